@@ -0,0 +1,94 @@
+package election
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/to404hanga/pkg404/logger"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+)
+
+// 选举失败后重新参选的间隔
+const retryInterval = time.Second
+
+var errSessionLost = errors.New("election session lost")
+
+// EtcdElector 基于 etcd concurrency.Election 的选举实现。
+// leader 身份绑定在 ttl 秒的租约上，由 session 自动续约，实例宕机后租约过期即由其他实例接替
+type EtcdElector struct {
+	client *clientv3.Client
+	prefix string
+	ttl    int
+	id     string
+	l      logger.Logger
+	leader atomic.Bool
+}
+
+var _ Elector = (*EtcdElector)(nil)
+
+func NewEtcdElector(client *clientv3.Client, prefix string, ttl int, id string, l logger.Logger) Elector {
+	return &EtcdElector{
+		client: client,
+		prefix: prefix,
+		ttl:    ttl,
+		id:     id,
+		l:      l,
+	}
+}
+
+func (e *EtcdElector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+func (e *EtcdElector) Run(ctx context.Context) error {
+	for {
+		err := e.campaign(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		e.l.Warn("leader election interrupted, retrying",
+			logger.Error(err),
+			logger.String("id", e.id),
+		)
+
+		select {
+		case <-time.After(retryInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// campaign 建立新的 session 参选，当选后保持 leader 身份直到 session 失效或 ctx 取消
+func (e *EtcdElector) campaign(ctx context.Context) error {
+	// session 使用客户端自身的 ctx，保证 ctx 取消后 Close 仍能撤销租约
+	session, err := concurrency.NewSession(e.client, concurrency.WithTTL(e.ttl))
+	if err != nil {
+		return err
+	}
+	// 撤销租约会删除参选 key，其他实例无需等待租约过期即可接替
+	defer session.Close()
+
+	election := concurrency.NewElection(session, e.prefix)
+	if err = election.Campaign(ctx, e.id); err != nil {
+		return err
+	}
+
+	e.leader.Store(true)
+	defer e.leader.Store(false)
+	e.l.Info("became leader",
+		logger.String("id", e.id),
+		logger.String("prefix", e.prefix),
+	)
+
+	select {
+	case <-session.Done():
+		// 续约失败（如与 etcd 断连超过 ttl），租约可能已被其他实例接替
+		return errSessionLost
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,91 @@
+package election
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// MemoryElectionGroup 进程内的选举组，组内同一时刻最多一个 leader，按参选顺序接替。
+// 用于测试以及无需协调的单实例部署
+type MemoryElectionGroup struct {
+	mu         sync.Mutex
+	leader     *MemoryElector
+	candidates []*MemoryElector
+}
+
+func NewMemoryElectionGroup() *MemoryElectionGroup {
+	return &MemoryElectionGroup{}
+}
+
+// NewElector 创建加入该选举组的参选者
+func (g *MemoryElectionGroup) NewElector() *MemoryElector {
+	return &MemoryElector{group: g}
+}
+
+// Expire 模拟当前 leader 租约过期：其失去 leader 身份并排到候选队列末尾，由下一个候选者接替
+func (g *MemoryElectionGroup) Expire() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.leader == nil {
+		return
+	}
+	old := g.leader
+	old.leader.Store(false)
+	g.leader = nil
+	g.candidates = append(g.candidates, old)
+	g.promoteLocked()
+}
+
+func (g *MemoryElectionGroup) join(e *MemoryElector) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.candidates = append(g.candidates, e)
+	g.promoteLocked()
+}
+
+func (g *MemoryElectionGroup) leave(e *MemoryElector) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.leader == e {
+		e.leader.Store(false)
+		g.leader = nil
+	}
+	for i, c := range g.candidates {
+		if c == e {
+			g.candidates = append(g.candidates[:i], g.candidates[i+1:]...)
+			break
+		}
+	}
+	g.promoteLocked()
+}
+
+func (g *MemoryElectionGroup) promoteLocked() {
+	if g.leader != nil || len(g.candidates) == 0 {
+		return
+	}
+	g.leader = g.candidates[0]
+	g.candidates = g.candidates[1:]
+	g.leader.leader.Store(true)
+}
+
+type MemoryElector struct {
+	group  *MemoryElectionGroup
+	leader atomic.Bool
+}
+
+var _ Elector = (*MemoryElector)(nil)
+
+func (e *MemoryElector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+func (e *MemoryElector) Run(ctx context.Context) error {
+	e.group.join(e)
+	<-ctx.Done()
+	e.group.leave(e)
+	return ctx.Err()
+}
@@ -0,0 +1,91 @@
+package election
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryElectionGroup(t *testing.T) {
+	testCases := []struct {
+		name string
+		// action 在三个参选者均加入后执行，返回期望成为 leader 的下标，-1 表示没有 leader
+		action func(g *MemoryElectionGroup, cancels []context.CancelFunc) int
+	}{
+		{
+			name: "最先参选者当选",
+			action: func(g *MemoryElectionGroup, cancels []context.CancelFunc) int {
+				return 0
+			},
+		},
+		{
+			name: "leader 退出后由下一个候选者接替",
+			action: func(g *MemoryElectionGroup, cancels []context.CancelFunc) int {
+				cancels[0]()
+				return 1
+			},
+		},
+		{
+			name: "候选者退出不影响 leader",
+			action: func(g *MemoryElectionGroup, cancels []context.CancelFunc) int {
+				cancels[1]()
+				return 0
+			},
+		},
+		{
+			name: "租约过期后原 leader 重新排队",
+			action: func(g *MemoryElectionGroup, cancels []context.CancelFunc) int {
+				g.Expire()
+				g.Expire()
+				g.Expire()
+				return 0
+			},
+		},
+		{
+			name: "全部退出后没有 leader",
+			action: func(g *MemoryElectionGroup, cancels []context.CancelFunc) int {
+				for _, cancel := range cancels {
+					cancel()
+				}
+				return -1
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewMemoryElectionGroup()
+			electors := make([]*MemoryElector, 3)
+			cancels := make([]context.CancelFunc, 3)
+			for i := range electors {
+				electors[i] = g.NewElector()
+				ctx, cancel := context.WithCancel(context.Background())
+				cancels[i] = cancel
+				go electors[i].Run(ctx)
+				// 保证参选顺序
+				assert.Eventually(t, func() bool {
+					g.mu.Lock()
+					defer g.mu.Unlock()
+					return g.leader == electors[i] || len(g.candidates) == i
+				}, time.Second, time.Millisecond)
+			}
+			defer func() {
+				for _, cancel := range cancels {
+					cancel()
+				}
+			}()
+
+			want := tc.action(g, cancels)
+			assert.Eventually(t, func() bool {
+				for i, e := range electors {
+					if e.IsLeader() != (i == want) {
+						return false
+					}
+				}
+				return true
+			}, time.Second, time.Millisecond)
+		})
+	}
+}
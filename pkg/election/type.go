@@ -0,0 +1,12 @@
+// Package election 多实例间的 leader 选举，用于保证定时任务等操作同一时刻只在一个实例上执行。
+package election
+
+import "context"
+
+type Elector interface {
+	// IsLeader 当前实例是否为 leader
+	IsLeader() bool
+	// Run 参与选举并维持 leader 身份，失去 leader 身份后自动重新参选，
+	// 直到 ctx 取消时让出 leader 身份并返回
+	Run(ctx context.Context) error
+}
@@ -49,6 +49,42 @@ func CheckShortUrl(shortUrl string, weights []int) bool {
 	return Base62NumberTable[string(checksumChar)] == calculateChecksum(data, weights)
 }
 
+// IsGeneratedFormat 判断是否为生成器产出的格式（7 位 Base62 字符）
+// 该格式的短链接可以使用校验位快速拒绝，自定义别名不允许占用该格式
+func IsGeneratedFormat(shortUrl string) bool {
+	if len(shortUrl) != 7 {
+		return false
+	}
+	for i := 0; i < len(shortUrl); i++ {
+		if _, ok := Base62NumberTable[string(shortUrl[i])]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckCustomAlias 验证自定义别名的有效性
+// 别名由 Base62 字符、'-' 和 '_' 组成，首字符必须为 Base62 字符（用于分表），
+// 且不能与生成的短链接格式重叠
+func CheckCustomAlias(alias string) bool {
+	if len(alias) < AliasMinLength || len(alias) > AliasMaxLength {
+		return false
+	}
+	if _, ok := Base62NumberTable[string(alias[0])]; !ok {
+		return false
+	}
+	for i := 1; i < len(alias); i++ {
+		c := alias[i]
+		if c == '-' || c == '_' {
+			continue
+		}
+		if _, ok := Base62NumberTable[string(c)]; !ok {
+			return false
+		}
+	}
+	return !IsGeneratedFormat(alias)
+}
+
 // 快速base62编码
 func encodeBase62(data []byte) []byte {
 	var result []byte
@@ -42,3 +42,60 @@ func TestShortUrlService_generateShortUrl(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckCustomAlias(t *testing.T) {
+	testCases := []struct {
+		name  string
+		alias string
+		want  bool
+	}{
+		{
+			name:  "正常别名",
+			alias: "spring-sale",
+			want:  true,
+		},
+		{
+			name:  "包含下划线",
+			alias: "summer_2025",
+			want:  true,
+		},
+		{
+			name:  "长度过短",
+			alias: "ab",
+			want:  false,
+		},
+		{
+			name:  "长度过长",
+			alias: "abcdefghijklmnopqrstuvwxyz0123456",
+			want:  false,
+		},
+		{
+			name:  "首字符非法",
+			alias: "-spring",
+			want:  false,
+		},
+		{
+			name:  "包含非法字符",
+			alias: "spring/sale",
+			want:  false,
+		},
+		{
+			name:  "与生成格式重叠",
+			alias: "abc1234",
+			want:  false,
+		},
+		{
+			name:  "7 位但包含连字符",
+			alias: "abc-123",
+			want:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := CheckCustomAlias(tc.alias); got != tc.want {
+				t.Errorf("CheckCustomAlias(%q) = %v, want %v", tc.alias, got, tc.want)
+			}
+		})
+	}
+}
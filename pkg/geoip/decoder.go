@@ -0,0 +1,206 @@
+package geoip
+
+import (
+	"fmt"
+	"math"
+)
+
+// 数据段的字段类型，编号与 MaxMind DB 规范一致
+const (
+	typeExtended  = 0
+	typePointer   = 1
+	typeString    = 2
+	typeDouble    = 3
+	typeBytes     = 4
+	typeUint16    = 5
+	typeUint32    = 6
+	typeMap       = 7
+	typeInt32     = 8
+	typeUint64    = 9
+	typeUint128   = 10
+	typeArray     = 11
+	typeContainer = 12
+	typeEnd       = 13
+	typeBool      = 14
+	typeFloat     = 15
+)
+
+// maxDecodeDepth 嵌套与指针的最大深度，防止损坏的文件导致无限递归
+const maxDecodeDepth = 32
+
+// decoder 解析数据段或元数据段，指针偏移相对于 buf 起始位置
+type decoder struct {
+	buf []byte
+}
+
+// decode 解析 offset 处的字段，返回值与下一个字段的偏移。
+// 整数统一返回 uint64（int32 除外），uint128 返回原始字节
+func (d decoder) decode(offset uint, depth int) (any, uint, error) {
+	if depth > maxDecodeDepth {
+		return nil, 0, fmt.Errorf("%w: data nested too deep", ErrInvalidDatabase)
+	}
+	if offset >= uint(len(d.buf)) {
+		return nil, 0, fmt.Errorf("%w: data offset out of range", ErrInvalidDatabase)
+	}
+	ctrl := d.buf[offset]
+	offset++
+	typ := uint(ctrl >> 5)
+	if typ == typePointer {
+		ptr, next, err := d.pointer(ctrl, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		val, _, err := d.decode(ptr, depth+1)
+		return val, next, err
+	}
+	if typ == typeExtended {
+		if offset >= uint(len(d.buf)) {
+			return nil, 0, fmt.Errorf("%w: data offset out of range", ErrInvalidDatabase)
+		}
+		typ = 7 + uint(d.buf[offset])
+		offset++
+	}
+	size, offset, err := d.size(ctrl, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	return d.value(typ, size, offset, depth)
+}
+
+// pointer 解析指针的目标偏移，指针的长度由控制字节的第 4、5 位决定
+func (d decoder) pointer(ctrl byte, offset uint) (uint, uint, error) {
+	n := uint(ctrl>>3)&0x3 + 1
+	b, err := d.bytes(offset, n)
+	if err != nil {
+		return 0, 0, err
+	}
+	prefix := uint(ctrl & 0x7)
+	var ptr uint
+	switch n {
+	case 1:
+		ptr = prefix<<8 | uint(b[0])
+	case 2:
+		ptr = (prefix<<16 | uint(b[0])<<8 | uint(b[1])) + 2048
+	case 3:
+		ptr = (prefix<<24 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])) + 526336
+	default:
+		ptr = uint(b[0])<<24 | uint(b[1])<<16 | uint(b[2])<<8 | uint(b[3])
+	}
+	return ptr, offset + n, nil
+}
+
+// size 解析字段长度，低 5 位为 29~31 时长度由后续 1~3 个字节给出
+func (d decoder) size(ctrl byte, offset uint) (uint, uint, error) {
+	size := uint(ctrl & 0x1f)
+	if size < 29 {
+		return size, offset, nil
+	}
+	n := size - 28
+	b, err := d.bytes(offset, n)
+	if err != nil {
+		return 0, 0, err
+	}
+	switch n {
+	case 1:
+		size = 29 + uint(b[0])
+	case 2:
+		size = 285 + (uint(b[0])<<8 | uint(b[1]))
+	default:
+		size = 65821 + (uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2]))
+	}
+	return size, offset + n, nil
+}
+
+func (d decoder) value(typ, size, offset uint, depth int) (any, uint, error) {
+	switch typ {
+	case typeMap:
+		m := make(map[string]any, min(size, 64))
+		for i := uint(0); i < size; i++ {
+			key, next, err := d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			k, ok := key.(string)
+			if !ok {
+				return nil, 0, fmt.Errorf("%w: map key is not a string", ErrInvalidDatabase)
+			}
+			val, next, err := d.decode(next, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			m[k] = val
+			offset = next
+		}
+		return m, offset, nil
+	case typeArray:
+		arr := make([]any, 0, min(size, 64))
+		for i := uint(0); i < size; i++ {
+			val, next, err := d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			arr = append(arr, val)
+			offset = next
+		}
+		return arr, offset, nil
+	case typeBool:
+		// 布尔值直接保存在长度字段中，没有数据部分
+		return size != 0, offset, nil
+	case typeEnd:
+		return nil, offset, nil
+	case typeContainer:
+		return nil, 0, fmt.Errorf("%w: unexpected data cache container", ErrInvalidDatabase)
+	}
+
+	b, err := d.bytes(offset, size)
+	if err != nil {
+		return nil, 0, err
+	}
+	next := offset + size
+	switch typ {
+	case typeString:
+		return string(b), next, nil
+	case typeBytes:
+		return append([]byte(nil), b...), next, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, fmt.Errorf("%w: invalid double size %d", ErrInvalidDatabase, size)
+		}
+		return math.Float64frombits(uint64(beUint(b))), next, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, fmt.Errorf("%w: invalid float size %d", ErrInvalidDatabase, size)
+		}
+		return math.Float32frombits(uint32(beUint(b))), next, nil
+	case typeUint16, typeUint32, typeUint64:
+		if size > 8 {
+			return nil, 0, fmt.Errorf("%w: invalid integer size %d", ErrInvalidDatabase, size)
+		}
+		return uint64(beUint(b)), next, nil
+	case typeInt32:
+		if size > 4 {
+			return nil, 0, fmt.Errorf("%w: invalid integer size %d", ErrInvalidDatabase, size)
+		}
+		return int32(uint32(beUint(b))), next, nil
+	case typeUint128:
+		return append([]byte(nil), b...), next, nil
+	default:
+		return nil, 0, fmt.Errorf("%w: unknown data type %d", ErrInvalidDatabase, typ)
+	}
+}
+
+func (d decoder) bytes(offset, n uint) ([]byte, error) {
+	if offset+n > uint(len(d.buf)) {
+		return nil, fmt.Errorf("%w: data offset out of range", ErrInvalidDatabase)
+	}
+	return d.buf[offset : offset+n], nil
+}
+
+// beUint 按大端序解析不超过 8 字节的无符号整数
+func beUint(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
@@ -0,0 +1,197 @@
+// Package geoip 提供 MaxMind DB（mmdb）格式离线库的只读解析，
+// 仅实现按 IP 查询记录所需的部分，不依赖第三方库
+package geoip
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+)
+
+var ErrInvalidDatabase = errors.New("geoip: invalid database")
+
+// metadataMarker 元数据段起始标记，位于文件末尾 128KB 以内
+var metadataMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// 搜索树与数据段之间固定有 16 字节的 0 作为分隔
+const dataSectionSeparator = 16
+
+// Metadata mmdb 文件元数据中查询需要的字段
+type Metadata struct {
+	NodeCount    uint
+	RecordSize   uint // 单条记录的位数，支持 24/28/32
+	IPVersion    uint // 4 表示只包含 IPv4，6 表示 IPv6 树（IPv4 位于 ::/96 下）
+	DatabaseType string
+	BuildEpoch   uint64
+}
+
+// Reader 将整个 mmdb 文件加载到内存后查询，创建后只读，可并发使用
+type Reader struct {
+	meta Metadata
+	tree []byte
+	data decoder
+	// ipv4Start IPv6 树中 ::/96 对应的节点，查询 IPv4 地址时从这里开始
+	ipv4Start uint
+}
+
+// Open 读取并解析 path 指定的 mmdb 文件
+func Open(path string) (*Reader, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return FromBytes(buf)
+}
+
+// FromBytes 解析内存中的 mmdb 数据，返回的 Reader 会引用 buf，调用方不能再修改
+func FromBytes(buf []byte) (*Reader, error) {
+	idx := bytes.LastIndex(buf, metadataMarker)
+	if idx < 0 {
+		return nil, fmt.Errorf("%w: metadata not found", ErrInvalidDatabase)
+	}
+	meta, err := decodeMetadata(decoder{buf: buf[idx+len(metadataMarker):]})
+	if err != nil {
+		return nil, err
+	}
+
+	treeSize := meta.NodeCount * meta.RecordSize / 4
+	if treeSize+dataSectionSeparator > uint(idx) {
+		return nil, fmt.Errorf("%w: search tree out of range", ErrInvalidDatabase)
+	}
+	r := &Reader{
+		meta: meta,
+		tree: buf[:treeSize],
+		data: decoder{buf: buf[treeSize+dataSectionSeparator : idx]},
+	}
+	if meta.IPVersion == 6 {
+		node := uint(0)
+		for i := 0; i < 96 && node < meta.NodeCount; i++ {
+			node = r.readNode(node, 0)
+		}
+		r.ipv4Start = node
+	}
+	return r, nil
+}
+
+func decodeMetadata(d decoder) (Metadata, error) {
+	val, _, err := d.decode(0, 0)
+	if err != nil {
+		return Metadata{}, err
+	}
+	m, ok := val.(map[string]any)
+	if !ok {
+		return Metadata{}, fmt.Errorf("%w: metadata is not a map", ErrInvalidDatabase)
+	}
+	meta := Metadata{
+		NodeCount:  uint(toUint64(m["node_count"])),
+		RecordSize: uint(toUint64(m["record_size"])),
+		IPVersion:  uint(toUint64(m["ip_version"])),
+		BuildEpoch: toUint64(m["build_epoch"]),
+	}
+	meta.DatabaseType, _ = m["database_type"].(string)
+	switch meta.RecordSize {
+	case 24, 28, 32:
+	default:
+		return Metadata{}, fmt.Errorf("%w: unsupported record size %d", ErrInvalidDatabase, meta.RecordSize)
+	}
+	if meta.IPVersion != 4 && meta.IPVersion != 6 {
+		return Metadata{}, fmt.Errorf("%w: unsupported ip version %d", ErrInvalidDatabase, meta.IPVersion)
+	}
+	return meta, nil
+}
+
+func (r *Reader) Metadata() Metadata {
+	return r.meta
+}
+
+// Lookup 查询 ip 所在网段的记录，ip 不在库中时返回 nil
+func (r *Reader) Lookup(ip net.IP) (map[string]any, error) {
+	node := uint(0)
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+		if r.meta.IPVersion == 6 {
+			node = r.ipv4Start
+		}
+	} else if r.meta.IPVersion == 4 || ip.To16() == nil {
+		// 仅包含 IPv4 的库无法查询 IPv6 地址
+		return nil, nil
+	}
+
+	for i := 0; i < len(ip)*8 && node < r.meta.NodeCount; i++ {
+		bit := (ip[i>>3] >> (7 - uint(i&7))) & 1
+		node = r.readNode(node, bit)
+	}
+	if node <= r.meta.NodeCount {
+		// 等于节点数表示没有数据，小于节点数说明树的深度超过了地址长度
+		return nil, nil
+	}
+	offset := node - r.meta.NodeCount - dataSectionSeparator
+	val, _, err := r.data.decode(offset, 0)
+	if err != nil {
+		return nil, err
+	}
+	record, ok := val.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: record is not a map", ErrInvalidDatabase)
+	}
+	return record, nil
+}
+
+// Country 返回 ip 所在国家的 ISO 3166-1 二位代码，缺少国家时使用注册国家，查询不到时返回空字符串
+func (r *Reader) Country(ip net.IP) string {
+	record, err := r.Lookup(ip)
+	if err != nil || record == nil {
+		return ""
+	}
+	for _, key := range []string{"country", "registered_country"} {
+		if c, ok := record[key].(map[string]any); ok {
+			if code, ok := c["iso_code"].(string); ok && code != "" {
+				return code
+			}
+		}
+	}
+	return ""
+}
+
+// readNode 读取节点的左（bit 为 0）或右记录，越界时按没有数据处理
+func (r *Reader) readNode(node uint, bit byte) uint {
+	b := r.tree
+	switch r.meta.RecordSize {
+	case 24:
+		off := node*6 + uint(bit)*3
+		if off+3 > uint(len(b)) {
+			return r.meta.NodeCount
+		}
+		return uint(b[off])<<16 | uint(b[off+1])<<8 | uint(b[off+2])
+	case 28:
+		off := node * 7
+		if off+7 > uint(len(b)) {
+			return r.meta.NodeCount
+		}
+		// 中间字节的高 4 位属于左记录，低 4 位属于右记录
+		if bit == 0 {
+			return uint(b[off+3]&0xF0)<<20 | uint(b[off])<<16 | uint(b[off+1])<<8 | uint(b[off+2])
+		}
+		return uint(b[off+3]&0x0F)<<24 | uint(b[off+4])<<16 | uint(b[off+5])<<8 | uint(b[off+6])
+	default:
+		off := node*8 + uint(bit)*4
+		if off+4 > uint(len(b)) {
+			return r.meta.NodeCount
+		}
+		return uint(b[off])<<24 | uint(b[off+1])<<16 | uint(b[off+2])<<8 | uint(b[off+3])
+	}
+}
+
+func toUint64(v any) uint64 {
+	switch n := v.(type) {
+	case uint64:
+		return n
+	case int32:
+		if n > 0 {
+			return uint64(n)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,236 @@
+package geoip
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 以下为测试用的最小 mmdb 编码实现，只覆盖用到的类型
+
+func encCtrl(typ, size int) []byte {
+	var b []byte
+	if typ > 7 {
+		b = []byte{0, byte(typ - 7)}
+	} else {
+		b = []byte{byte(typ << 5)}
+	}
+	switch {
+	case size < 29:
+		b[0] |= byte(size)
+	case size < 285:
+		b[0] |= 29
+		b = append(b, byte(size-29))
+	default:
+		b[0] |= 30
+		size -= 285
+		b = append(b, byte(size>>8), byte(size))
+	}
+	return b
+}
+
+func encString(s string) []byte {
+	return append(encCtrl(typeString, len(s)), s...)
+}
+
+func encUint(typ int, v uint64) []byte {
+	var payload []byte
+	for ; v > 0; v >>= 8 {
+		payload = append([]byte{byte(v)}, payload...)
+	}
+	return append(encCtrl(typ, len(payload)), payload...)
+}
+
+// encPointer 偏移量小于 2048 时使用 1 字节指针，否则使用带附加值的 2 字节指针
+func encPointer(offset int) []byte {
+	if offset < 2048 {
+		return []byte{byte(typePointer<<5 | offset>>8), byte(offset)}
+	}
+	offset -= 2048
+	return []byte{byte(typePointer<<5 | 1<<3 | offset>>16), byte(offset >> 8), byte(offset)}
+}
+
+// encMap 按给定顺序编码 key/value，value 为已编码的字节
+func encMap(kvs ...any) []byte {
+	b := encCtrl(typeMap, len(kvs)/2)
+	for i := 0; i < len(kvs); i += 2 {
+		b = append(b, encString(kvs[i].(string))...)
+		b = append(b, kvs[i+1].([]byte)...)
+	}
+	return b
+}
+
+type network struct {
+	ip     []byte
+	prefix int
+	data   int // 记录在数据段中的偏移
+}
+
+type trieNode struct {
+	children [2]*trieNode
+	data     int // 叶子节点的数据偏移，-1 表示不是叶子
+}
+
+// buildDB 生成包含 networks 的 mmdb 文件，data 为数据段内容
+func buildDB(t *testing.T, ipVersion, recordSize int, networks []network, data []byte) []byte {
+	root := &trieNode{data: -1}
+	for _, n := range networks {
+		cur := root
+		for i := 0; i < n.prefix; i++ {
+			bit := (n.ip[i/8] >> (7 - i%8)) & 1
+			if cur.children[bit] == nil {
+				cur.children[bit] = &trieNode{data: -1}
+			}
+			cur = cur.children[bit]
+		}
+		cur.data = n.data
+	}
+
+	// 按广度优先为非叶子节点编号
+	var nodes []*trieNode
+	index := map[*trieNode]int{}
+	for queue := []*trieNode{root}; len(queue) > 0; queue = queue[1:] {
+		cur := queue[0]
+		index[cur] = len(nodes)
+		nodes = append(nodes, cur)
+		for _, c := range cur.children {
+			if c != nil && c.data < 0 {
+				queue = append(queue, c)
+			}
+		}
+	}
+	nodeCount := len(nodes)
+	record := func(c *trieNode) uint32 {
+		switch {
+		case c == nil:
+			return uint32(nodeCount)
+		case c.data >= 0:
+			return uint32(nodeCount + dataSectionSeparator + c.data)
+		default:
+			return uint32(index[c])
+		}
+	}
+
+	var tree []byte
+	for _, n := range nodes {
+		l, r := record(n.children[0]), record(n.children[1])
+		switch recordSize {
+		case 24:
+			tree = append(tree, byte(l>>16), byte(l>>8), byte(l), byte(r>>16), byte(r>>8), byte(r))
+		case 28:
+			tree = append(tree, byte(l>>16), byte(l>>8), byte(l), byte(l>>24<<4)|byte(r>>24&0x0F), byte(r>>16), byte(r>>8), byte(r))
+		case 32:
+			tree = append(tree, byte(l>>24), byte(l>>16), byte(l>>8), byte(l), byte(r>>24), byte(r>>16), byte(r>>8), byte(r))
+		default:
+			t.Fatalf("unsupported record size %d", recordSize)
+		}
+	}
+
+	buf := append(tree, make([]byte, dataSectionSeparator)...)
+	buf = append(buf, data...)
+	buf = append(buf, metadataMarker...)
+	buf = append(buf, encMap(
+		"node_count", encUint(typeUint32, uint64(nodeCount)),
+		"record_size", encUint(typeUint16, uint64(recordSize)),
+		"ip_version", encUint(typeUint16, uint64(ipVersion)),
+		"database_type", encString("Test-Country"),
+		"build_epoch", encUint(typeUint64, 1700000000),
+	)...)
+	return buf
+}
+
+func TestReader_Country(t *testing.T) {
+	// 数据段：一个被指针引用的字符串、两条记录
+	var data []byte
+	us := len(data)
+	data = append(data, encString("US")...)
+	cn := len(data)
+	data = append(data, encMap("country", encMap("iso_code", encString("CN")))...)
+	// 只有注册国家的记录，iso_code 通过指针引用
+	registered := len(data)
+	data = append(data, encMap("registered_country", encMap("iso_code", encPointer(us)))...)
+
+	v4 := func(ip string) []byte { return net.ParseIP(ip).To4() }
+	mapped := func(ip string) []byte { return append(make([]byte, 12), v4(ip)...) }
+
+	testCases := []struct {
+		name      string
+		ipVersion int
+		networks  []network
+	}{
+		{
+			name:      "IPv4 树",
+			ipVersion: 4,
+			networks: []network{
+				{ip: v4("1.0.0.0"), prefix: 8, data: cn},
+				{ip: v4("8.8.8.0"), prefix: 24, data: registered},
+			},
+		},
+		{
+			name:      "IPv6 树中的 IPv4 地址",
+			ipVersion: 6,
+			networks: []network{
+				{ip: mapped("1.0.0.0"), prefix: 96 + 8, data: cn},
+				{ip: mapped("8.8.8.0"), prefix: 96 + 24, data: registered},
+				{ip: net.ParseIP("2001:db8::"), prefix: 32, data: cn},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		for _, recordSize := range []int{24, 28, 32} {
+			t.Run(tc.name, func(t *testing.T) {
+				r, err := FromBytes(buildDB(t, tc.ipVersion, recordSize, tc.networks, data))
+				require.NoError(t, err)
+				assert.Equal(t, uint(recordSize), r.Metadata().RecordSize)
+				assert.Equal(t, "Test-Country", r.Metadata().DatabaseType)
+				assert.Equal(t, uint64(1700000000), r.Metadata().BuildEpoch)
+
+				assert.Equal(t, "CN", r.Country(net.ParseIP("1.2.3.4")))
+				assert.Equal(t, "US", r.Country(net.ParseIP("8.8.8.8")))
+				assert.Equal(t, "", r.Country(net.ParseIP("8.8.9.8")))
+				assert.Equal(t, "", r.Country(net.ParseIP("127.0.0.1")))
+				if tc.ipVersion == 6 {
+					assert.Equal(t, "CN", r.Country(net.ParseIP("2001:db8::1")))
+				} else {
+					assert.Equal(t, "", r.Country(net.ParseIP("2001:db8::1")))
+				}
+			})
+		}
+	}
+}
+
+func TestFromBytes_Invalid(t *testing.T) {
+	testCases := []struct {
+		name string
+		buf  []byte
+	}{
+		{name: "缺少元数据", buf: []byte("not a database")},
+		{name: "元数据被截断", buf: append(append([]byte{}, metadataMarker...), 0xe3)},
+		{
+			name: "不支持的记录长度",
+			buf: append(append([]byte{}, metadataMarker...), encMap(
+				"node_count", encUint(typeUint32, 1),
+				"record_size", encUint(typeUint16, 20),
+				"ip_version", encUint(typeUint16, 4),
+			)...),
+		},
+		{
+			name: "搜索树超出文件长度",
+			buf: append(append([]byte{}, metadataMarker...), encMap(
+				"node_count", encUint(typeUint32, 100),
+				"record_size", encUint(typeUint16, 24),
+				"ip_version", encUint(typeUint16, 4),
+			)...),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := FromBytes(tc.buf)
+			assert.ErrorIs(t, err, ErrInvalidDatabase)
+		})
+	}
+}
@@ -14,6 +14,9 @@ import (
 //go:embed scripts/*.lua
 var luaFS embed.FS
 
+// ErrInvalidTokenRequest 请求的令牌数不在 (0, 桶容量] 范围内，等待多久都无法满足
+var ErrInvalidTokenRequest = errors.New("token request must be positive and not exceed bucket capacity")
+
 // TokenBucketLimiter 基于Redis的令牌桶限流器
 type TokenBucketLimiter struct {
 	client   *redis.Client // Redis客户端实例
@@ -95,40 +98,64 @@ func NewTokenBucketLimiter(client *redis.Client, rate time.Duration, capacity in
 	}, nil
 }
 
+// Result 一次获取令牌的结果
+type Result struct {
+	Allowed   bool  // 是否获取到足够的令牌
+	Limit     int64 // 桶容量
+	Remaining int64 // 本次请求之后桶内剩余的令牌数
+	// ResetAfter 距下一个令牌生成的时间，桶已满时为 0
+	ResetAfter time.Duration
+	// RetryAfter 被拒绝时距桶内令牌足够本次请求的时间，允许时为 0
+	RetryAfter time.Duration
+}
+
 // Allow 尝试获取指定数量的令牌
 // ctx: 上下文，用于控制超时和取消
 // key: Redis中的键名（用于区分不同的限流器）
 // n: 请求的令牌数量
 // 返回值:
 //
-//	bool - true表示允许请求（获取到足够令牌）
-//	error - 执行过程中的错误
-func (l *TokenBucketLimiter) Allow(ctx context.Context, key string, n int64) (bool, error) {
+//	Result - 是否允许请求以及桶内剩余令牌数、令牌恢复时间
+//	error - 执行过程中的错误，n 不合法时返回 ErrInvalidTokenRequest
+func (l *TokenBucketLimiter) Allow(ctx context.Context, key string, n int64) (Result, error) {
 	// 参数校验：请求令牌数必须在合理范围内
 	if n <= 0 || n > l.capacity {
-		// 无效请求（0或负数，或超过桶容量）
-		return false, nil
+		// 无效请求（0或负数，或超过桶容量）属于配置错误，不能作为普通的限流拒绝返回
+		return Result{}, ErrInvalidTokenRequest
 	}
 
 	// 获取当前时间（纳秒级）
 	currentTime := time.Now().UnixNano()
 
 	// 执行Lua脚本（带重试机制）
-	result, err := l.script.Run(ctx, l.client, []string{key}, // KEYS参数
+	vals, err := l.script.Run(ctx, l.client, []string{key}, // KEYS参数
 		l.rate.Nanoseconds(),    // 令牌生成速率 (纳秒/令牌)
 		l.capacity,              // 桶容量
 		n,                       // 请求令牌数
 		l.expire.Milliseconds(), // Key过期时间(毫秒),
 		currentTime,             // 当前时间(纳秒)
-	).Int64() // 将结果转换为int64类型
+	).Int64Slice() // 将结果转换为 []int64
 
 	// 处理脚本执行错误
 	if err != nil {
-		return false, fmt.Errorf("script execution failed: %w", err)
+		return Result{}, fmt.Errorf("script execution failed: %w", err)
+	}
+	if len(vals) != 3 {
+		return Result{}, fmt.Errorf("unexpected script result: %v", vals)
 	}
 
-	// Lua脚本返回1表示允许，0表示拒绝
-	return result == 1, nil
+	// Lua脚本返回 {是否允许, 剩余令牌数, 距下一个令牌的纳秒数}
+	res := Result{
+		Allowed:    vals[0] == 1,
+		Limit:      l.capacity,
+		Remaining:  vals[1],
+		ResetAfter: time.Duration(vals[2]),
+	}
+	if !res.Allowed {
+		// 还差 n - Remaining 个令牌，第一个令牌在 ResetAfter 后生成，之后每个令牌间隔 rate
+		res.RetryAfter = res.ResetAfter + time.Duration(n-res.Remaining-1)*l.rate
+	}
+	return res, nil
 }
 
 // SetExpiration 自定义Key过期时间
@@ -0,0 +1,29 @@
+package go_redis_tokenbucket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucketLimiter_AllowInvalidRequest(t *testing.T) {
+	// 参数校验先于脚本执行，不需要连接 redis
+	l := &TokenBucketLimiter{rate: time.Millisecond, capacity: 10}
+	testCases := []struct {
+		name string
+		n    int64
+	}{
+		{name: "请求 0 个令牌", n: 0},
+		{name: "请求负数个令牌", n: -1},
+		{name: "超过桶容量", n: 11},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := l.Allow(context.Background(), "k", tc.n)
+			assert.ErrorIs(t, err, ErrInvalidTokenRequest)
+			assert.False(t, res.Allowed)
+		})
+	}
+}
@@ -7,6 +7,9 @@
 --   [2] bucket_capacity: 桶容量（最大令牌数）
 --   [3] token_request: 请求的令牌数量
 --   [4] key_expiration: Key过期时间（毫秒）
+--   [5] current_time: 当前时间（纳秒）
+--
+-- 返回值: { 是否允许(1/0), 剩余令牌数, 距下一个令牌生成的时间（纳秒，桶已满时为 0） }
 
 local bucket_key = KEYS[1] -- 令牌桶标识
 
@@ -64,6 +67,8 @@ if tokens_available >= token_request then
     -- 计算新的刷新时间：
     -- 推进时间 = 原时间 + (请求令牌数 * 令牌生成时间)
     local new_refresh_time_ns = last_refresh_time_ns + (token_request * token_gen_rate_ns)
+    last_refresh_time_ns = new_refresh_time_ns
+    tokens_available = tokens_available - token_request
 
     -- 更新Redis中的时间戳
     redis.call('SET', bucket_key, new_refresh_time_ns, 'PX', key_expiration_ms)
@@ -76,6 +81,11 @@ end
 
 --------------------------------------------------------------------------------
 -- 返回结果
--- 1 = 允许请求, 0 = 拒绝请求
+-- 下一个令牌的生成时刻 = 刷新时间 + (剩余令牌数 + 1) * 令牌生成时间
 --------------------------------------------------------------------------------
-return request_allowed
\ No newline at end of file
+local next_token_ns = 0
+if tokens_available < bucket_capacity then
+    next_token_ns = math.max(last_refresh_time_ns + (tokens_available + 1) * token_gen_rate_ns - current_time_ns, 0)
+end
+
+return { request_allowed, tokens_available, next_token_ns }
\ No newline at end of file
@@ -0,0 +1,122 @@
+// Package lifecycle 管理进程的优雅关闭：收到退出信号后按注册顺序依次执行关闭钩子，
+// 所有钩子共享同一个截止时间。
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/to404hanga/pkg404/logger"
+)
+
+// 截止时间过后每个钩子仍可占用的宽限期，用于执行强制关闭等快速清理
+const forceGracePeriod = 200 * time.Millisecond
+
+// Hook 关闭钩子，ctx 携带整体关闭流程的截止时间
+type Hook func(ctx context.Context) error
+
+type namedHook struct {
+	name string
+	fn   Hook
+}
+
+type Manager struct {
+	timeout time.Duration
+	l       logger.Logger
+	mu      sync.Mutex
+	hooks   []namedHook
+	once    sync.Once
+	err     error
+}
+
+func NewManager(timeout time.Duration, l logger.Logger) *Manager {
+	return &Manager{
+		timeout: timeout,
+		l:       l,
+	}
+}
+
+// Register 注册关闭钩子，关闭时按注册顺序依次执行
+func (m *Manager) Register(name string, fn Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.hooks = append(m.hooks, namedHook{name: name, fn: fn})
+}
+
+// Wait 阻塞直到收到 SIGINT/SIGTERM 或 errCh 返回服务异常退出的错误，随后执行关闭流程
+func (m *Manager) Wait(errCh <-chan error) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var serveErr error
+	select {
+	case <-ctx.Done():
+		m.l.Info("shutdown signal received")
+	case serveErr = <-errCh:
+		m.l.Error("server exited unexpectedly, shutting down", logger.Error(serveErr))
+	}
+	return errors.Join(serveErr, m.Shutdown())
+}
+
+// Shutdown 在截止时间内依次执行所有关闭钩子，多次调用只会执行一次。
+// 某个钩子超时后不再等待它，后续钩子会拿到已到期的 ctx，应尽快完成强制清理
+func (m *Manager) Shutdown() error {
+	m.once.Do(func() {
+		m.mu.Lock()
+		hooks := m.hooks
+		m.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+		defer cancel()
+
+		var errs []error
+		for _, h := range hooks {
+			start := time.Now()
+			if err := runHook(ctx, h.fn); err != nil {
+				m.l.Error("shutdown hook failed",
+					logger.Error(err),
+					logger.String("hook", h.name),
+				)
+				errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+				continue
+			}
+			m.l.Info("shutdown hook finished",
+				logger.String("hook", h.name),
+				logger.String("cost", time.Since(start).String()),
+			)
+		}
+		m.err = errors.Join(errs...)
+	})
+	return m.err
+}
+
+func runHook(ctx context.Context, fn Hook) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+
+	// 截止时间已过，再给钩子一个短暂的宽限期完成强制清理
+	timer := time.NewTimer(forceGracePeriod)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		if err != nil {
+			return err
+		}
+	case <-timer.C:
+	}
+	return ctx.Err()
+}
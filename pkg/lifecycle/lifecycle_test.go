@@ -0,0 +1,91 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/to404hanga/pkg404/logger"
+)
+
+func TestManager_Shutdown(t *testing.T) {
+	errHook := errors.New("hook failed")
+
+	testCases := []struct {
+		name      string
+		timeout   time.Duration
+		hooks     []Hook
+		wantOrder []int
+		wantErr   error
+	}{
+		{
+			name:    "按注册顺序执行",
+			timeout: time.Second,
+			hooks: []Hook{
+				func(ctx context.Context) error { return nil },
+				func(ctx context.Context) error { return nil },
+				func(ctx context.Context) error { return nil },
+			},
+			wantOrder: []int{0, 1, 2},
+		},
+		{
+			name:    "钩子失败不影响后续钩子",
+			timeout: time.Second,
+			hooks: []Hook{
+				func(ctx context.Context) error { return errHook },
+				func(ctx context.Context) error { return nil },
+			},
+			wantOrder: []int{0, 1},
+			wantErr:   errHook,
+		},
+		{
+			name:    "钩子超时后继续执行后续钩子",
+			timeout: 50 * time.Millisecond,
+			hooks: []Hook{
+				func(ctx context.Context) error {
+					time.Sleep(time.Second)
+					return nil
+				},
+				func(ctx context.Context) error { return ctx.Err() },
+			},
+			wantOrder: []int{1},
+			wantErr:   context.DeadlineExceeded,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewManager(tc.timeout, logger.NewNopLogger())
+			var (
+				mu    sync.Mutex
+				order []int
+			)
+			for i, h := range tc.hooks {
+				m.Register("hook", func(ctx context.Context) error {
+					err := h(ctx)
+					mu.Lock()
+					order = append(order, i)
+					mu.Unlock()
+					return err
+				})
+			}
+
+			err := m.Shutdown()
+			mu.Lock()
+			got := append([]int(nil), order...)
+			mu.Unlock()
+
+			assert.Equal(t, tc.wantOrder, got)
+			if tc.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.wantErr)
+			}
+			// 重复调用不会再次执行钩子
+			assert.Equal(t, err, m.Shutdown())
+		})
+	}
+}
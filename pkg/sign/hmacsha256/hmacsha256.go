@@ -0,0 +1,74 @@
+package hmacsha256
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"short_url/pkg/sign"
+	"sort"
+	"strings"
+)
+
+// HmacSha256SignHandler 将 data 按 key 升序拼接为 "key=value" 行，以换行分隔，
+// 再使用 apiKey 作为密钥计算 HMAC-SHA256，输出小写十六进制。
+// 空值同样参与签名，value 只支持字符串、整数与布尔值
+type HmacSha256SignHandler struct {
+}
+
+var _ sign.SignHandler = (*HmacSha256SignHandler)(nil)
+
+var ErrEmptyApiKey = errors.New("apiKey cannot be empty")
+
+func NewHmacSha256SignHandler() sign.SignHandler {
+	return &HmacSha256SignHandler{}
+}
+
+func (h *HmacSha256SignHandler) GenerateSign(data map[string]any, apiKey string) (string, error) {
+	if apiKey == "" {
+		return "", ErrEmptyApiKey
+	}
+	payload, err := CanonicalString(data)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, []byte(apiKey))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// CanonicalString 返回参与签名的原文，便于调用方排查签名不一致的问题
+func CanonicalString(data map[string]any) (string, error) {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for i, k := range keys {
+		var val string
+		switch v := data[k].(type) {
+		case nil:
+		case string:
+			val = v
+		case int, int32, int64, uint, uint32, uint64, bool:
+			val = fmt.Sprint(v)
+		default:
+			return "", fmt.Errorf("unsupported value type %T for key %q", v, k)
+		}
+		// 换行用作分隔符，不允许出现在 key 与 value 中，否则不同的 data 可能得到相同的原文
+		if strings.ContainsAny(k, "=\n") || strings.Contains(val, "\n") {
+			return "", fmt.Errorf("key or value of %q contains reserved characters", k)
+		}
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(val)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,74 @@
+package hmacsha256
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateSign(t *testing.T) {
+	expected := func(payload, key string) string {
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write([]byte(payload))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	testCases := []struct {
+		name     string
+		apiKey   string
+		data     map[string]any
+		wantSign string
+		wantErr  bool
+	}{
+		{
+			name:   "按 key 排序拼接",
+			apiKey: "secret",
+			data: map[string]any{
+				"path":      "/api/create",
+				"method":    "POST",
+				"timestamp": int64(1700000000),
+				"query":     "",
+			},
+			wantSign: expected("method=POST\npath=/api/create\nquery=\ntimestamp=1700000000", "secret"),
+		},
+		{
+			name:     "整数与布尔值",
+			apiKey:   "secret",
+			data:     map[string]any{"b": true, "a": 42},
+			wantSign: expected("a=42\nb=true", "secret"),
+		},
+		{
+			name:    "apiKey 为空",
+			data:    map[string]any{"a": "b"},
+			wantErr: true,
+		},
+		{
+			name:    "value 包含换行",
+			apiKey:  "secret",
+			data:    map[string]any{"a": "b\nc=d"},
+			wantErr: true,
+		},
+		{
+			name:    "不支持的 value 类型",
+			apiKey:  "secret",
+			data:    map[string]any{"a": map[string]any{"b": "c"}},
+			wantErr: true,
+		},
+	}
+
+	h := NewHmacSha256SignHandler()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := h.GenerateSign(tc.data, tc.apiKey)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantSign, got)
+		})
+	}
+}
@@ -21,9 +21,194 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type Platform int32
+
+const (
+	Platform_PLATFORM_UNSPECIFIED Platform = 0
+	Platform_PLATFORM_IOS         Platform = 1
+	Platform_PLATFORM_ANDROID     Platform = 2
+	Platform_PLATFORM_WINDOWS     Platform = 3
+	Platform_PLATFORM_MACOS       Platform = 4
+	Platform_PLATFORM_LINUX       Platform = 5
+)
+
+// Enum value maps for Platform.
+var (
+	Platform_name = map[int32]string{
+		0: "PLATFORM_UNSPECIFIED",
+		1: "PLATFORM_IOS",
+		2: "PLATFORM_ANDROID",
+		3: "PLATFORM_WINDOWS",
+		4: "PLATFORM_MACOS",
+		5: "PLATFORM_LINUX",
+	}
+	Platform_value = map[string]int32{
+		"PLATFORM_UNSPECIFIED": 0,
+		"PLATFORM_IOS":         1,
+		"PLATFORM_ANDROID":     2,
+		"PLATFORM_WINDOWS":     3,
+		"PLATFORM_MACOS":       4,
+		"PLATFORM_LINUX":       5,
+	}
+)
+
+func (x Platform) Enum() *Platform {
+	p := new(Platform)
+	*p = x
+	return p
+}
+
+func (x Platform) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Platform) Descriptor() protoreflect.EnumDescriptor {
+	return file_short_url_proto_enumTypes[0].Descriptor()
+}
+
+func (Platform) Type() protoreflect.EnumType {
+	return &file_short_url_proto_enumTypes[0]
+}
+
+func (x Platform) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Platform.Descriptor instead.
+func (Platform) EnumDescriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{0}
+}
+
+type StatGranularity int32
+
+const (
+	StatGranularity_STAT_GRANULARITY_UNSPECIFIED StatGranularity = 0
+	StatGranularity_STAT_GRANULARITY_HOUR        StatGranularity = 1
+	StatGranularity_STAT_GRANULARITY_DAY         StatGranularity = 2
+)
+
+// Enum value maps for StatGranularity.
+var (
+	StatGranularity_name = map[int32]string{
+		0: "STAT_GRANULARITY_UNSPECIFIED",
+		1: "STAT_GRANULARITY_HOUR",
+		2: "STAT_GRANULARITY_DAY",
+	}
+	StatGranularity_value = map[string]int32{
+		"STAT_GRANULARITY_UNSPECIFIED": 0,
+		"STAT_GRANULARITY_HOUR":        1,
+		"STAT_GRANULARITY_DAY":         2,
+	}
+)
+
+func (x StatGranularity) Enum() *StatGranularity {
+	p := new(StatGranularity)
+	*p = x
+	return p
+}
+
+func (x StatGranularity) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (StatGranularity) Descriptor() protoreflect.EnumDescriptor {
+	return file_short_url_proto_enumTypes[1].Descriptor()
+}
+
+func (StatGranularity) Type() protoreflect.EnumType {
+	return &file_short_url_proto_enumTypes[1]
+}
+
+func (x StatGranularity) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use StatGranularity.Descriptor instead.
+func (StatGranularity) EnumDescriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{1}
+}
+
+type TopWindow int32
+
+const (
+	TopWindow_TOP_WINDOW_UNSPECIFIED TopWindow = 0
+	TopWindow_TOP_WINDOW_5M          TopWindow = 1
+	TopWindow_TOP_WINDOW_1H          TopWindow = 2
+	TopWindow_TOP_WINDOW_1D          TopWindow = 3
+)
+
+// Enum value maps for TopWindow.
+var (
+	TopWindow_name = map[int32]string{
+		0: "TOP_WINDOW_UNSPECIFIED",
+		1: "TOP_WINDOW_5M",
+		2: "TOP_WINDOW_1H",
+		3: "TOP_WINDOW_1D",
+	}
+	TopWindow_value = map[string]int32{
+		"TOP_WINDOW_UNSPECIFIED": 0,
+		"TOP_WINDOW_5M":          1,
+		"TOP_WINDOW_1H":          2,
+		"TOP_WINDOW_1D":          3,
+	}
+)
+
+func (x TopWindow) Enum() *TopWindow {
+	p := new(TopWindow)
+	*p = x
+	return p
+}
+
+func (x TopWindow) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TopWindow) Descriptor() protoreflect.EnumDescriptor {
+	return file_short_url_proto_enumTypes[2].Descriptor()
+}
+
+func (TopWindow) Type() protoreflect.EnumType {
+	return &file_short_url_proto_enumTypes[2]
+}
+
+func (x TopWindow) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TopWindow.Descriptor instead.
+func (TopWindow) EnumDescriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{2}
+}
+
 type GenerateShortUrlRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	OriginUrl     string                 `protobuf:"bytes,1,opt,name=origin_url,json=originUrl,proto3" json:"origin_url,omitempty"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	OriginUrl string                 `protobuf:"bytes,1,opt,name=origin_url,json=originUrl,proto3" json:"origin_url,omitempty"`
+	// 自定义别名，为空时按原链接生成短码
+	CustomAlias string `protobuf:"bytes,2,opt,name=custom_alias,json=customAlias,proto3" json:"custom_alias,omitempty"`
+	// 过期时间戳（秒），与 ttl 互斥
+	ExpiredAt int64 `protobuf:"varint,3,opt,name=expired_at,json=expiredAt,proto3" json:"expired_at,omitempty"`
+	// 有效期（秒），与 expired_at 互斥
+	Ttl int64 `protobuf:"varint,4,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	// 永不过期
+	NeverExpire bool `protobuf:"varint,5,opt,name=never_expire,json=neverExpire,proto3" json:"never_expire,omitempty"`
+	// 跳转状态码，支持 301/302/307/308，为 0 时使用 web 层配置的默认值
+	RedirectCode int32 `protobuf:"varint,6,opt,name=redirect_code,json=redirectCode,proto3" json:"redirect_code,omitempty"`
+	// 跳转时将请求中的查询参数与短码之后的路径追加到原链接
+	PassThrough bool `protobuf:"varint,7,opt,name=pass_through,json=passThrough,proto3" json:"pass_through,omitempty"`
+	// 跳转时合并到原链接的 UTM 参数，非空字段覆盖模板中的同名参数
+	Utm *Utm `protobuf:"bytes,8,opt,name=utm,proto3" json:"utm,omitempty"`
+	// 配置中定义的 UTM 模板名
+	UtmTemplate string `protobuf:"bytes,9,opt,name=utm_template,json=utmTemplate,proto3" json:"utm_template,omitempty"`
+	// 访问密码，为空表示无需密码，仅保存其 bcrypt 哈希
+	Password string `protobuf:"bytes,10,opt,name=password,proto3" json:"password,omitempty"`
+	// 最大点击次数，耗尽后短链接立即失效，0 表示不限
+	MaxClicks int64 `protobuf:"varint,11,opt,name=max_clicks,json=maxClicks,proto3" json:"max_clicks,omitempty"`
+	// 生效时间戳（秒），此前访问展示“尚未生效”页面，0 表示立即生效；指定 ttl 时有效期从生效时间起算
+	NotBefore int64 `protobuf:"varint,12,opt,name=not_before,json=notBefore,proto3" json:"not_before,omitempty"`
+	// 按客户端平台与国家选择跳转目标的规则，按顺序匹配，均未命中时跳转到 origin_url
+	Rules []*RoutingRule `protobuf:"bytes,13,rep,name=rules,proto3" json:"rules,omitempty"`
+	// A/B 分流目标，不为空时至少两个，按权重选择其一代替 origin_url，跳转规则优先于分流
+	Variants      []*Variant `protobuf:"bytes,14,rep,name=variants,proto3" json:"variants,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -65,27 +250,120 @@ func (x *GenerateShortUrlRequest) GetOriginUrl() string {
 	return ""
 }
 
-type GenerateShortUrlResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ShortUrl      string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+func (x *GenerateShortUrlRequest) GetCustomAlias() string {
+	if x != nil {
+		return x.CustomAlias
+	}
+	return ""
+}
+
+func (x *GenerateShortUrlRequest) GetExpiredAt() int64 {
+	if x != nil {
+		return x.ExpiredAt
+	}
+	return 0
+}
+
+func (x *GenerateShortUrlRequest) GetTtl() int64 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+func (x *GenerateShortUrlRequest) GetNeverExpire() bool {
+	if x != nil {
+		return x.NeverExpire
+	}
+	return false
+}
+
+func (x *GenerateShortUrlRequest) GetRedirectCode() int32 {
+	if x != nil {
+		return x.RedirectCode
+	}
+	return 0
+}
+
+func (x *GenerateShortUrlRequest) GetPassThrough() bool {
+	if x != nil {
+		return x.PassThrough
+	}
+	return false
+}
+
+func (x *GenerateShortUrlRequest) GetUtm() *Utm {
+	if x != nil {
+		return x.Utm
+	}
+	return nil
+}
+
+func (x *GenerateShortUrlRequest) GetUtmTemplate() string {
+	if x != nil {
+		return x.UtmTemplate
+	}
+	return ""
+}
+
+func (x *GenerateShortUrlRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *GenerateShortUrlRequest) GetMaxClicks() int64 {
+	if x != nil {
+		return x.MaxClicks
+	}
+	return 0
+}
+
+func (x *GenerateShortUrlRequest) GetNotBefore() int64 {
+	if x != nil {
+		return x.NotBefore
+	}
+	return 0
+}
+
+func (x *GenerateShortUrlRequest) GetRules() []*RoutingRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *GenerateShortUrlRequest) GetVariants() []*Variant {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+type Variant struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Target string                 `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
+	// 相对权重，必须为正数，如 70 与 30
+	Weight        int32 `protobuf:"varint,2,opt,name=weight,proto3" json:"weight,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GenerateShortUrlResponse) Reset() {
-	*x = GenerateShortUrlResponse{}
+func (x *Variant) Reset() {
+	*x = Variant{}
 	mi := &file_short_url_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GenerateShortUrlResponse) String() string {
+func (x *Variant) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GenerateShortUrlResponse) ProtoMessage() {}
+func (*Variant) ProtoMessage() {}
 
-func (x *GenerateShortUrlResponse) ProtoReflect() protoreflect.Message {
+func (x *Variant) ProtoReflect() protoreflect.Message {
 	mi := &file_short_url_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -97,39 +375,52 @@ func (x *GenerateShortUrlResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GenerateShortUrlResponse.ProtoReflect.Descriptor instead.
-func (*GenerateShortUrlResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use Variant.ProtoReflect.Descriptor instead.
+func (*Variant) Descriptor() ([]byte, []int) {
 	return file_short_url_proto_rawDescGZIP(), []int{1}
 }
 
-func (x *GenerateShortUrlResponse) GetShortUrl() string {
+func (x *Variant) GetTarget() string {
 	if x != nil {
-		return x.ShortUrl
+		return x.Target
 	}
 	return ""
 }
 
-type GetOriginUrlRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ShortUrl      string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+func (x *Variant) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+// 跳转规则，platform 与 country 至少指定一个，都指定时需同时满足
+type RoutingRule struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// PLATFORM_UNSPECIFIED 表示不限平台
+	Platform Platform `protobuf:"varint,1,opt,name=platform,proto3,enum=short_url.v1.Platform" json:"platform,omitempty"`
+	// 跳转目标，支持 itms-apps://、market:// 等应用商店协议
+	Target string `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	// ISO 3166-1 二位大写国家代码，为空表示不限国家
+	Country       string `protobuf:"bytes,3,opt,name=country,proto3" json:"country,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetOriginUrlRequest) Reset() {
-	*x = GetOriginUrlRequest{}
+func (x *RoutingRule) Reset() {
+	*x = RoutingRule{}
 	mi := &file_short_url_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetOriginUrlRequest) String() string {
+func (x *RoutingRule) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetOriginUrlRequest) ProtoMessage() {}
+func (*RoutingRule) ProtoMessage() {}
 
-func (x *GetOriginUrlRequest) ProtoReflect() protoreflect.Message {
+func (x *RoutingRule) ProtoReflect() protoreflect.Message {
 	mi := &file_short_url_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -141,39 +432,57 @@ func (x *GetOriginUrlRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetOriginUrlRequest.ProtoReflect.Descriptor instead.
-func (*GetOriginUrlRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use RoutingRule.ProtoReflect.Descriptor instead.
+func (*RoutingRule) Descriptor() ([]byte, []int) {
 	return file_short_url_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *GetOriginUrlRequest) GetShortUrl() string {
+func (x *RoutingRule) GetPlatform() Platform {
 	if x != nil {
-		return x.ShortUrl
+		return x.Platform
+	}
+	return Platform_PLATFORM_UNSPECIFIED
+}
+
+func (x *RoutingRule) GetTarget() string {
+	if x != nil {
+		return x.Target
 	}
 	return ""
 }
 
-type GetOriginUrlResponse struct {
+func (x *RoutingRule) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+type Utm struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	OriginUrl     string                 `protobuf:"bytes,1,opt,name=origin_url,json=originUrl,proto3" json:"origin_url,omitempty"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Medium        string                 `protobuf:"bytes,2,opt,name=medium,proto3" json:"medium,omitempty"`
+	Campaign      string                 `protobuf:"bytes,3,opt,name=campaign,proto3" json:"campaign,omitempty"`
+	Term          string                 `protobuf:"bytes,4,opt,name=term,proto3" json:"term,omitempty"`
+	Content       string                 `protobuf:"bytes,5,opt,name=content,proto3" json:"content,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetOriginUrlResponse) Reset() {
-	*x = GetOriginUrlResponse{}
+func (x *Utm) Reset() {
+	*x = Utm{}
 	mi := &file_short_url_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetOriginUrlResponse) String() string {
+func (x *Utm) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetOriginUrlResponse) ProtoMessage() {}
+func (*Utm) ProtoMessage() {}
 
-func (x *GetOriginUrlResponse) ProtoReflect() protoreflect.Message {
+func (x *Utm) ProtoReflect() protoreflect.Message {
 	mi := &file_short_url_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -185,36 +494,2393 @@ func (x *GetOriginUrlResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetOriginUrlResponse.ProtoReflect.Descriptor instead.
-func (*GetOriginUrlResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use Utm.ProtoReflect.Descriptor instead.
+func (*Utm) Descriptor() ([]byte, []int) {
 	return file_short_url_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *GetOriginUrlResponse) GetOriginUrl() string {
+func (x *Utm) GetSource() string {
 	if x != nil {
-		return x.OriginUrl
+		return x.Source
 	}
 	return ""
 }
 
-var File_short_url_proto protoreflect.FileDescriptor
+func (x *Utm) GetMedium() string {
+	if x != nil {
+		return x.Medium
+	}
+	return ""
+}
 
-const file_short_url_proto_rawDesc = "" +
-	"\n" +
-	"\x0fshort_url.proto\x12\fshort_url.v1\"8\n" +
-	"\x17GenerateShortUrlRequest\x12\x1d\n" +
-	"\n" +
-	"origin_url\x18\x01 \x01(\tR\toriginUrl\"7\n" +
-	"\x18GenerateShortUrlResponse\x12\x1b\n" +
-	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\"2\n" +
-	"\x13GetOriginUrlRequest\x12\x1b\n" +
-	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\"5\n" +
-	"\x14GetOriginUrlResponse\x12\x1d\n" +
+func (x *Utm) GetCampaign() string {
+	if x != nil {
+		return x.Campaign
+	}
+	return ""
+}
+
+func (x *Utm) GetTerm() string {
+	if x != nil {
+		return x.Term
+	}
+	return ""
+}
+
+func (x *Utm) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+type GenerateShortUrlResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	ShortUrl string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	// 过期时间戳（秒），永不过期时为 0
+	ExpiredAt    int64 `protobuf:"varint,2,opt,name=expired_at,json=expiredAt,proto3" json:"expired_at,omitempty"`
+	NeverExpire  bool  `protobuf:"varint,3,opt,name=never_expire,json=neverExpire,proto3" json:"never_expire,omitempty"`
+	RedirectCode int32 `protobuf:"varint,4,opt,name=redirect_code,json=redirectCode,proto3" json:"redirect_code,omitempty"`
+	PassThrough  bool  `protobuf:"varint,5,opt,name=pass_through,json=passThrough,proto3" json:"pass_through,omitempty"`
+	// 合并模板后的 UTM 参数
+	Utm               *Utm           `protobuf:"bytes,6,opt,name=utm,proto3" json:"utm,omitempty"`
+	PasswordProtected bool           `protobuf:"varint,7,opt,name=password_protected,json=passwordProtected,proto3" json:"password_protected,omitempty"`
+	MaxClicks         int64          `protobuf:"varint,8,opt,name=max_clicks,json=maxClicks,proto3" json:"max_clicks,omitempty"`
+	NotBefore         int64          `protobuf:"varint,9,opt,name=not_before,json=notBefore,proto3" json:"not_before,omitempty"`
+	Rules             []*RoutingRule `protobuf:"bytes,10,rep,name=rules,proto3" json:"rules,omitempty"`
+	Variants          []*Variant     `protobuf:"bytes,11,rep,name=variants,proto3" json:"variants,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *GenerateShortUrlResponse) Reset() {
+	*x = GenerateShortUrlResponse{}
+	mi := &file_short_url_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerateShortUrlResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateShortUrlResponse) ProtoMessage() {}
+
+func (x *GenerateShortUrlResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateShortUrlResponse.ProtoReflect.Descriptor instead.
+func (*GenerateShortUrlResponse) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GenerateShortUrlResponse) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *GenerateShortUrlResponse) GetExpiredAt() int64 {
+	if x != nil {
+		return x.ExpiredAt
+	}
+	return 0
+}
+
+func (x *GenerateShortUrlResponse) GetNeverExpire() bool {
+	if x != nil {
+		return x.NeverExpire
+	}
+	return false
+}
+
+func (x *GenerateShortUrlResponse) GetRedirectCode() int32 {
+	if x != nil {
+		return x.RedirectCode
+	}
+	return 0
+}
+
+func (x *GenerateShortUrlResponse) GetPassThrough() bool {
+	if x != nil {
+		return x.PassThrough
+	}
+	return false
+}
+
+func (x *GenerateShortUrlResponse) GetUtm() *Utm {
+	if x != nil {
+		return x.Utm
+	}
+	return nil
+}
+
+func (x *GenerateShortUrlResponse) GetPasswordProtected() bool {
+	if x != nil {
+		return x.PasswordProtected
+	}
+	return false
+}
+
+func (x *GenerateShortUrlResponse) GetMaxClicks() int64 {
+	if x != nil {
+		return x.MaxClicks
+	}
+	return 0
+}
+
+func (x *GenerateShortUrlResponse) GetNotBefore() int64 {
+	if x != nil {
+		return x.NotBefore
+	}
+	return 0
+}
+
+func (x *GenerateShortUrlResponse) GetRules() []*RoutingRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *GenerateShortUrlResponse) GetVariants() []*Variant {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+type GetOriginUrlRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortUrl      string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOriginUrlRequest) Reset() {
+	*x = GetOriginUrlRequest{}
+	mi := &file_short_url_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOriginUrlRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOriginUrlRequest) ProtoMessage() {}
+
+func (x *GetOriginUrlRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOriginUrlRequest.ProtoReflect.Descriptor instead.
+func (*GetOriginUrlRequest) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetOriginUrlRequest) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+type GetOriginUrlResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	OriginUrl string                 `protobuf:"bytes,1,opt,name=origin_url,json=originUrl,proto3" json:"origin_url,omitempty"`
+	// 跳转状态码，为 0 时使用 web 层配置的默认值
+	RedirectCode int32 `protobuf:"varint,2,opt,name=redirect_code,json=redirectCode,proto3" json:"redirect_code,omitempty"`
+	PassThrough  bool  `protobuf:"varint,3,opt,name=pass_through,json=passThrough,proto3" json:"pass_through,omitempty"`
+	Utm          *Utm  `protobuf:"bytes,4,opt,name=utm,proto3" json:"utm,omitempty"`
+	// 需要密码访问，web 层校验通过后才可跳转
+	PasswordProtected bool `protobuf:"varint,5,opt,name=password_protected,json=passwordProtected,proto3" json:"password_protected,omitempty"`
+	// 大于 0 时每次跳转前需调用 ConsumeClick 扣减点击预算
+	MaxClicks int64 `protobuf:"varint,6,opt,name=max_clicks,json=maxClicks,proto3" json:"max_clicks,omitempty"`
+	// web 层按 User-Agent 识别平台、按客户端 IP 识别国家并选择跳转目标
+	Rules []*RoutingRule `protobuf:"bytes,7,rep,name=rules,proto3" json:"rules,omitempty"`
+	// web 层按权重选择分流目标，并通过 cookie 保持同一访客的选择
+	Variants      []*Variant `protobuf:"bytes,8,rep,name=variants,proto3" json:"variants,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOriginUrlResponse) Reset() {
+	*x = GetOriginUrlResponse{}
+	mi := &file_short_url_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOriginUrlResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOriginUrlResponse) ProtoMessage() {}
+
+func (x *GetOriginUrlResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOriginUrlResponse.ProtoReflect.Descriptor instead.
+func (*GetOriginUrlResponse) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetOriginUrlResponse) GetOriginUrl() string {
+	if x != nil {
+		return x.OriginUrl
+	}
+	return ""
+}
+
+func (x *GetOriginUrlResponse) GetRedirectCode() int32 {
+	if x != nil {
+		return x.RedirectCode
+	}
+	return 0
+}
+
+func (x *GetOriginUrlResponse) GetPassThrough() bool {
+	if x != nil {
+		return x.PassThrough
+	}
+	return false
+}
+
+func (x *GetOriginUrlResponse) GetUtm() *Utm {
+	if x != nil {
+		return x.Utm
+	}
+	return nil
+}
+
+func (x *GetOriginUrlResponse) GetPasswordProtected() bool {
+	if x != nil {
+		return x.PasswordProtected
+	}
+	return false
+}
+
+func (x *GetOriginUrlResponse) GetMaxClicks() int64 {
+	if x != nil {
+		return x.MaxClicks
+	}
+	return 0
+}
+
+func (x *GetOriginUrlResponse) GetRules() []*RoutingRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *GetOriginUrlResponse) GetVariants() []*Variant {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+type ShortUrlInfo struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ShortUrl  string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	OriginUrl string                 `protobuf:"bytes,2,opt,name=origin_url,json=originUrl,proto3" json:"origin_url,omitempty"`
+	// 过期时间戳（秒），永不过期时为 0
+	ExpiredAt         int64          `protobuf:"varint,3,opt,name=expired_at,json=expiredAt,proto3" json:"expired_at,omitempty"`
+	NeverExpire       bool           `protobuf:"varint,4,opt,name=never_expire,json=neverExpire,proto3" json:"never_expire,omitempty"`
+	RedirectCode      int32          `protobuf:"varint,5,opt,name=redirect_code,json=redirectCode,proto3" json:"redirect_code,omitempty"`
+	PassThrough       bool           `protobuf:"varint,6,opt,name=pass_through,json=passThrough,proto3" json:"pass_through,omitempty"`
+	Utm               *Utm           `protobuf:"bytes,7,opt,name=utm,proto3" json:"utm,omitempty"`
+	UtmTemplate       string         `protobuf:"bytes,8,opt,name=utm_template,json=utmTemplate,proto3" json:"utm_template,omitempty"`
+	PasswordProtected bool           `protobuf:"varint,9,opt,name=password_protected,json=passwordProtected,proto3" json:"password_protected,omitempty"`
+	MaxClicks         int64          `protobuf:"varint,10,opt,name=max_clicks,json=maxClicks,proto3" json:"max_clicks,omitempty"`
+	NotBefore         int64          `protobuf:"varint,11,opt,name=not_before,json=notBefore,proto3" json:"not_before,omitempty"`
+	Rules             []*RoutingRule `protobuf:"bytes,12,rep,name=rules,proto3" json:"rules,omitempty"`
+	Variants          []*Variant     `protobuf:"bytes,13,rep,name=variants,proto3" json:"variants,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ShortUrlInfo) Reset() {
+	*x = ShortUrlInfo{}
+	mi := &file_short_url_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShortUrlInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShortUrlInfo) ProtoMessage() {}
+
+func (x *ShortUrlInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShortUrlInfo.ProtoReflect.Descriptor instead.
+func (*ShortUrlInfo) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ShortUrlInfo) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *ShortUrlInfo) GetOriginUrl() string {
+	if x != nil {
+		return x.OriginUrl
+	}
+	return ""
+}
+
+func (x *ShortUrlInfo) GetExpiredAt() int64 {
+	if x != nil {
+		return x.ExpiredAt
+	}
+	return 0
+}
+
+func (x *ShortUrlInfo) GetNeverExpire() bool {
+	if x != nil {
+		return x.NeverExpire
+	}
+	return false
+}
+
+func (x *ShortUrlInfo) GetRedirectCode() int32 {
+	if x != nil {
+		return x.RedirectCode
+	}
+	return 0
+}
+
+func (x *ShortUrlInfo) GetPassThrough() bool {
+	if x != nil {
+		return x.PassThrough
+	}
+	return false
+}
+
+func (x *ShortUrlInfo) GetUtm() *Utm {
+	if x != nil {
+		return x.Utm
+	}
+	return nil
+}
+
+func (x *ShortUrlInfo) GetUtmTemplate() string {
+	if x != nil {
+		return x.UtmTemplate
+	}
+	return ""
+}
+
+func (x *ShortUrlInfo) GetPasswordProtected() bool {
+	if x != nil {
+		return x.PasswordProtected
+	}
+	return false
+}
+
+func (x *ShortUrlInfo) GetMaxClicks() int64 {
+	if x != nil {
+		return x.MaxClicks
+	}
+	return 0
+}
+
+func (x *ShortUrlInfo) GetNotBefore() int64 {
+	if x != nil {
+		return x.NotBefore
+	}
+	return 0
+}
+
+func (x *ShortUrlInfo) GetRules() []*RoutingRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *ShortUrlInfo) GetVariants() []*Variant {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+type GetShortUrlInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortUrl      string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetShortUrlInfoRequest) Reset() {
+	*x = GetShortUrlInfoRequest{}
+	mi := &file_short_url_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetShortUrlInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetShortUrlInfoRequest) ProtoMessage() {}
+
+func (x *GetShortUrlInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetShortUrlInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetShortUrlInfoRequest) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetShortUrlInfoRequest) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+type GetShortUrlInfoResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Info          *ShortUrlInfo          `protobuf:"bytes,1,opt,name=info,proto3" json:"info,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetShortUrlInfoResponse) Reset() {
+	*x = GetShortUrlInfoResponse{}
+	mi := &file_short_url_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetShortUrlInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetShortUrlInfoResponse) ProtoMessage() {}
+
+func (x *GetShortUrlInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetShortUrlInfoResponse.ProtoReflect.Descriptor instead.
+func (*GetShortUrlInfoResponse) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetShortUrlInfoResponse) GetInfo() *ShortUrlInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+type UpdateOriginUrlRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortUrl      string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	OriginUrl     string                 `protobuf:"bytes,2,opt,name=origin_url,json=originUrl,proto3" json:"origin_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateOriginUrlRequest) Reset() {
+	*x = UpdateOriginUrlRequest{}
+	mi := &file_short_url_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateOriginUrlRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateOriginUrlRequest) ProtoMessage() {}
+
+func (x *UpdateOriginUrlRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateOriginUrlRequest.ProtoReflect.Descriptor instead.
+func (*UpdateOriginUrlRequest) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *UpdateOriginUrlRequest) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *UpdateOriginUrlRequest) GetOriginUrl() string {
+	if x != nil {
+		return x.OriginUrl
+	}
+	return ""
+}
+
+type UpdateOriginUrlResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Info          *ShortUrlInfo          `protobuf:"bytes,1,opt,name=info,proto3" json:"info,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateOriginUrlResponse) Reset() {
+	*x = UpdateOriginUrlResponse{}
+	mi := &file_short_url_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateOriginUrlResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateOriginUrlResponse) ProtoMessage() {}
+
+func (x *UpdateOriginUrlResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateOriginUrlResponse.ProtoReflect.Descriptor instead.
+func (*UpdateOriginUrlResponse) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UpdateOriginUrlResponse) GetInfo() *ShortUrlInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+type DeleteShortUrlRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortUrl      string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteShortUrlRequest) Reset() {
+	*x = DeleteShortUrlRequest{}
+	mi := &file_short_url_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteShortUrlRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteShortUrlRequest) ProtoMessage() {}
+
+func (x *DeleteShortUrlRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteShortUrlRequest.ProtoReflect.Descriptor instead.
+func (*DeleteShortUrlRequest) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DeleteShortUrlRequest) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+type DeleteShortUrlResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteShortUrlResponse) Reset() {
+	*x = DeleteShortUrlResponse{}
+	mi := &file_short_url_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteShortUrlResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteShortUrlResponse) ProtoMessage() {}
+
+func (x *DeleteShortUrlResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteShortUrlResponse.ProtoReflect.Descriptor instead.
+func (*DeleteShortUrlResponse) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{13}
+}
+
+type ExtendExpirationRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	ShortUrl string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	// 新的过期时间戳（秒），必须晚于当前过期时间，与 ttl 互斥
+	ExpiredAt int64 `protobuf:"varint,2,opt,name=expired_at,json=expiredAt,proto3" json:"expired_at,omitempty"`
+	// 在当前过期时间（已过期则为当前时间）基础上延长的秒数，与 expired_at 互斥
+	Ttl int64 `protobuf:"varint,3,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	// 设置为永不过期
+	NeverExpire   bool `protobuf:"varint,4,opt,name=never_expire,json=neverExpire,proto3" json:"never_expire,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtendExpirationRequest) Reset() {
+	*x = ExtendExpirationRequest{}
+	mi := &file_short_url_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtendExpirationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtendExpirationRequest) ProtoMessage() {}
+
+func (x *ExtendExpirationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtendExpirationRequest.ProtoReflect.Descriptor instead.
+func (*ExtendExpirationRequest) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ExtendExpirationRequest) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *ExtendExpirationRequest) GetExpiredAt() int64 {
+	if x != nil {
+		return x.ExpiredAt
+	}
+	return 0
+}
+
+func (x *ExtendExpirationRequest) GetTtl() int64 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+func (x *ExtendExpirationRequest) GetNeverExpire() bool {
+	if x != nil {
+		return x.NeverExpire
+	}
+	return false
+}
+
+type ExtendExpirationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Info          *ShortUrlInfo          `protobuf:"bytes,1,opt,name=info,proto3" json:"info,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtendExpirationResponse) Reset() {
+	*x = ExtendExpirationResponse{}
+	mi := &file_short_url_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtendExpirationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtendExpirationResponse) ProtoMessage() {}
+
+func (x *ExtendExpirationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtendExpirationResponse.ProtoReflect.Descriptor instead.
+func (*ExtendExpirationResponse) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ExtendExpirationResponse) GetInfo() *ShortUrlInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+type BatchGenerateShortUrlRequest struct {
+	state         protoimpl.MessageState     `protogen:"open.v1"`
+	Items         []*GenerateShortUrlRequest `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGenerateShortUrlRequest) Reset() {
+	*x = BatchGenerateShortUrlRequest{}
+	mi := &file_short_url_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGenerateShortUrlRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGenerateShortUrlRequest) ProtoMessage() {}
+
+func (x *BatchGenerateShortUrlRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGenerateShortUrlRequest.ProtoReflect.Descriptor instead.
+func (*BatchGenerateShortUrlRequest) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *BatchGenerateShortUrlRequest) GetItems() []*GenerateShortUrlRequest {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type BatchGenerateShortUrlResult struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	ShortUrl string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	// 过期时间戳（秒），永不过期时为 0
+	ExpiredAt   int64 `protobuf:"varint,2,opt,name=expired_at,json=expiredAt,proto3" json:"expired_at,omitempty"`
+	NeverExpire bool  `protobuf:"varint,3,opt,name=never_expire,json=neverExpire,proto3" json:"never_expire,omitempty"`
+	// 单条失败时的 gRPC 状态码，成功时为 0
+	Code          int32  `protobuf:"varint,4,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGenerateShortUrlResult) Reset() {
+	*x = BatchGenerateShortUrlResult{}
+	mi := &file_short_url_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGenerateShortUrlResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGenerateShortUrlResult) ProtoMessage() {}
+
+func (x *BatchGenerateShortUrlResult) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGenerateShortUrlResult.ProtoReflect.Descriptor instead.
+func (*BatchGenerateShortUrlResult) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *BatchGenerateShortUrlResult) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *BatchGenerateShortUrlResult) GetExpiredAt() int64 {
+	if x != nil {
+		return x.ExpiredAt
+	}
+	return 0
+}
+
+func (x *BatchGenerateShortUrlResult) GetNeverExpire() bool {
+	if x != nil {
+		return x.NeverExpire
+	}
+	return false
+}
+
+func (x *BatchGenerateShortUrlResult) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *BatchGenerateShortUrlResult) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type BatchGenerateShortUrlResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 与请求中的 items 一一对应
+	Results       []*BatchGenerateShortUrlResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGenerateShortUrlResponse) Reset() {
+	*x = BatchGenerateShortUrlResponse{}
+	mi := &file_short_url_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGenerateShortUrlResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGenerateShortUrlResponse) ProtoMessage() {}
+
+func (x *BatchGenerateShortUrlResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGenerateShortUrlResponse.ProtoReflect.Descriptor instead.
+func (*BatchGenerateShortUrlResponse) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *BatchGenerateShortUrlResponse) GetResults() []*BatchGenerateShortUrlResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type BatchGetOriginUrlRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortUrls     []string               `protobuf:"bytes,1,rep,name=short_urls,json=shortUrls,proto3" json:"short_urls,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetOriginUrlRequest) Reset() {
+	*x = BatchGetOriginUrlRequest{}
+	mi := &file_short_url_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetOriginUrlRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetOriginUrlRequest) ProtoMessage() {}
+
+func (x *BatchGetOriginUrlRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetOriginUrlRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetOriginUrlRequest) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *BatchGetOriginUrlRequest) GetShortUrls() []string {
+	if x != nil {
+		return x.ShortUrls
+	}
+	return nil
+}
+
+type BatchGetOriginUrlResult struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ShortUrl  string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	OriginUrl string                 `protobuf:"bytes,2,opt,name=origin_url,json=originUrl,proto3" json:"origin_url,omitempty"`
+	// 单条失败时的 gRPC 状态码，成功时为 0
+	Code          int32  `protobuf:"varint,3,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetOriginUrlResult) Reset() {
+	*x = BatchGetOriginUrlResult{}
+	mi := &file_short_url_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetOriginUrlResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetOriginUrlResult) ProtoMessage() {}
+
+func (x *BatchGetOriginUrlResult) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetOriginUrlResult.ProtoReflect.Descriptor instead.
+func (*BatchGetOriginUrlResult) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *BatchGetOriginUrlResult) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *BatchGetOriginUrlResult) GetOriginUrl() string {
+	if x != nil {
+		return x.OriginUrl
+	}
+	return ""
+}
+
+func (x *BatchGetOriginUrlResult) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *BatchGetOriginUrlResult) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type BatchGetOriginUrlResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 与请求中的 short_urls 一一对应
+	Results       []*BatchGetOriginUrlResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetOriginUrlResponse) Reset() {
+	*x = BatchGetOriginUrlResponse{}
+	mi := &file_short_url_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetOriginUrlResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetOriginUrlResponse) ProtoMessage() {}
+
+func (x *BatchGetOriginUrlResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetOriginUrlResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetOriginUrlResponse) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *BatchGetOriginUrlResponse) GetResults() []*BatchGetOriginUrlResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type ClickEvent struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	ShortUrl string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	// 点击时间戳（毫秒）
+	Timestamp int64  `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Referrer  string `protobuf:"bytes,3,opt,name=referrer,proto3" json:"referrer,omitempty"`
+	UserAgent string `protobuf:"bytes,4,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
+	// 加盐哈希后的客户端 IP，不传输原始 IP
+	IpHash string `protobuf:"bytes,5,opt,name=ip_hash,json=ipHash,proto3" json:"ip_hash,omitempty"`
+	// 由客户端 IP 解析的 ISO 3166-1 二位国家代码，无法解析时为空
+	Country string `protobuf:"bytes,6,opt,name=country,proto3" json:"country,omitempty"`
+	// 命中的 A/B 分流目标序号，从 1 开始，0 表示未分流
+	Variant       int32 `protobuf:"varint,7,opt,name=variant,proto3" json:"variant,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClickEvent) Reset() {
+	*x = ClickEvent{}
+	mi := &file_short_url_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClickEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClickEvent) ProtoMessage() {}
+
+func (x *ClickEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClickEvent.ProtoReflect.Descriptor instead.
+func (*ClickEvent) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ClickEvent) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *ClickEvent) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *ClickEvent) GetReferrer() string {
+	if x != nil {
+		return x.Referrer
+	}
+	return ""
+}
+
+func (x *ClickEvent) GetUserAgent() string {
+	if x != nil {
+		return x.UserAgent
+	}
+	return ""
+}
+
+func (x *ClickEvent) GetIpHash() string {
+	if x != nil {
+		return x.IpHash
+	}
+	return ""
+}
+
+func (x *ClickEvent) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *ClickEvent) GetVariant() int32 {
+	if x != nil {
+		return x.Variant
+	}
+	return 0
+}
+
+type ReportClicksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*ClickEvent          `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportClicksRequest) Reset() {
+	*x = ReportClicksRequest{}
+	mi := &file_short_url_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportClicksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportClicksRequest) ProtoMessage() {}
+
+func (x *ReportClicksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportClicksRequest.ProtoReflect.Descriptor instead.
+func (*ReportClicksRequest) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ReportClicksRequest) GetEvents() []*ClickEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type ReportClicksResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 实际计入统计的事件数
+	Accepted      int32 `protobuf:"varint,1,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportClicksResponse) Reset() {
+	*x = ReportClicksResponse{}
+	mi := &file_short_url_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportClicksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportClicksResponse) ProtoMessage() {}
+
+func (x *ReportClicksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportClicksResponse.ProtoReflect.Descriptor instead.
+func (*ReportClicksResponse) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ReportClicksResponse) GetAccepted() int32 {
+	if x != nil {
+		return x.Accepted
+	}
+	return 0
+}
+
+type GetClickStatsRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	ShortUrl string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	// 未指定时按小时统计
+	Granularity StatGranularity `protobuf:"varint,2,opt,name=granularity,proto3,enum=short_url.v1.StatGranularity" json:"granularity,omitempty"`
+	// 查询区间 [start, end)，时间戳（秒），为 0 时使用默认区间
+	Start         int64 `protobuf:"varint,3,opt,name=start,proto3" json:"start,omitempty"`
+	End           int64 `protobuf:"varint,4,opt,name=end,proto3" json:"end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetClickStatsRequest) Reset() {
+	*x = GetClickStatsRequest{}
+	mi := &file_short_url_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetClickStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetClickStatsRequest) ProtoMessage() {}
+
+func (x *GetClickStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetClickStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetClickStatsRequest) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *GetClickStatsRequest) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *GetClickStatsRequest) GetGranularity() StatGranularity {
+	if x != nil {
+		return x.Granularity
+	}
+	return StatGranularity_STAT_GRANULARITY_UNSPECIFIED
+}
+
+func (x *GetClickStatsRequest) GetStart() int64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *GetClickStatsRequest) GetEnd() int64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+type ClickStatPoint struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 时间桶起始时间戳（秒）
+	Timestamp int64 `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Clicks    int64 `protobuf:"varint,2,opt,name=clicks,proto3" json:"clicks,omitempty"`
+	// 近似独立访客数，仅按天统计时返回
+	UniqueVisitors int64 `protobuf:"varint,3,opt,name=unique_visitors,json=uniqueVisitors,proto3" json:"unique_visitors,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ClickStatPoint) Reset() {
+	*x = ClickStatPoint{}
+	mi := &file_short_url_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClickStatPoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClickStatPoint) ProtoMessage() {}
+
+func (x *ClickStatPoint) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClickStatPoint.ProtoReflect.Descriptor instead.
+func (*ClickStatPoint) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ClickStatPoint) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *ClickStatPoint) GetClicks() int64 {
+	if x != nil {
+		return x.Clicks
+	}
+	return 0
+}
+
+func (x *ClickStatPoint) GetUniqueVisitors() int64 {
+	if x != nil {
+		return x.UniqueVisitors
+	}
+	return 0
+}
+
+type GetClickStatsResponse struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ShortUrl    string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	Granularity StatGranularity        `protobuf:"varint,2,opt,name=granularity,proto3,enum=short_url.v1.StatGranularity" json:"granularity,omitempty"`
+	// 全部历史点击数
+	Total int64 `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+	// 查询区间内的点击数
+	RangeTotal int64 `protobuf:"varint,4,opt,name=range_total,json=rangeTotal,proto3" json:"range_total,omitempty"`
+	// 按时间升序排列，没有点击的时间桶也会补 0
+	Points []*ClickStatPoint `protobuf:"bytes,5,rep,name=points,proto3" json:"points,omitempty"`
+	// 查询区间内的近似独立访客数，跨天重复访问只计一次，仅按天统计时返回
+	RangeUniqueVisitors int64 `protobuf:"varint,6,opt,name=range_unique_visitors,json=rangeUniqueVisitors,proto3" json:"range_unique_visitors,omitempty"`
+	// 查询区间内按国家汇总的点击数，按点击数降序，仅按天统计时返回
+	Countries []*CountryClicks `protobuf:"bytes,7,rep,name=countries,proto3" json:"countries,omitempty"`
+	// 查询区间内各 A/B 分流目标的点击数，按序号升序，仅按天统计且配置了分流时返回
+	Variants      []*VariantClicks `protobuf:"bytes,8,rep,name=variants,proto3" json:"variants,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetClickStatsResponse) Reset() {
+	*x = GetClickStatsResponse{}
+	mi := &file_short_url_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetClickStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetClickStatsResponse) ProtoMessage() {}
+
+func (x *GetClickStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetClickStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetClickStatsResponse) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *GetClickStatsResponse) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *GetClickStatsResponse) GetGranularity() StatGranularity {
+	if x != nil {
+		return x.Granularity
+	}
+	return StatGranularity_STAT_GRANULARITY_UNSPECIFIED
+}
+
+func (x *GetClickStatsResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *GetClickStatsResponse) GetRangeTotal() int64 {
+	if x != nil {
+		return x.RangeTotal
+	}
+	return 0
+}
+
+func (x *GetClickStatsResponse) GetPoints() []*ClickStatPoint {
+	if x != nil {
+		return x.Points
+	}
+	return nil
+}
+
+func (x *GetClickStatsResponse) GetRangeUniqueVisitors() int64 {
+	if x != nil {
+		return x.RangeUniqueVisitors
+	}
+	return 0
+}
+
+func (x *GetClickStatsResponse) GetCountries() []*CountryClicks {
+	if x != nil {
+		return x.Countries
+	}
+	return nil
+}
+
+func (x *GetClickStatsResponse) GetVariants() []*VariantClicks {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+type VariantClicks struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 从 1 开始的序号，与创建时 variants 的顺序一致
+	Variant       int32  `protobuf:"varint,1,opt,name=variant,proto3" json:"variant,omitempty"`
+	Target        string `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	Weight        int32  `protobuf:"varint,3,opt,name=weight,proto3" json:"weight,omitempty"`
+	Clicks        int64  `protobuf:"varint,4,opt,name=clicks,proto3" json:"clicks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VariantClicks) Reset() {
+	*x = VariantClicks{}
+	mi := &file_short_url_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VariantClicks) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VariantClicks) ProtoMessage() {}
+
+func (x *VariantClicks) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VariantClicks.ProtoReflect.Descriptor instead.
+func (*VariantClicks) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *VariantClicks) GetVariant() int32 {
+	if x != nil {
+		return x.Variant
+	}
+	return 0
+}
+
+func (x *VariantClicks) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *VariantClicks) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *VariantClicks) GetClicks() int64 {
+	if x != nil {
+		return x.Clicks
+	}
+	return 0
+}
+
+type CountryClicks struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Country       string                 `protobuf:"bytes,1,opt,name=country,proto3" json:"country,omitempty"`
+	Clicks        int64                  `protobuf:"varint,2,opt,name=clicks,proto3" json:"clicks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CountryClicks) Reset() {
+	*x = CountryClicks{}
+	mi := &file_short_url_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CountryClicks) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountryClicks) ProtoMessage() {}
+
+func (x *CountryClicks) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountryClicks.ProtoReflect.Descriptor instead.
+func (*CountryClicks) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *CountryClicks) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *CountryClicks) GetClicks() int64 {
+	if x != nil {
+		return x.Clicks
+	}
+	return 0
+}
+
+type ListTopLinksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 未指定时使用最近 1 小时
+	Window TopWindow `protobuf:"varint,1,opt,name=window,proto3,enum=short_url.v1.TopWindow" json:"window,omitempty"`
+	// 返回条数，为 0 时使用默认值 10
+	Limit         int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTopLinksRequest) Reset() {
+	*x = ListTopLinksRequest{}
+	mi := &file_short_url_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTopLinksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTopLinksRequest) ProtoMessage() {}
+
+func (x *ListTopLinksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTopLinksRequest.ProtoReflect.Descriptor instead.
+func (*ListTopLinksRequest) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ListTopLinksRequest) GetWindow() TopWindow {
+	if x != nil {
+		return x.Window
+	}
+	return TopWindow_TOP_WINDOW_UNSPECIFIED
+}
+
+func (x *ListTopLinksRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type TopLink struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortUrl      string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	Clicks        int64                  `protobuf:"varint,2,opt,name=clicks,proto3" json:"clicks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopLink) Reset() {
+	*x = TopLink{}
+	mi := &file_short_url_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopLink) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopLink) ProtoMessage() {}
+
+func (x *TopLink) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopLink.ProtoReflect.Descriptor instead.
+func (*TopLink) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *TopLink) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *TopLink) GetClicks() int64 {
+	if x != nil {
+		return x.Clicks
+	}
+	return 0
+}
+
+type ListTopLinksResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Window TopWindow              `protobuf:"varint,1,opt,name=window,proto3,enum=short_url.v1.TopWindow" json:"window,omitempty"`
+	// 按点击数降序排列
+	Links         []*TopLink `protobuf:"bytes,2,rep,name=links,proto3" json:"links,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTopLinksResponse) Reset() {
+	*x = ListTopLinksResponse{}
+	mi := &file_short_url_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTopLinksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTopLinksResponse) ProtoMessage() {}
+
+func (x *ListTopLinksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTopLinksResponse.ProtoReflect.Descriptor instead.
+func (*ListTopLinksResponse) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ListTopLinksResponse) GetWindow() TopWindow {
+	if x != nil {
+		return x.Window
+	}
+	return TopWindow_TOP_WINDOW_UNSPECIFIED
+}
+
+func (x *ListTopLinksResponse) GetLinks() []*TopLink {
+	if x != nil {
+		return x.Links
+	}
+	return nil
+}
+
+type VerifyPasswordRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortUrl      string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyPasswordRequest) Reset() {
+	*x = VerifyPasswordRequest{}
+	mi := &file_short_url_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyPasswordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyPasswordRequest) ProtoMessage() {}
+
+func (x *VerifyPasswordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyPasswordRequest.ProtoReflect.Descriptor instead.
+func (*VerifyPasswordRequest) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *VerifyPasswordRequest) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *VerifyPasswordRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type VerifyPasswordResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 短链接无需密码时同样返回 true
+	Ok            bool `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyPasswordResponse) Reset() {
+	*x = VerifyPasswordResponse{}
+	mi := &file_short_url_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyPasswordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyPasswordResponse) ProtoMessage() {}
+
+func (x *VerifyPasswordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyPasswordResponse.ProtoReflect.Descriptor instead.
+func (*VerifyPasswordResponse) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *VerifyPasswordResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+type ConsumeClickRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortUrl      string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConsumeClickRequest) Reset() {
+	*x = ConsumeClickRequest{}
+	mi := &file_short_url_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConsumeClickRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConsumeClickRequest) ProtoMessage() {}
+
+func (x *ConsumeClickRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConsumeClickRequest.ProtoReflect.Descriptor instead.
+func (*ConsumeClickRequest) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ConsumeClickRequest) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+type ConsumeClickResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 预算已耗尽时为 false，调用方不应继续跳转
+	Ok bool `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	// 扣减后的剩余点击次数，不限次数时为 -1
+	Remaining     int64 `protobuf:"varint,2,opt,name=remaining,proto3" json:"remaining,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConsumeClickResponse) Reset() {
+	*x = ConsumeClickResponse{}
+	mi := &file_short_url_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConsumeClickResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConsumeClickResponse) ProtoMessage() {}
+
+func (x *ConsumeClickResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConsumeClickResponse.ProtoReflect.Descriptor instead.
+func (*ConsumeClickResponse) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *ConsumeClickResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *ConsumeClickResponse) GetRemaining() int64 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+// 签名密钥只保存在 rpc 层，web 层提交参与签名的字段，由 rpc 层计算并比较签名
+type VerifySignatureRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	KeyId string                 `protobuf:"bytes,1,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
+	// 参与签名的字段，按 key 排序后拼接为原文
+	Params map[string]string `protobuf:"bytes,2,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// 小写十六进制的 HMAC-SHA256 签名
+	Signature     string `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifySignatureRequest) Reset() {
+	*x = VerifySignatureRequest{}
+	mi := &file_short_url_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifySignatureRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifySignatureRequest) ProtoMessage() {}
+
+func (x *VerifySignatureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifySignatureRequest.ProtoReflect.Descriptor instead.
+func (*VerifySignatureRequest) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *VerifySignatureRequest) GetKeyId() string {
+	if x != nil {
+		return x.KeyId
+	}
+	return ""
+}
+
+func (x *VerifySignatureRequest) GetParams() map[string]string {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+func (x *VerifySignatureRequest) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+// API Key 不存在或已禁用时返回 NOT_FOUND，签名不匹配时返回 UNAUTHENTICATED
+type VerifySignatureResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifySignatureResponse) Reset() {
+	*x = VerifySignatureResponse{}
+	mi := &file_short_url_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifySignatureResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifySignatureResponse) ProtoMessage() {}
+
+func (x *VerifySignatureResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_short_url_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifySignatureResponse.ProtoReflect.Descriptor instead.
+func (*VerifySignatureResponse) Descriptor() ([]byte, []int) {
+	return file_short_url_proto_rawDescGZIP(), []int{38}
+}
+
+var File_short_url_proto protoreflect.FileDescriptor
+
+const file_short_url_proto_rawDesc = "" +
+	"\n" +
+	"\x0fshort_url.proto\x12\fshort_url.v1\"\xfd\x03\n" +
+	"\x17GenerateShortUrlRequest\x12\x1d\n" +
+	"\n" +
+	"origin_url\x18\x01 \x01(\tR\toriginUrl\x12!\n" +
+	"\fcustom_alias\x18\x02 \x01(\tR\vcustomAlias\x12\x1d\n" +
+	"\n" +
+	"expired_at\x18\x03 \x01(\x03R\texpiredAt\x12\x10\n" +
+	"\x03ttl\x18\x04 \x01(\x03R\x03ttl\x12!\n" +
+	"\fnever_expire\x18\x05 \x01(\bR\vneverExpire\x12#\n" +
+	"\rredirect_code\x18\x06 \x01(\x05R\fredirectCode\x12!\n" +
+	"\fpass_through\x18\a \x01(\bR\vpassThrough\x12#\n" +
+	"\x03utm\x18\b \x01(\v2\x11.short_url.v1.UtmR\x03utm\x12!\n" +
+	"\futm_template\x18\t \x01(\tR\vutmTemplate\x12\x1a\n" +
+	"\bpassword\x18\n" +
+	" \x01(\tR\bpassword\x12\x1d\n" +
+	"\n" +
+	"max_clicks\x18\v \x01(\x03R\tmaxClicks\x12\x1d\n" +
+	"\n" +
+	"not_before\x18\f \x01(\x03R\tnotBefore\x12/\n" +
+	"\x05rules\x18\r \x03(\v2\x19.short_url.v1.RoutingRuleR\x05rules\x121\n" +
+	"\bvariants\x18\x0e \x03(\v2\x15.short_url.v1.VariantR\bvariants\"9\n" +
+	"\aVariant\x12\x16\n" +
+	"\x06target\x18\x01 \x01(\tR\x06target\x12\x16\n" +
+	"\x06weight\x18\x02 \x01(\x05R\x06weight\"s\n" +
+	"\vRoutingRule\x122\n" +
+	"\bplatform\x18\x01 \x01(\x0e2\x16.short_url.v1.PlatformR\bplatform\x12\x16\n" +
+	"\x06target\x18\x02 \x01(\tR\x06target\x12\x18\n" +
+	"\acountry\x18\x03 \x01(\tR\acountry\"\x7f\n" +
+	"\x03Utm\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x16\n" +
+	"\x06medium\x18\x02 \x01(\tR\x06medium\x12\x1a\n" +
+	"\bcampaign\x18\x03 \x01(\tR\bcampaign\x12\x12\n" +
+	"\x04term\x18\x04 \x01(\tR\x04term\x12\x18\n" +
+	"\acontent\x18\x05 \x01(\tR\acontent\"\xb7\x03\n" +
+	"\x18GenerateShortUrlResponse\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\x12\x1d\n" +
+	"\n" +
+	"expired_at\x18\x02 \x01(\x03R\texpiredAt\x12!\n" +
+	"\fnever_expire\x18\x03 \x01(\bR\vneverExpire\x12#\n" +
+	"\rredirect_code\x18\x04 \x01(\x05R\fredirectCode\x12!\n" +
+	"\fpass_through\x18\x05 \x01(\bR\vpassThrough\x12#\n" +
+	"\x03utm\x18\x06 \x01(\v2\x11.short_url.v1.UtmR\x03utm\x12-\n" +
+	"\x12password_protected\x18\a \x01(\bR\x11passwordProtected\x12\x1d\n" +
+	"\n" +
+	"max_clicks\x18\b \x01(\x03R\tmaxClicks\x12\x1d\n" +
+	"\n" +
+	"not_before\x18\t \x01(\x03R\tnotBefore\x12/\n" +
+	"\x05rules\x18\n" +
+	" \x03(\v2\x19.short_url.v1.RoutingRuleR\x05rules\x121\n" +
+	"\bvariants\x18\v \x03(\v2\x15.short_url.v1.VariantR\bvariants\"2\n" +
+	"\x13GetOriginUrlRequest\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\"\xd4\x02\n" +
+	"\x14GetOriginUrlResponse\x12\x1d\n" +
+	"\n" +
+	"origin_url\x18\x01 \x01(\tR\toriginUrl\x12#\n" +
+	"\rredirect_code\x18\x02 \x01(\x05R\fredirectCode\x12!\n" +
+	"\fpass_through\x18\x03 \x01(\bR\vpassThrough\x12#\n" +
+	"\x03utm\x18\x04 \x01(\v2\x11.short_url.v1.UtmR\x03utm\x12-\n" +
+	"\x12password_protected\x18\x05 \x01(\bR\x11passwordProtected\x12\x1d\n" +
+	"\n" +
+	"max_clicks\x18\x06 \x01(\x03R\tmaxClicks\x12/\n" +
+	"\x05rules\x18\a \x03(\v2\x19.short_url.v1.RoutingRuleR\x05rules\x121\n" +
+	"\bvariants\x18\b \x03(\v2\x15.short_url.v1.VariantR\bvariants\"\xed\x03\n" +
+	"\fShortUrlInfo\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\x12\x1d\n" +
+	"\n" +
+	"origin_url\x18\x02 \x01(\tR\toriginUrl\x12\x1d\n" +
+	"\n" +
+	"expired_at\x18\x03 \x01(\x03R\texpiredAt\x12!\n" +
+	"\fnever_expire\x18\x04 \x01(\bR\vneverExpire\x12#\n" +
+	"\rredirect_code\x18\x05 \x01(\x05R\fredirectCode\x12!\n" +
+	"\fpass_through\x18\x06 \x01(\bR\vpassThrough\x12#\n" +
+	"\x03utm\x18\a \x01(\v2\x11.short_url.v1.UtmR\x03utm\x12!\n" +
+	"\futm_template\x18\b \x01(\tR\vutmTemplate\x12-\n" +
+	"\x12password_protected\x18\t \x01(\bR\x11passwordProtected\x12\x1d\n" +
+	"\n" +
+	"max_clicks\x18\n" +
+	" \x01(\x03R\tmaxClicks\x12\x1d\n" +
+	"\n" +
+	"not_before\x18\v \x01(\x03R\tnotBefore\x12/\n" +
+	"\x05rules\x18\f \x03(\v2\x19.short_url.v1.RoutingRuleR\x05rules\x121\n" +
+	"\bvariants\x18\r \x03(\v2\x15.short_url.v1.VariantR\bvariants\"5\n" +
+	"\x16GetShortUrlInfoRequest\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\"I\n" +
+	"\x17GetShortUrlInfoResponse\x12.\n" +
+	"\x04info\x18\x01 \x01(\v2\x1a.short_url.v1.ShortUrlInfoR\x04info\"T\n" +
+	"\x16UpdateOriginUrlRequest\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\x12\x1d\n" +
+	"\n" +
+	"origin_url\x18\x02 \x01(\tR\toriginUrl\"I\n" +
+	"\x17UpdateOriginUrlResponse\x12.\n" +
+	"\x04info\x18\x01 \x01(\v2\x1a.short_url.v1.ShortUrlInfoR\x04info\"4\n" +
+	"\x15DeleteShortUrlRequest\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\"\x18\n" +
+	"\x16DeleteShortUrlResponse\"\x8a\x01\n" +
+	"\x17ExtendExpirationRequest\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\x12\x1d\n" +
+	"\n" +
+	"expired_at\x18\x02 \x01(\x03R\texpiredAt\x12\x10\n" +
+	"\x03ttl\x18\x03 \x01(\x03R\x03ttl\x12!\n" +
+	"\fnever_expire\x18\x04 \x01(\bR\vneverExpire\"J\n" +
+	"\x18ExtendExpirationResponse\x12.\n" +
+	"\x04info\x18\x01 \x01(\v2\x1a.short_url.v1.ShortUrlInfoR\x04info\"[\n" +
+	"\x1cBatchGenerateShortUrlRequest\x12;\n" +
+	"\x05items\x18\x01 \x03(\v2%.short_url.v1.GenerateShortUrlRequestR\x05items\"\xaa\x01\n" +
+	"\x1bBatchGenerateShortUrlResult\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\x12\x1d\n" +
+	"\n" +
+	"expired_at\x18\x02 \x01(\x03R\texpiredAt\x12!\n" +
+	"\fnever_expire\x18\x03 \x01(\bR\vneverExpire\x12\x12\n" +
+	"\x04code\x18\x04 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x05 \x01(\tR\amessage\"d\n" +
+	"\x1dBatchGenerateShortUrlResponse\x12C\n" +
+	"\aresults\x18\x01 \x03(\v2).short_url.v1.BatchGenerateShortUrlResultR\aresults\"9\n" +
+	"\x18BatchGetOriginUrlRequest\x12\x1d\n" +
+	"\n" +
+	"short_urls\x18\x01 \x03(\tR\tshortUrls\"\x83\x01\n" +
+	"\x17BatchGetOriginUrlResult\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\x12\x1d\n" +
+	"\n" +
+	"origin_url\x18\x02 \x01(\tR\toriginUrl\x12\x12\n" +
+	"\x04code\x18\x03 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\"\\\n" +
+	"\x19BatchGetOriginUrlResponse\x12?\n" +
+	"\aresults\x18\x01 \x03(\v2%.short_url.v1.BatchGetOriginUrlResultR\aresults\"\xcf\x01\n" +
+	"\n" +
+	"ClickEvent\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\x12\x1c\n" +
+	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\x12\x1a\n" +
+	"\breferrer\x18\x03 \x01(\tR\breferrer\x12\x1d\n" +
+	"\n" +
+	"user_agent\x18\x04 \x01(\tR\tuserAgent\x12\x17\n" +
+	"\aip_hash\x18\x05 \x01(\tR\x06ipHash\x12\x18\n" +
+	"\acountry\x18\x06 \x01(\tR\acountry\x12\x18\n" +
+	"\avariant\x18\a \x01(\x05R\avariant\"G\n" +
+	"\x13ReportClicksRequest\x120\n" +
+	"\x06events\x18\x01 \x03(\v2\x18.short_url.v1.ClickEventR\x06events\"2\n" +
+	"\x14ReportClicksResponse\x12\x1a\n" +
+	"\baccepted\x18\x01 \x01(\x05R\baccepted\"\x9c\x01\n" +
+	"\x14GetClickStatsRequest\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\x12?\n" +
+	"\vgranularity\x18\x02 \x01(\x0e2\x1d.short_url.v1.StatGranularityR\vgranularity\x12\x14\n" +
+	"\x05start\x18\x03 \x01(\x03R\x05start\x12\x10\n" +
+	"\x03end\x18\x04 \x01(\x03R\x03end\"o\n" +
+	"\x0eClickStatPoint\x12\x1c\n" +
+	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x12\x16\n" +
+	"\x06clicks\x18\x02 \x01(\x03R\x06clicks\x12'\n" +
+	"\x0funique_visitors\x18\x03 \x01(\x03R\x0euniqueVisitors\"\x8a\x03\n" +
+	"\x15GetClickStatsResponse\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\x12?\n" +
+	"\vgranularity\x18\x02 \x01(\x0e2\x1d.short_url.v1.StatGranularityR\vgranularity\x12\x14\n" +
+	"\x05total\x18\x03 \x01(\x03R\x05total\x12\x1f\n" +
+	"\vrange_total\x18\x04 \x01(\x03R\n" +
+	"rangeTotal\x124\n" +
+	"\x06points\x18\x05 \x03(\v2\x1c.short_url.v1.ClickStatPointR\x06points\x122\n" +
+	"\x15range_unique_visitors\x18\x06 \x01(\x03R\x13rangeUniqueVisitors\x129\n" +
+	"\tcountries\x18\a \x03(\v2\x1b.short_url.v1.CountryClicksR\tcountries\x127\n" +
+	"\bvariants\x18\b \x03(\v2\x1b.short_url.v1.VariantClicksR\bvariants\"q\n" +
+	"\rVariantClicks\x12\x18\n" +
+	"\avariant\x18\x01 \x01(\x05R\avariant\x12\x16\n" +
+	"\x06target\x18\x02 \x01(\tR\x06target\x12\x16\n" +
+	"\x06weight\x18\x03 \x01(\x05R\x06weight\x12\x16\n" +
+	"\x06clicks\x18\x04 \x01(\x03R\x06clicks\"A\n" +
+	"\rCountryClicks\x12\x18\n" +
+	"\acountry\x18\x01 \x01(\tR\acountry\x12\x16\n" +
+	"\x06clicks\x18\x02 \x01(\x03R\x06clicks\"\\\n" +
+	"\x13ListTopLinksRequest\x12/\n" +
+	"\x06window\x18\x01 \x01(\x0e2\x17.short_url.v1.TopWindowR\x06window\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\">\n" +
+	"\aTopLink\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\x12\x16\n" +
+	"\x06clicks\x18\x02 \x01(\x03R\x06clicks\"t\n" +
+	"\x14ListTopLinksResponse\x12/\n" +
+	"\x06window\x18\x01 \x01(\x0e2\x17.short_url.v1.TopWindowR\x06window\x12+\n" +
+	"\x05links\x18\x02 \x03(\v2\x15.short_url.v1.TopLinkR\x05links\"P\n" +
+	"\x15VerifyPasswordRequest\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"(\n" +
+	"\x16VerifyPasswordResponse\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\"2\n" +
+	"\x13ConsumeClickRequest\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\"D\n" +
+	"\x14ConsumeClickResponse\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\x12\x1c\n" +
+	"\tremaining\x18\x02 \x01(\x03R\tremaining\"\xd2\x01\n" +
+	"\x16VerifySignatureRequest\x12\x15\n" +
+	"\x06key_id\x18\x01 \x01(\tR\x05keyId\x12H\n" +
+	"\x06params\x18\x02 \x03(\v20.short_url.v1.VerifySignatureRequest.ParamsEntryR\x06params\x12\x1c\n" +
+	"\tsignature\x18\x03 \x01(\tR\tsignature\x1a9\n" +
+	"\vParamsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x19\n" +
+	"\x17VerifySignatureResponse*\x8a\x01\n" +
+	"\bPlatform\x12\x18\n" +
+	"\x14PLATFORM_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fPLATFORM_IOS\x10\x01\x12\x14\n" +
+	"\x10PLATFORM_ANDROID\x10\x02\x12\x14\n" +
+	"\x10PLATFORM_WINDOWS\x10\x03\x12\x12\n" +
+	"\x0ePLATFORM_MACOS\x10\x04\x12\x12\n" +
+	"\x0ePLATFORM_LINUX\x10\x05*h\n" +
+	"\x0fStatGranularity\x12 \n" +
+	"\x1cSTAT_GRANULARITY_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15STAT_GRANULARITY_HOUR\x10\x01\x12\x18\n" +
+	"\x14STAT_GRANULARITY_DAY\x10\x02*`\n" +
+	"\tTopWindow\x12\x1a\n" +
+	"\x16TOP_WINDOW_UNSPECIFIED\x10\x00\x12\x11\n" +
+	"\rTOP_WINDOW_5M\x10\x01\x12\x11\n" +
+	"\rTOP_WINDOW_1H\x10\x02\x12\x11\n" +
+	"\rTOP_WINDOW_1D\x10\x032\xbf\n" +
 	"\n" +
-	"origin_url\x18\x01 \x01(\tR\toriginUrl2\xcb\x01\n" +
 	"\x0fShortUrlService\x12a\n" +
 	"\x10GenerateShortUrl\x12%.short_url.v1.GenerateShortUrlRequest\x1a&.short_url.v1.GenerateShortUrlResponse\x12U\n" +
-	"\fGetOriginUrl\x12!.short_url.v1.GetOriginUrlRequest\x1a\".short_url.v1.GetOriginUrlResponseB\x1bZ\x19short_url/v1;short_url_v1b\x06proto3"
+	"\fGetOriginUrl\x12!.short_url.v1.GetOriginUrlRequest\x1a\".short_url.v1.GetOriginUrlResponse\x12^\n" +
+	"\x0fGetShortUrlInfo\x12$.short_url.v1.GetShortUrlInfoRequest\x1a%.short_url.v1.GetShortUrlInfoResponse\x12^\n" +
+	"\x0fUpdateOriginUrl\x12$.short_url.v1.UpdateOriginUrlRequest\x1a%.short_url.v1.UpdateOriginUrlResponse\x12[\n" +
+	"\x0eDeleteShortUrl\x12#.short_url.v1.DeleteShortUrlRequest\x1a$.short_url.v1.DeleteShortUrlResponse\x12a\n" +
+	"\x10ExtendExpiration\x12%.short_url.v1.ExtendExpirationRequest\x1a&.short_url.v1.ExtendExpirationResponse\x12p\n" +
+	"\x15BatchGenerateShortUrl\x12*.short_url.v1.BatchGenerateShortUrlRequest\x1a+.short_url.v1.BatchGenerateShortUrlResponse\x12d\n" +
+	"\x11BatchGetOriginUrl\x12&.short_url.v1.BatchGetOriginUrlRequest\x1a'.short_url.v1.BatchGetOriginUrlResponse\x12U\n" +
+	"\fReportClicks\x12!.short_url.v1.ReportClicksRequest\x1a\".short_url.v1.ReportClicksResponse\x12X\n" +
+	"\rGetClickStats\x12\".short_url.v1.GetClickStatsRequest\x1a#.short_url.v1.GetClickStatsResponse\x12U\n" +
+	"\fListTopLinks\x12!.short_url.v1.ListTopLinksRequest\x1a\".short_url.v1.ListTopLinksResponse\x12[\n" +
+	"\x0eVerifyPassword\x12#.short_url.v1.VerifyPasswordRequest\x1a$.short_url.v1.VerifyPasswordResponse\x12U\n" +
+	"\fConsumeClick\x12!.short_url.v1.ConsumeClickRequest\x1a\".short_url.v1.ConsumeClickResponse\x12^\n" +
+	"\x0fVerifySignature\x12$.short_url.v1.VerifySignatureRequest\x1a%.short_url.v1.VerifySignatureResponseB\x1bZ\x19short_url/v1;short_url_v1b\x06proto3"
 
 var (
 	file_short_url_proto_rawDescOnce sync.Once
@@ -228,23 +2894,116 @@ func file_short_url_proto_rawDescGZIP() []byte {
 	return file_short_url_proto_rawDescData
 }
 
-var file_short_url_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_short_url_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_short_url_proto_msgTypes = make([]protoimpl.MessageInfo, 40)
 var file_short_url_proto_goTypes = []any{
-	(*GenerateShortUrlRequest)(nil),  // 0: short_url.v1.GenerateShortUrlRequest
-	(*GenerateShortUrlResponse)(nil), // 1: short_url.v1.GenerateShortUrlResponse
-	(*GetOriginUrlRequest)(nil),      // 2: short_url.v1.GetOriginUrlRequest
-	(*GetOriginUrlResponse)(nil),     // 3: short_url.v1.GetOriginUrlResponse
+	(Platform)(0),                         // 0: short_url.v1.Platform
+	(StatGranularity)(0),                  // 1: short_url.v1.StatGranularity
+	(TopWindow)(0),                        // 2: short_url.v1.TopWindow
+	(*GenerateShortUrlRequest)(nil),       // 3: short_url.v1.GenerateShortUrlRequest
+	(*Variant)(nil),                       // 4: short_url.v1.Variant
+	(*RoutingRule)(nil),                   // 5: short_url.v1.RoutingRule
+	(*Utm)(nil),                           // 6: short_url.v1.Utm
+	(*GenerateShortUrlResponse)(nil),      // 7: short_url.v1.GenerateShortUrlResponse
+	(*GetOriginUrlRequest)(nil),           // 8: short_url.v1.GetOriginUrlRequest
+	(*GetOriginUrlResponse)(nil),          // 9: short_url.v1.GetOriginUrlResponse
+	(*ShortUrlInfo)(nil),                  // 10: short_url.v1.ShortUrlInfo
+	(*GetShortUrlInfoRequest)(nil),        // 11: short_url.v1.GetShortUrlInfoRequest
+	(*GetShortUrlInfoResponse)(nil),       // 12: short_url.v1.GetShortUrlInfoResponse
+	(*UpdateOriginUrlRequest)(nil),        // 13: short_url.v1.UpdateOriginUrlRequest
+	(*UpdateOriginUrlResponse)(nil),       // 14: short_url.v1.UpdateOriginUrlResponse
+	(*DeleteShortUrlRequest)(nil),         // 15: short_url.v1.DeleteShortUrlRequest
+	(*DeleteShortUrlResponse)(nil),        // 16: short_url.v1.DeleteShortUrlResponse
+	(*ExtendExpirationRequest)(nil),       // 17: short_url.v1.ExtendExpirationRequest
+	(*ExtendExpirationResponse)(nil),      // 18: short_url.v1.ExtendExpirationResponse
+	(*BatchGenerateShortUrlRequest)(nil),  // 19: short_url.v1.BatchGenerateShortUrlRequest
+	(*BatchGenerateShortUrlResult)(nil),   // 20: short_url.v1.BatchGenerateShortUrlResult
+	(*BatchGenerateShortUrlResponse)(nil), // 21: short_url.v1.BatchGenerateShortUrlResponse
+	(*BatchGetOriginUrlRequest)(nil),      // 22: short_url.v1.BatchGetOriginUrlRequest
+	(*BatchGetOriginUrlResult)(nil),       // 23: short_url.v1.BatchGetOriginUrlResult
+	(*BatchGetOriginUrlResponse)(nil),     // 24: short_url.v1.BatchGetOriginUrlResponse
+	(*ClickEvent)(nil),                    // 25: short_url.v1.ClickEvent
+	(*ReportClicksRequest)(nil),           // 26: short_url.v1.ReportClicksRequest
+	(*ReportClicksResponse)(nil),          // 27: short_url.v1.ReportClicksResponse
+	(*GetClickStatsRequest)(nil),          // 28: short_url.v1.GetClickStatsRequest
+	(*ClickStatPoint)(nil),                // 29: short_url.v1.ClickStatPoint
+	(*GetClickStatsResponse)(nil),         // 30: short_url.v1.GetClickStatsResponse
+	(*VariantClicks)(nil),                 // 31: short_url.v1.VariantClicks
+	(*CountryClicks)(nil),                 // 32: short_url.v1.CountryClicks
+	(*ListTopLinksRequest)(nil),           // 33: short_url.v1.ListTopLinksRequest
+	(*TopLink)(nil),                       // 34: short_url.v1.TopLink
+	(*ListTopLinksResponse)(nil),          // 35: short_url.v1.ListTopLinksResponse
+	(*VerifyPasswordRequest)(nil),         // 36: short_url.v1.VerifyPasswordRequest
+	(*VerifyPasswordResponse)(nil),        // 37: short_url.v1.VerifyPasswordResponse
+	(*ConsumeClickRequest)(nil),           // 38: short_url.v1.ConsumeClickRequest
+	(*ConsumeClickResponse)(nil),          // 39: short_url.v1.ConsumeClickResponse
+	(*VerifySignatureRequest)(nil),        // 40: short_url.v1.VerifySignatureRequest
+	(*VerifySignatureResponse)(nil),       // 41: short_url.v1.VerifySignatureResponse
+	nil,                                   // 42: short_url.v1.VerifySignatureRequest.ParamsEntry
 }
 var file_short_url_proto_depIdxs = []int32{
-	0, // 0: short_url.v1.ShortUrlService.GenerateShortUrl:input_type -> short_url.v1.GenerateShortUrlRequest
-	2, // 1: short_url.v1.ShortUrlService.GetOriginUrl:input_type -> short_url.v1.GetOriginUrlRequest
-	1, // 2: short_url.v1.ShortUrlService.GenerateShortUrl:output_type -> short_url.v1.GenerateShortUrlResponse
-	3, // 3: short_url.v1.ShortUrlService.GetOriginUrl:output_type -> short_url.v1.GetOriginUrlResponse
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	6,  // 0: short_url.v1.GenerateShortUrlRequest.utm:type_name -> short_url.v1.Utm
+	5,  // 1: short_url.v1.GenerateShortUrlRequest.rules:type_name -> short_url.v1.RoutingRule
+	4,  // 2: short_url.v1.GenerateShortUrlRequest.variants:type_name -> short_url.v1.Variant
+	0,  // 3: short_url.v1.RoutingRule.platform:type_name -> short_url.v1.Platform
+	6,  // 4: short_url.v1.GenerateShortUrlResponse.utm:type_name -> short_url.v1.Utm
+	5,  // 5: short_url.v1.GenerateShortUrlResponse.rules:type_name -> short_url.v1.RoutingRule
+	4,  // 6: short_url.v1.GenerateShortUrlResponse.variants:type_name -> short_url.v1.Variant
+	6,  // 7: short_url.v1.GetOriginUrlResponse.utm:type_name -> short_url.v1.Utm
+	5,  // 8: short_url.v1.GetOriginUrlResponse.rules:type_name -> short_url.v1.RoutingRule
+	4,  // 9: short_url.v1.GetOriginUrlResponse.variants:type_name -> short_url.v1.Variant
+	6,  // 10: short_url.v1.ShortUrlInfo.utm:type_name -> short_url.v1.Utm
+	5,  // 11: short_url.v1.ShortUrlInfo.rules:type_name -> short_url.v1.RoutingRule
+	4,  // 12: short_url.v1.ShortUrlInfo.variants:type_name -> short_url.v1.Variant
+	10, // 13: short_url.v1.GetShortUrlInfoResponse.info:type_name -> short_url.v1.ShortUrlInfo
+	10, // 14: short_url.v1.UpdateOriginUrlResponse.info:type_name -> short_url.v1.ShortUrlInfo
+	10, // 15: short_url.v1.ExtendExpirationResponse.info:type_name -> short_url.v1.ShortUrlInfo
+	3,  // 16: short_url.v1.BatchGenerateShortUrlRequest.items:type_name -> short_url.v1.GenerateShortUrlRequest
+	20, // 17: short_url.v1.BatchGenerateShortUrlResponse.results:type_name -> short_url.v1.BatchGenerateShortUrlResult
+	23, // 18: short_url.v1.BatchGetOriginUrlResponse.results:type_name -> short_url.v1.BatchGetOriginUrlResult
+	25, // 19: short_url.v1.ReportClicksRequest.events:type_name -> short_url.v1.ClickEvent
+	1,  // 20: short_url.v1.GetClickStatsRequest.granularity:type_name -> short_url.v1.StatGranularity
+	1,  // 21: short_url.v1.GetClickStatsResponse.granularity:type_name -> short_url.v1.StatGranularity
+	29, // 22: short_url.v1.GetClickStatsResponse.points:type_name -> short_url.v1.ClickStatPoint
+	32, // 23: short_url.v1.GetClickStatsResponse.countries:type_name -> short_url.v1.CountryClicks
+	31, // 24: short_url.v1.GetClickStatsResponse.variants:type_name -> short_url.v1.VariantClicks
+	2,  // 25: short_url.v1.ListTopLinksRequest.window:type_name -> short_url.v1.TopWindow
+	2,  // 26: short_url.v1.ListTopLinksResponse.window:type_name -> short_url.v1.TopWindow
+	34, // 27: short_url.v1.ListTopLinksResponse.links:type_name -> short_url.v1.TopLink
+	42, // 28: short_url.v1.VerifySignatureRequest.params:type_name -> short_url.v1.VerifySignatureRequest.ParamsEntry
+	3,  // 29: short_url.v1.ShortUrlService.GenerateShortUrl:input_type -> short_url.v1.GenerateShortUrlRequest
+	8,  // 30: short_url.v1.ShortUrlService.GetOriginUrl:input_type -> short_url.v1.GetOriginUrlRequest
+	11, // 31: short_url.v1.ShortUrlService.GetShortUrlInfo:input_type -> short_url.v1.GetShortUrlInfoRequest
+	13, // 32: short_url.v1.ShortUrlService.UpdateOriginUrl:input_type -> short_url.v1.UpdateOriginUrlRequest
+	15, // 33: short_url.v1.ShortUrlService.DeleteShortUrl:input_type -> short_url.v1.DeleteShortUrlRequest
+	17, // 34: short_url.v1.ShortUrlService.ExtendExpiration:input_type -> short_url.v1.ExtendExpirationRequest
+	19, // 35: short_url.v1.ShortUrlService.BatchGenerateShortUrl:input_type -> short_url.v1.BatchGenerateShortUrlRequest
+	22, // 36: short_url.v1.ShortUrlService.BatchGetOriginUrl:input_type -> short_url.v1.BatchGetOriginUrlRequest
+	26, // 37: short_url.v1.ShortUrlService.ReportClicks:input_type -> short_url.v1.ReportClicksRequest
+	28, // 38: short_url.v1.ShortUrlService.GetClickStats:input_type -> short_url.v1.GetClickStatsRequest
+	33, // 39: short_url.v1.ShortUrlService.ListTopLinks:input_type -> short_url.v1.ListTopLinksRequest
+	36, // 40: short_url.v1.ShortUrlService.VerifyPassword:input_type -> short_url.v1.VerifyPasswordRequest
+	38, // 41: short_url.v1.ShortUrlService.ConsumeClick:input_type -> short_url.v1.ConsumeClickRequest
+	40, // 42: short_url.v1.ShortUrlService.VerifySignature:input_type -> short_url.v1.VerifySignatureRequest
+	7,  // 43: short_url.v1.ShortUrlService.GenerateShortUrl:output_type -> short_url.v1.GenerateShortUrlResponse
+	9,  // 44: short_url.v1.ShortUrlService.GetOriginUrl:output_type -> short_url.v1.GetOriginUrlResponse
+	12, // 45: short_url.v1.ShortUrlService.GetShortUrlInfo:output_type -> short_url.v1.GetShortUrlInfoResponse
+	14, // 46: short_url.v1.ShortUrlService.UpdateOriginUrl:output_type -> short_url.v1.UpdateOriginUrlResponse
+	16, // 47: short_url.v1.ShortUrlService.DeleteShortUrl:output_type -> short_url.v1.DeleteShortUrlResponse
+	18, // 48: short_url.v1.ShortUrlService.ExtendExpiration:output_type -> short_url.v1.ExtendExpirationResponse
+	21, // 49: short_url.v1.ShortUrlService.BatchGenerateShortUrl:output_type -> short_url.v1.BatchGenerateShortUrlResponse
+	24, // 50: short_url.v1.ShortUrlService.BatchGetOriginUrl:output_type -> short_url.v1.BatchGetOriginUrlResponse
+	27, // 51: short_url.v1.ShortUrlService.ReportClicks:output_type -> short_url.v1.ReportClicksResponse
+	30, // 52: short_url.v1.ShortUrlService.GetClickStats:output_type -> short_url.v1.GetClickStatsResponse
+	35, // 53: short_url.v1.ShortUrlService.ListTopLinks:output_type -> short_url.v1.ListTopLinksResponse
+	37, // 54: short_url.v1.ShortUrlService.VerifyPassword:output_type -> short_url.v1.VerifyPasswordResponse
+	39, // 55: short_url.v1.ShortUrlService.ConsumeClick:output_type -> short_url.v1.ConsumeClickResponse
+	41, // 56: short_url.v1.ShortUrlService.VerifySignature:output_type -> short_url.v1.VerifySignatureResponse
+	43, // [43:57] is the sub-list for method output_type
+	29, // [29:43] is the sub-list for method input_type
+	29, // [29:29] is the sub-list for extension type_name
+	29, // [29:29] is the sub-list for extension extendee
+	0,  // [0:29] is the sub-list for field type_name
 }
 
 func init() { file_short_url_proto_init() }
@@ -257,13 +3016,14 @@ func file_short_url_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_short_url_proto_rawDesc), len(file_short_url_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   4,
+			NumEnums:      3,
+			NumMessages:   40,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_short_url_proto_goTypes,
 		DependencyIndexes: file_short_url_proto_depIdxs,
+		EnumInfos:         file_short_url_proto_enumTypes,
 		MessageInfos:      file_short_url_proto_msgTypes,
 	}.Build()
 	File_short_url_proto = out.File
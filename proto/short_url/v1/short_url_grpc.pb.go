@@ -19,8 +19,20 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	ShortUrlService_GenerateShortUrl_FullMethodName = "/short_url.v1.ShortUrlService/GenerateShortUrl"
-	ShortUrlService_GetOriginUrl_FullMethodName     = "/short_url.v1.ShortUrlService/GetOriginUrl"
+	ShortUrlService_GenerateShortUrl_FullMethodName      = "/short_url.v1.ShortUrlService/GenerateShortUrl"
+	ShortUrlService_GetOriginUrl_FullMethodName          = "/short_url.v1.ShortUrlService/GetOriginUrl"
+	ShortUrlService_GetShortUrlInfo_FullMethodName       = "/short_url.v1.ShortUrlService/GetShortUrlInfo"
+	ShortUrlService_UpdateOriginUrl_FullMethodName       = "/short_url.v1.ShortUrlService/UpdateOriginUrl"
+	ShortUrlService_DeleteShortUrl_FullMethodName        = "/short_url.v1.ShortUrlService/DeleteShortUrl"
+	ShortUrlService_ExtendExpiration_FullMethodName      = "/short_url.v1.ShortUrlService/ExtendExpiration"
+	ShortUrlService_BatchGenerateShortUrl_FullMethodName = "/short_url.v1.ShortUrlService/BatchGenerateShortUrl"
+	ShortUrlService_BatchGetOriginUrl_FullMethodName     = "/short_url.v1.ShortUrlService/BatchGetOriginUrl"
+	ShortUrlService_ReportClicks_FullMethodName          = "/short_url.v1.ShortUrlService/ReportClicks"
+	ShortUrlService_GetClickStats_FullMethodName         = "/short_url.v1.ShortUrlService/GetClickStats"
+	ShortUrlService_ListTopLinks_FullMethodName          = "/short_url.v1.ShortUrlService/ListTopLinks"
+	ShortUrlService_VerifyPassword_FullMethodName        = "/short_url.v1.ShortUrlService/VerifyPassword"
+	ShortUrlService_ConsumeClick_FullMethodName          = "/short_url.v1.ShortUrlService/ConsumeClick"
+	ShortUrlService_VerifySignature_FullMethodName       = "/short_url.v1.ShortUrlService/VerifySignature"
 )
 
 // ShortUrlServiceClient is the client API for ShortUrlService service.
@@ -28,7 +40,20 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ShortUrlServiceClient interface {
 	GenerateShortUrl(ctx context.Context, in *GenerateShortUrlRequest, opts ...grpc.CallOption) (*GenerateShortUrlResponse, error)
+	// 尚未到生效时间的短链接返回 FAILED_PRECONDITION
 	GetOriginUrl(ctx context.Context, in *GetOriginUrlRequest, opts ...grpc.CallOption) (*GetOriginUrlResponse, error)
+	GetShortUrlInfo(ctx context.Context, in *GetShortUrlInfoRequest, opts ...grpc.CallOption) (*GetShortUrlInfoResponse, error)
+	UpdateOriginUrl(ctx context.Context, in *UpdateOriginUrlRequest, opts ...grpc.CallOption) (*UpdateOriginUrlResponse, error)
+	DeleteShortUrl(ctx context.Context, in *DeleteShortUrlRequest, opts ...grpc.CallOption) (*DeleteShortUrlResponse, error)
+	ExtendExpiration(ctx context.Context, in *ExtendExpirationRequest, opts ...grpc.CallOption) (*ExtendExpirationResponse, error)
+	BatchGenerateShortUrl(ctx context.Context, in *BatchGenerateShortUrlRequest, opts ...grpc.CallOption) (*BatchGenerateShortUrlResponse, error)
+	BatchGetOriginUrl(ctx context.Context, in *BatchGetOriginUrlRequest, opts ...grpc.CallOption) (*BatchGetOriginUrlResponse, error)
+	ReportClicks(ctx context.Context, in *ReportClicksRequest, opts ...grpc.CallOption) (*ReportClicksResponse, error)
+	GetClickStats(ctx context.Context, in *GetClickStatsRequest, opts ...grpc.CallOption) (*GetClickStatsResponse, error)
+	ListTopLinks(ctx context.Context, in *ListTopLinksRequest, opts ...grpc.CallOption) (*ListTopLinksResponse, error)
+	VerifyPassword(ctx context.Context, in *VerifyPasswordRequest, opts ...grpc.CallOption) (*VerifyPasswordResponse, error)
+	ConsumeClick(ctx context.Context, in *ConsumeClickRequest, opts ...grpc.CallOption) (*ConsumeClickResponse, error)
+	VerifySignature(ctx context.Context, in *VerifySignatureRequest, opts ...grpc.CallOption) (*VerifySignatureResponse, error)
 }
 
 type shortUrlServiceClient struct {
@@ -59,12 +84,145 @@ func (c *shortUrlServiceClient) GetOriginUrl(ctx context.Context, in *GetOriginU
 	return out, nil
 }
 
+func (c *shortUrlServiceClient) GetShortUrlInfo(ctx context.Context, in *GetShortUrlInfoRequest, opts ...grpc.CallOption) (*GetShortUrlInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetShortUrlInfoResponse)
+	err := c.cc.Invoke(ctx, ShortUrlService_GetShortUrlInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortUrlServiceClient) UpdateOriginUrl(ctx context.Context, in *UpdateOriginUrlRequest, opts ...grpc.CallOption) (*UpdateOriginUrlResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateOriginUrlResponse)
+	err := c.cc.Invoke(ctx, ShortUrlService_UpdateOriginUrl_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortUrlServiceClient) DeleteShortUrl(ctx context.Context, in *DeleteShortUrlRequest, opts ...grpc.CallOption) (*DeleteShortUrlResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteShortUrlResponse)
+	err := c.cc.Invoke(ctx, ShortUrlService_DeleteShortUrl_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortUrlServiceClient) ExtendExpiration(ctx context.Context, in *ExtendExpirationRequest, opts ...grpc.CallOption) (*ExtendExpirationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExtendExpirationResponse)
+	err := c.cc.Invoke(ctx, ShortUrlService_ExtendExpiration_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortUrlServiceClient) BatchGenerateShortUrl(ctx context.Context, in *BatchGenerateShortUrlRequest, opts ...grpc.CallOption) (*BatchGenerateShortUrlResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchGenerateShortUrlResponse)
+	err := c.cc.Invoke(ctx, ShortUrlService_BatchGenerateShortUrl_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortUrlServiceClient) BatchGetOriginUrl(ctx context.Context, in *BatchGetOriginUrlRequest, opts ...grpc.CallOption) (*BatchGetOriginUrlResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchGetOriginUrlResponse)
+	err := c.cc.Invoke(ctx, ShortUrlService_BatchGetOriginUrl_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortUrlServiceClient) ReportClicks(ctx context.Context, in *ReportClicksRequest, opts ...grpc.CallOption) (*ReportClicksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReportClicksResponse)
+	err := c.cc.Invoke(ctx, ShortUrlService_ReportClicks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortUrlServiceClient) GetClickStats(ctx context.Context, in *GetClickStatsRequest, opts ...grpc.CallOption) (*GetClickStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetClickStatsResponse)
+	err := c.cc.Invoke(ctx, ShortUrlService_GetClickStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortUrlServiceClient) ListTopLinks(ctx context.Context, in *ListTopLinksRequest, opts ...grpc.CallOption) (*ListTopLinksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTopLinksResponse)
+	err := c.cc.Invoke(ctx, ShortUrlService_ListTopLinks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortUrlServiceClient) VerifyPassword(ctx context.Context, in *VerifyPasswordRequest, opts ...grpc.CallOption) (*VerifyPasswordResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VerifyPasswordResponse)
+	err := c.cc.Invoke(ctx, ShortUrlService_VerifyPassword_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortUrlServiceClient) ConsumeClick(ctx context.Context, in *ConsumeClickRequest, opts ...grpc.CallOption) (*ConsumeClickResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConsumeClickResponse)
+	err := c.cc.Invoke(ctx, ShortUrlService_ConsumeClick_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortUrlServiceClient) VerifySignature(ctx context.Context, in *VerifySignatureRequest, opts ...grpc.CallOption) (*VerifySignatureResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VerifySignatureResponse)
+	err := c.cc.Invoke(ctx, ShortUrlService_VerifySignature_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ShortUrlServiceServer is the server API for ShortUrlService service.
 // All implementations must embed UnimplementedShortUrlServiceServer
 // for forward compatibility.
 type ShortUrlServiceServer interface {
 	GenerateShortUrl(context.Context, *GenerateShortUrlRequest) (*GenerateShortUrlResponse, error)
+	// 尚未到生效时间的短链接返回 FAILED_PRECONDITION
 	GetOriginUrl(context.Context, *GetOriginUrlRequest) (*GetOriginUrlResponse, error)
+	GetShortUrlInfo(context.Context, *GetShortUrlInfoRequest) (*GetShortUrlInfoResponse, error)
+	UpdateOriginUrl(context.Context, *UpdateOriginUrlRequest) (*UpdateOriginUrlResponse, error)
+	DeleteShortUrl(context.Context, *DeleteShortUrlRequest) (*DeleteShortUrlResponse, error)
+	ExtendExpiration(context.Context, *ExtendExpirationRequest) (*ExtendExpirationResponse, error)
+	BatchGenerateShortUrl(context.Context, *BatchGenerateShortUrlRequest) (*BatchGenerateShortUrlResponse, error)
+	BatchGetOriginUrl(context.Context, *BatchGetOriginUrlRequest) (*BatchGetOriginUrlResponse, error)
+	ReportClicks(context.Context, *ReportClicksRequest) (*ReportClicksResponse, error)
+	GetClickStats(context.Context, *GetClickStatsRequest) (*GetClickStatsResponse, error)
+	ListTopLinks(context.Context, *ListTopLinksRequest) (*ListTopLinksResponse, error)
+	VerifyPassword(context.Context, *VerifyPasswordRequest) (*VerifyPasswordResponse, error)
+	ConsumeClick(context.Context, *ConsumeClickRequest) (*ConsumeClickResponse, error)
+	VerifySignature(context.Context, *VerifySignatureRequest) (*VerifySignatureResponse, error)
 	mustEmbedUnimplementedShortUrlServiceServer()
 }
 
@@ -81,6 +239,42 @@ func (UnimplementedShortUrlServiceServer) GenerateShortUrl(context.Context, *Gen
 func (UnimplementedShortUrlServiceServer) GetOriginUrl(context.Context, *GetOriginUrlRequest) (*GetOriginUrlResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetOriginUrl not implemented")
 }
+func (UnimplementedShortUrlServiceServer) GetShortUrlInfo(context.Context, *GetShortUrlInfoRequest) (*GetShortUrlInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetShortUrlInfo not implemented")
+}
+func (UnimplementedShortUrlServiceServer) UpdateOriginUrl(context.Context, *UpdateOriginUrlRequest) (*UpdateOriginUrlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateOriginUrl not implemented")
+}
+func (UnimplementedShortUrlServiceServer) DeleteShortUrl(context.Context, *DeleteShortUrlRequest) (*DeleteShortUrlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteShortUrl not implemented")
+}
+func (UnimplementedShortUrlServiceServer) ExtendExpiration(context.Context, *ExtendExpirationRequest) (*ExtendExpirationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExtendExpiration not implemented")
+}
+func (UnimplementedShortUrlServiceServer) BatchGenerateShortUrl(context.Context, *BatchGenerateShortUrlRequest) (*BatchGenerateShortUrlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGenerateShortUrl not implemented")
+}
+func (UnimplementedShortUrlServiceServer) BatchGetOriginUrl(context.Context, *BatchGetOriginUrlRequest) (*BatchGetOriginUrlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetOriginUrl not implemented")
+}
+func (UnimplementedShortUrlServiceServer) ReportClicks(context.Context, *ReportClicksRequest) (*ReportClicksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportClicks not implemented")
+}
+func (UnimplementedShortUrlServiceServer) GetClickStats(context.Context, *GetClickStatsRequest) (*GetClickStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetClickStats not implemented")
+}
+func (UnimplementedShortUrlServiceServer) ListTopLinks(context.Context, *ListTopLinksRequest) (*ListTopLinksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTopLinks not implemented")
+}
+func (UnimplementedShortUrlServiceServer) VerifyPassword(context.Context, *VerifyPasswordRequest) (*VerifyPasswordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyPassword not implemented")
+}
+func (UnimplementedShortUrlServiceServer) ConsumeClick(context.Context, *ConsumeClickRequest) (*ConsumeClickResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConsumeClick not implemented")
+}
+func (UnimplementedShortUrlServiceServer) VerifySignature(context.Context, *VerifySignatureRequest) (*VerifySignatureResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifySignature not implemented")
+}
 func (UnimplementedShortUrlServiceServer) mustEmbedUnimplementedShortUrlServiceServer() {}
 func (UnimplementedShortUrlServiceServer) testEmbeddedByValue()                         {}
 
@@ -138,6 +332,222 @@ func _ShortUrlService_GetOriginUrl_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ShortUrlService_GetShortUrlInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetShortUrlInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortUrlServiceServer).GetShortUrlInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortUrlService_GetShortUrlInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortUrlServiceServer).GetShortUrlInfo(ctx, req.(*GetShortUrlInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShortUrlService_UpdateOriginUrl_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateOriginUrlRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortUrlServiceServer).UpdateOriginUrl(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortUrlService_UpdateOriginUrl_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortUrlServiceServer).UpdateOriginUrl(ctx, req.(*UpdateOriginUrlRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShortUrlService_DeleteShortUrl_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteShortUrlRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortUrlServiceServer).DeleteShortUrl(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortUrlService_DeleteShortUrl_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortUrlServiceServer).DeleteShortUrl(ctx, req.(*DeleteShortUrlRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShortUrlService_ExtendExpiration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExtendExpirationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortUrlServiceServer).ExtendExpiration(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortUrlService_ExtendExpiration_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortUrlServiceServer).ExtendExpiration(ctx, req.(*ExtendExpirationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShortUrlService_BatchGenerateShortUrl_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGenerateShortUrlRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortUrlServiceServer).BatchGenerateShortUrl(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortUrlService_BatchGenerateShortUrl_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortUrlServiceServer).BatchGenerateShortUrl(ctx, req.(*BatchGenerateShortUrlRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShortUrlService_BatchGetOriginUrl_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetOriginUrlRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortUrlServiceServer).BatchGetOriginUrl(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortUrlService_BatchGetOriginUrl_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortUrlServiceServer).BatchGetOriginUrl(ctx, req.(*BatchGetOriginUrlRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShortUrlService_ReportClicks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportClicksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortUrlServiceServer).ReportClicks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortUrlService_ReportClicks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortUrlServiceServer).ReportClicks(ctx, req.(*ReportClicksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShortUrlService_GetClickStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetClickStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortUrlServiceServer).GetClickStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortUrlService_GetClickStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortUrlServiceServer).GetClickStats(ctx, req.(*GetClickStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShortUrlService_ListTopLinks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTopLinksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortUrlServiceServer).ListTopLinks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortUrlService_ListTopLinks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortUrlServiceServer).ListTopLinks(ctx, req.(*ListTopLinksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShortUrlService_VerifyPassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyPasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortUrlServiceServer).VerifyPassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortUrlService_VerifyPassword_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortUrlServiceServer).VerifyPassword(ctx, req.(*VerifyPasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShortUrlService_ConsumeClick_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConsumeClickRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortUrlServiceServer).ConsumeClick(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortUrlService_ConsumeClick_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortUrlServiceServer).ConsumeClick(ctx, req.(*ConsumeClickRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShortUrlService_VerifySignature_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifySignatureRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortUrlServiceServer).VerifySignature(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortUrlService_VerifySignature_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortUrlServiceServer).VerifySignature(ctx, req.(*VerifySignatureRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ShortUrlService_ServiceDesc is the grpc.ServiceDesc for ShortUrlService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -153,6 +563,54 @@ var ShortUrlService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetOriginUrl",
 			Handler:    _ShortUrlService_GetOriginUrl_Handler,
 		},
+		{
+			MethodName: "GetShortUrlInfo",
+			Handler:    _ShortUrlService_GetShortUrlInfo_Handler,
+		},
+		{
+			MethodName: "UpdateOriginUrl",
+			Handler:    _ShortUrlService_UpdateOriginUrl_Handler,
+		},
+		{
+			MethodName: "DeleteShortUrl",
+			Handler:    _ShortUrlService_DeleteShortUrl_Handler,
+		},
+		{
+			MethodName: "ExtendExpiration",
+			Handler:    _ShortUrlService_ExtendExpiration_Handler,
+		},
+		{
+			MethodName: "BatchGenerateShortUrl",
+			Handler:    _ShortUrlService_BatchGenerateShortUrl_Handler,
+		},
+		{
+			MethodName: "BatchGetOriginUrl",
+			Handler:    _ShortUrlService_BatchGetOriginUrl_Handler,
+		},
+		{
+			MethodName: "ReportClicks",
+			Handler:    _ShortUrlService_ReportClicks_Handler,
+		},
+		{
+			MethodName: "GetClickStats",
+			Handler:    _ShortUrlService_GetClickStats_Handler,
+		},
+		{
+			MethodName: "ListTopLinks",
+			Handler:    _ShortUrlService_ListTopLinks_Handler,
+		},
+		{
+			MethodName: "VerifyPassword",
+			Handler:    _ShortUrlService_VerifyPassword_Handler,
+		},
+		{
+			MethodName: "ConsumeClick",
+			Handler:    _ShortUrlService_ConsumeClick_Handler,
+		},
+		{
+			MethodName: "VerifySignature",
+			Handler:    _ShortUrlService_VerifySignature_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "short_url.proto",
@@ -1,6 +1,11 @@
 package main
 
 import (
+	"net/http"
+	"short_url/pkg/election"
+	"short_url/pkg/lifecycle"
+	"short_url/rpc/repository/dao"
+
 	"github.com/robfig/cron/v3"
 	"github.com/to404hanga/pkg404/grpcx"
 )
@@ -8,4 +13,11 @@ import (
 type App struct {
 	GrpcServer *grpcx.Server
 	Cron       *cron.Cron
+	// Elector 决定本实例是否执行定时任务
+	Elector election.Elector
+	// MetricsServer 输出 expvar 指标，未配置 metrics.addr 时为 nil
+	MetricsServer *http.Server
+	// DAO 退出前需要关闭以刷新写入缓冲区
+	DAO       dao.ShortUrlDAO
+	Lifecycle *lifecycle.Manager
 }
@@ -0,0 +1,9 @@
+package domain
+
+// ApiKey 调用 /api 接口的凭证，Secret 用于计算请求签名
+type ApiKey struct {
+	KeyID    string
+	Secret   string
+	Name     string // 调用方备注
+	Disabled bool
+}
@@ -0,0 +1,162 @@
+package domain
+
+import "time"
+
+// Granularity 点击统计的时间粒度
+type Granularity int8
+
+const (
+	GranularityHour Granularity = 1
+	GranularityDay  Granularity = 2
+)
+
+// Duration 返回单个时间桶的时长
+func (g Granularity) Duration() time.Duration {
+	if g == GranularityDay {
+		return 24 * time.Hour
+	}
+	return time.Hour
+}
+
+// Truncate 返回时间戳（秒）所在时间桶的起始时间戳，按 UTC 对齐
+func (g Granularity) Truncate(ts int64) int64 {
+	return truncate(ts, g.Duration())
+}
+
+// ClickEvent 一次成功跳转产生的点击事件
+type ClickEvent struct {
+	ShortUrl  string
+	Timestamp time.Time
+	Referrer  string
+	UserAgent string
+	IPHash    string // 加盐哈希后的客户端 IP
+	Country   string // ISO 3166-1 二位国家代码，无法解析时为空
+	Variant   int32  // 命中的 A/B 分流目标序号，从 1 开始，0 表示未分流
+}
+
+// ClickStatPoint 单个时间桶内的点击数
+type ClickStatPoint struct {
+	Bucket         int64 // 时间桶起始时间戳（秒）
+	Clicks         int64
+	UniqueVisitors int64 // 近似独立访客数，仅按天统计时有值
+}
+
+// ClickStats 短链接的点击统计
+type ClickStats struct {
+	ShortUrl    string
+	Granularity Granularity
+	Total       int64 // 全部历史点击数
+	RangeTotal  int64 // 查询区间内的点击数
+	// RangeUniqueVisitors 查询区间内的近似独立访客数，跨天重复访问只计一次，仅按天统计时有值
+	RangeUniqueVisitors int64
+	Points              []ClickStatPoint
+	// Countries 查询区间内按国家汇总的点击数，按点击数降序，仅按天统计时有值
+	Countries []CountryClicks
+	// Variants 查询区间内各 A/B 分流目标的点击数，按序号升序，仅按天统计且短链接配置了分流时有值
+	Variants []VariantClicks
+}
+
+// VariantClicks 某个 A/B 分流目标的点击数
+type VariantClicks struct {
+	Variant int32 // 从 1 开始的序号
+	Target  string
+	Weight  int32
+	Clicks  int64
+}
+
+// VariantClickCount 待累加到某天某个分流目标的点击数
+type VariantClickCount struct {
+	ShortUrl string
+	Day      int64 // 当天 0 点的时间戳（秒，UTC）
+	Variant  int32
+	Clicks   int64
+}
+
+// CountryClicks 某个国家的点击数
+type CountryClicks struct {
+	Country string
+	Clicks  int64
+}
+
+// CountryClickCount 待累加到某天某个国家的点击数
+type CountryClickCount struct {
+	ShortUrl string
+	Day      int64 // 当天 0 点的时间戳（秒，UTC）
+	Country  string
+	Clicks   int64
+}
+
+// ClickCount 待累加到某个时间桶的点击数
+type ClickCount struct {
+	ShortUrl    string
+	Granularity Granularity
+	Bucket      int64
+	Clicks      int64
+}
+
+// DailyVisitors 某短链接在某一天的访客指纹
+type DailyVisitors struct {
+	ShortUrl     string
+	Day          int64 // 当天 0 点的时间戳（秒，UTC）
+	Fingerprints []string
+}
+
+// TopWindow 热门链接统计的滑动窗口
+type TopWindow int8
+
+const (
+	TopWindow5m TopWindow = 1
+	TopWindow1h TopWindow = 2
+	TopWindow1d TopWindow = 3
+)
+
+// TopWindows 所有滑动窗口，每次点击都会累加到各窗口的时间桶
+var TopWindows = []TopWindow{TopWindow5m, TopWindow1h, TopWindow1d}
+
+// Duration 返回窗口时长
+func (w TopWindow) Duration() time.Duration {
+	switch w {
+	case TopWindow5m:
+		return 5 * time.Minute
+	case TopWindow1h:
+		return time.Hour
+	default:
+		return 24 * time.Hour
+	}
+}
+
+// BucketSize 返回窗口内单个时间桶的时长，窗口由最近的若干个时间桶（含当前桶）近似
+func (w TopWindow) BucketSize() time.Duration {
+	switch w {
+	case TopWindow5m:
+		return time.Minute
+	case TopWindow1h:
+		return 5 * time.Minute
+	default:
+		return time.Hour
+	}
+}
+
+// HotLinkCount 待累加到热门链接窗口时间桶的点击数
+type HotLinkCount struct {
+	ShortUrl string
+	Window   TopWindow
+	Bucket   int64 // 时间桶起始时间戳（秒）
+	Clicks   int64
+}
+
+// Truncate 返回时间戳（秒）所在时间桶的起始时间戳
+func (w TopWindow) Truncate(ts int64) int64 {
+	return truncate(ts, w.BucketSize())
+}
+
+// LinkClicks 短链接在某个窗口内的点击数
+type LinkClicks struct {
+	ShortUrl string
+	Clicks   int64
+}
+
+func truncate(ts int64, d time.Duration) int64 {
+	size := int64(d / time.Second)
+	return ts - ((ts%size)+size)%size
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Platform 按 User-Agent 识别的客户端平台
+type Platform int8
+
+const (
+	PlatformIOS     Platform = 1
+	PlatformAndroid Platform = 2
+	PlatformWindows Platform = 3
+	PlatformMacOS   Platform = 4
+	PlatformLinux   Platform = 5
+)
+
+// IsValid 判断是否为支持的平台
+func (p Platform) IsValid() bool {
+	return p >= PlatformIOS && p <= PlatformLinux
+}
+
+// RoutingRule 按客户端平台与所在国家选择跳转目标，两个条件都指定时需同时满足，
+// 均未命中时跳转到原链接
+type RoutingRule struct {
+	Platform Platform // 0 表示不限平台
+	Country  string   // ISO 3166-1 二位大写国家代码，为空表示不限国家
+	Target   string
+}
+
+// IsValid 判断规则至少指定了一个受支持的条件、目标是否为合法的跳转地址
+func (r RoutingRule) IsValid() bool {
+	if r.Platform == 0 && r.Country == "" {
+		return false
+	}
+	if r.Platform != 0 && !r.Platform.IsValid() {
+		return false
+	}
+	if r.Country != "" && !IsCountryCode(r.Country) {
+		return false
+	}
+	return isValidTarget(r.Target)
+}
+
+// Variant A/B 分流的跳转目标，按权重随机分配访客
+type Variant struct {
+	Target string
+	Weight int32
+}
+
+// IsValid 判断权重是否为正数、目标是否为合法的跳转地址
+func (v Variant) IsValid() bool {
+	return v.Weight > 0 && isValidTarget(v.Target)
+}
+
+// isValidTarget 判断跳转目标是否为带协议的绝对地址，
+// 允许使用 itms-apps://、market:// 等应用商店协议，但不允许 javascript: 等可执行脚本的协议
+func isValidTarget(target string) bool {
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme == "" {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "javascript", "vbscript", "data", "file":
+		return false
+	}
+	return u.Host != "" || u.Opaque != "" || u.Path != ""
+}
+
+// IsCountryCode 判断是否为两位大写字母的国家代码
+func IsCountryCode(code string) bool {
+	if len(code) != 2 {
+		return false
+	}
+	for i := 0; i < len(code); i++ {
+		if code[i] < 'A' || code[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+package domain
+
+import "time"
+
+// NeverExpire 表示短链接永不过期
+const NeverExpire int64 = -1
+
+type ShortUrl struct {
+	ShortUrl     string
+	OriginUrl    string
+	ExpiredAt    int64         // 过期时间戳（秒），NeverExpire 表示永不过期
+	NotBefore    int64         // 生效时间戳（秒），此前不跳转，0 表示创建后立即生效
+	RedirectCode int           // 跳转使用的 HTTP 状态码，0 表示使用默认值
+	PassThrough  bool          // 跳转时是否将请求中的查询参数与短码之后的路径追加到原链接
+	UtmTemplate  string        // 创建时使用的 UTM 模板名
+	Utm          Utm           // 跳转时合并到原链接的 UTM 参数，已包含模板中的值
+	Password     string        // 创建时的明文密码，仅用于生成 PasswordHash，不落库
+	PasswordHash string        // 访问密码的 bcrypt 哈希，为空表示无需密码
+	MaxClicks    int64         // 最大点击次数，耗尽后短链接立即过期，0 表示不限
+	Rules        []RoutingRule // 按客户端平台与国家选择跳转目标的规则，按顺序匹配，均未命中时跳转到 OriginUrl
+	// Variants A/B 分流的跳转目标，不为空时按权重选择其一代替 OriginUrl，跳转规则优先于分流
+	Variants []Variant
+}
+
+// IsProtected 判断访问短链接是否需要密码
+func (su ShortUrl) IsProtected() bool {
+	return su.PasswordHash != ""
+}
+
+// IsValidRedirectCode 判断是否为支持的跳转状态码，0 表示使用默认值
+func IsValidRedirectCode(code int) bool {
+	switch code {
+	case 0, 301, 302, 307, 308:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsExpired 判断短链接在 now（秒）时是否已过期
+func (s ShortUrl) IsExpired(now int64) bool {
+	return s.ExpiredAt != NeverExpire && s.ExpiredAt <= now
+}
+
+// IsActive 判断短链接在 now（秒）时是否已到生效时间
+func (s ShortUrl) IsActive(now int64) bool {
+	return s.NotBefore <= now
+}
+
+// Expiration 创建短链接时指定的有效期，均为零值时使用默认有效期
+type Expiration struct {
+	ExpiredAt int64         // 指定过期时间戳（秒）
+	TTL       time.Duration // 指定有效期，指定了生效时间时从生效时间起算
+	Never     bool          // 永不过期
+	NotBefore int64         // 生效时间戳（秒），0 表示立即生效
+}
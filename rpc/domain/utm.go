@@ -0,0 +1,69 @@
+package domain
+
+import "net/url"
+
+// Utm 跳转时合并到原链接的 UTM 参数，空字段不追加
+type Utm struct {
+	Source   string
+	Medium   string
+	Campaign string
+	Term     string
+	Content  string
+}
+
+// IsZero 判断是否未设置任何 UTM 参数
+func (u Utm) IsZero() bool {
+	return u == Utm{}
+}
+
+// Override 使用 o 中非空的字段覆盖 u 中对应的字段
+func (u Utm) Override(o Utm) Utm {
+	if o.Source != "" {
+		u.Source = o.Source
+	}
+	if o.Medium != "" {
+		u.Medium = o.Medium
+	}
+	if o.Campaign != "" {
+		u.Campaign = o.Campaign
+	}
+	if o.Term != "" {
+		u.Term = o.Term
+	}
+	if o.Content != "" {
+		u.Content = o.Content
+	}
+	return u
+}
+
+// Encode 编码为查询字符串，如 utm_source=a&utm_medium=b
+func (u Utm) Encode() string {
+	v := url.Values{}
+	for key, val := range map[string]string{
+		"utm_source":   u.Source,
+		"utm_medium":   u.Medium,
+		"utm_campaign": u.Campaign,
+		"utm_term":     u.Term,
+		"utm_content":  u.Content,
+	} {
+		if val != "" {
+			v.Set(key, val)
+		}
+	}
+	return v.Encode()
+}
+
+// ParseUtm 解析 Encode 生成的查询字符串，无法解析时返回空值
+func ParseUtm(raw string) Utm {
+	v, err := url.ParseQuery(raw)
+	if err != nil {
+		return Utm{}
+	}
+	return Utm{
+		Source:   v.Get("utm_source"),
+		Medium:   v.Get("utm_medium"),
+		Campaign: v.Get("utm_campaign"),
+		Term:     v.Get("utm_term"),
+		Content:  v.Get("utm_content"),
+	}
+}
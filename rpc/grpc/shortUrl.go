@@ -2,19 +2,26 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	short_url_v1 "short_url/proto/short_url/v1"
+	"short_url/rpc/domain"
 	"short_url/rpc/service"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type ShortUrlServiceServer struct {
 	short_url_v1.UnimplementedShortUrlServiceServer
-	svc service.ShortUrlService
+	svc     service.ShortUrlService
+	stat    service.ClickStatService
+	apiKeys service.ApiKeyService
 }
 
-func NewShortUrlServiceServer(svc service.ShortUrlService) *ShortUrlServiceServer {
-	return &ShortUrlServiceServer{svc: svc}
+func NewShortUrlServiceServer(svc service.ShortUrlService, stat service.ClickStatService, apiKeys service.ApiKeyService) *ShortUrlServiceServer {
+	return &ShortUrlServiceServer{svc: svc, stat: stat, apiKeys: apiKeys}
 }
 
 func (s *ShortUrlServiceServer) Register(server grpc.ServiceRegistrar) {
@@ -22,17 +29,438 @@ func (s *ShortUrlServiceServer) Register(server grpc.ServiceRegistrar) {
 }
 
 func (s *ShortUrlServiceServer) GenerateShortUrl(ctx context.Context, req *short_url_v1.GenerateShortUrlRequest) (*short_url_v1.GenerateShortUrlResponse, error) {
-	shortUrl, err := s.svc.Create(ctx, req.GetOriginUrl())
+	if req.GetExpiredAt() < 0 || req.GetTtl() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "expired_at and ttl must not be negative")
+	}
+	su, err := s.svc.Create(ctx, domain.ShortUrl{
+		ShortUrl:     req.GetCustomAlias(),
+		OriginUrl:    req.GetOriginUrl(),
+		RedirectCode: int(req.GetRedirectCode()),
+		PassThrough:  req.GetPassThrough(),
+		UtmTemplate:  req.GetUtmTemplate(),
+		Utm:          fromUtm(req.GetUtm()),
+		Password:     req.GetPassword(),
+		MaxClicks:    req.GetMaxClicks(),
+		Rules:        fromRoutingRules(req.GetRules()),
+		Variants:     fromVariants(req.GetVariants()),
+	}, domain.Expiration{
+		ExpiredAt: req.GetExpiredAt(),
+		TTL:       time.Duration(req.GetTtl()) * time.Second,
+		Never:     req.GetNeverExpire(),
+		NotBefore: req.GetNotBefore(),
+	})
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(err)
+	}
+	resp := &short_url_v1.GenerateShortUrlResponse{
+		ShortUrl:     su.ShortUrl,
+		RedirectCode: int32(su.RedirectCode),
+		PassThrough:  su.PassThrough,
+		Utm:          toUtm(su.Utm),
+
+		PasswordProtected: su.IsProtected(),
+		MaxClicks:         su.MaxClicks,
+		Rules:             toRoutingRules(su.Rules),
+		Variants:          toVariants(su.Variants),
+		NotBefore:         su.NotBefore,
+	}
+	if su.ExpiredAt == domain.NeverExpire {
+		resp.NeverExpire = true
+	} else {
+		resp.ExpiredAt = su.ExpiredAt
 	}
-	return &short_url_v1.GenerateShortUrlResponse{ShortUrl: shortUrl}, nil
+	return resp, nil
+}
+
+func (s *ShortUrlServiceServer) BatchGenerateShortUrl(ctx context.Context, req *short_url_v1.BatchGenerateShortUrlRequest) (*short_url_v1.BatchGenerateShortUrlResponse, error) {
+	results := make([]*short_url_v1.BatchGenerateShortUrlResult, len(req.GetItems()))
+	items := make([]service.BatchCreateItem, 0, len(req.GetItems()))
+	idx := make([]int, 0, len(req.GetItems()))
+	for i, item := range req.GetItems() {
+		if item.GetExpiredAt() < 0 || item.GetTtl() < 0 {
+			results[i] = &short_url_v1.BatchGenerateShortUrlResult{
+				Code:    int32(codes.InvalidArgument),
+				Message: "expired_at and ttl must not be negative",
+			}
+			continue
+		}
+		items = append(items, service.BatchCreateItem{
+			ShortUrl: domain.ShortUrl{
+				ShortUrl:     item.GetCustomAlias(),
+				OriginUrl:    item.GetOriginUrl(),
+				RedirectCode: int(item.GetRedirectCode()),
+				PassThrough:  item.GetPassThrough(),
+				UtmTemplate:  item.GetUtmTemplate(),
+				Utm:          fromUtm(item.GetUtm()),
+				Password:     item.GetPassword(),
+				MaxClicks:    item.GetMaxClicks(),
+				Rules:        fromRoutingRules(item.GetRules()),
+				Variants:     fromVariants(item.GetVariants()),
+			},
+			Expiration: domain.Expiration{
+				ExpiredAt: item.GetExpiredAt(),
+				TTL:       time.Duration(item.GetTtl()) * time.Second,
+				Never:     item.GetNeverExpire(),
+				NotBefore: item.GetNotBefore(),
+			},
+		})
+		idx = append(idx, i)
+	}
+
+	created, err := s.svc.BatchCreate(ctx, items)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	for j, res := range created {
+		result := &short_url_v1.BatchGenerateShortUrlResult{}
+		if res.Err != nil {
+			st := status.Convert(toStatusError(res.Err))
+			result.Code = int32(st.Code())
+			result.Message = st.Message()
+		} else {
+			result.ShortUrl = res.ShortUrl.ShortUrl
+			if res.ShortUrl.ExpiredAt == domain.NeverExpire {
+				result.NeverExpire = true
+			} else {
+				result.ExpiredAt = res.ShortUrl.ExpiredAt
+			}
+		}
+		results[idx[j]] = result
+	}
+	return &short_url_v1.BatchGenerateShortUrlResponse{Results: results}, nil
 }
 
 func (s *ShortUrlServiceServer) GetOriginUrl(ctx context.Context, req *short_url_v1.GetOriginUrlRequest) (*short_url_v1.GetOriginUrlResponse, error) {
-	originUrl, err := s.svc.Redirect(ctx, req.GetShortUrl())
+	su, err := s.svc.Redirect(ctx, req.GetShortUrl())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &short_url_v1.GetOriginUrlResponse{
+		OriginUrl:    su.OriginUrl,
+		RedirectCode: int32(su.RedirectCode),
+		PassThrough:  su.PassThrough,
+		Utm:          toUtm(su.Utm),
+
+		PasswordProtected: su.IsProtected(),
+		MaxClicks:         su.MaxClicks,
+		Rules:             toRoutingRules(su.Rules),
+		Variants:          toVariants(su.Variants),
+	}, nil
+}
+
+func (s *ShortUrlServiceServer) BatchGetOriginUrl(ctx context.Context, req *short_url_v1.BatchGetOriginUrlRequest) (*short_url_v1.BatchGetOriginUrlResponse, error) {
+	resolved, err := s.svc.BatchRedirect(ctx, req.GetShortUrls())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	results := make([]*short_url_v1.BatchGetOriginUrlResult, 0, len(resolved))
+	for i, res := range resolved {
+		result := &short_url_v1.BatchGetOriginUrlResult{ShortUrl: req.GetShortUrls()[i]}
+		if res.Err != nil {
+			st := status.Convert(toStatusError(res.Err))
+			result.Code = int32(st.Code())
+			result.Message = st.Message()
+		} else {
+			result.OriginUrl = res.OriginUrl
+		}
+		results = append(results, result)
+	}
+	return &short_url_v1.BatchGetOriginUrlResponse{Results: results}, nil
+}
+
+func (s *ShortUrlServiceServer) GetShortUrlInfo(ctx context.Context, req *short_url_v1.GetShortUrlInfoRequest) (*short_url_v1.GetShortUrlInfoResponse, error) {
+	su, err := s.svc.Info(ctx, req.GetShortUrl())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &short_url_v1.GetShortUrlInfoResponse{Info: toShortUrlInfo(su)}, nil
+}
+
+func (s *ShortUrlServiceServer) UpdateOriginUrl(ctx context.Context, req *short_url_v1.UpdateOriginUrlRequest) (*short_url_v1.UpdateOriginUrlResponse, error) {
+	if req.GetOriginUrl() == "" {
+		return nil, status.Error(codes.InvalidArgument, "origin_url must not be empty")
+	}
+	su, err := s.svc.UpdateOriginUrl(ctx, req.GetShortUrl(), req.GetOriginUrl())
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(err)
+	}
+	return &short_url_v1.UpdateOriginUrlResponse{Info: toShortUrlInfo(su)}, nil
+}
+
+func (s *ShortUrlServiceServer) DeleteShortUrl(ctx context.Context, req *short_url_v1.DeleteShortUrlRequest) (*short_url_v1.DeleteShortUrlResponse, error) {
+	if err := s.svc.Delete(ctx, req.GetShortUrl()); err != nil {
+		return nil, toStatusError(err)
+	}
+	return &short_url_v1.DeleteShortUrlResponse{}, nil
+}
+
+func (s *ShortUrlServiceServer) ExtendExpiration(ctx context.Context, req *short_url_v1.ExtendExpirationRequest) (*short_url_v1.ExtendExpirationResponse, error) {
+	if req.GetExpiredAt() < 0 || req.GetTtl() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "expired_at and ttl must not be negative")
+	}
+	su, err := s.svc.ExtendExpiration(ctx, req.GetShortUrl(), domain.Expiration{
+		ExpiredAt: req.GetExpiredAt(),
+		TTL:       time.Duration(req.GetTtl()) * time.Second,
+		Never:     req.GetNeverExpire(),
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &short_url_v1.ExtendExpirationResponse{Info: toShortUrlInfo(su)}, nil
+}
+
+func (s *ShortUrlServiceServer) ReportClicks(ctx context.Context, req *short_url_v1.ReportClicksRequest) (*short_url_v1.ReportClicksResponse, error) {
+	events := make([]domain.ClickEvent, 0, len(req.GetEvents()))
+	for _, ev := range req.GetEvents() {
+		events = append(events, domain.ClickEvent{
+			ShortUrl:  ev.GetShortUrl(),
+			Timestamp: time.UnixMilli(ev.GetTimestamp()),
+			Referrer:  ev.GetReferrer(),
+			UserAgent: ev.GetUserAgent(),
+			IPHash:    ev.GetIpHash(),
+			Country:   ev.GetCountry(),
+			Variant:   ev.GetVariant(),
+		})
+	}
+	accepted, err := s.stat.RecordClicks(ctx, events)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &short_url_v1.ReportClicksResponse{Accepted: int32(accepted)}, nil
+}
+
+func (s *ShortUrlServiceServer) GetClickStats(ctx context.Context, req *short_url_v1.GetClickStatsRequest) (*short_url_v1.GetClickStatsResponse, error) {
+	var granularity domain.Granularity
+	switch req.GetGranularity() {
+	case short_url_v1.StatGranularity_STAT_GRANULARITY_UNSPECIFIED, short_url_v1.StatGranularity_STAT_GRANULARITY_HOUR:
+		granularity = domain.GranularityHour
+	case short_url_v1.StatGranularity_STAT_GRANULARITY_DAY:
+		granularity = domain.GranularityDay
+	default:
+		return nil, status.Error(codes.InvalidArgument, "unknown granularity")
+	}
+	stats, err := s.stat.ClickStats(ctx, req.GetShortUrl(), granularity, req.GetStart(), req.GetEnd())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	resp := &short_url_v1.GetClickStatsResponse{
+		ShortUrl:    stats.ShortUrl,
+		Granularity: short_url_v1.StatGranularity_STAT_GRANULARITY_HOUR,
+		Total:       stats.Total,
+		RangeTotal:  stats.RangeTotal,
+		Points:      make([]*short_url_v1.ClickStatPoint, 0, len(stats.Points)),
+
+		RangeUniqueVisitors: stats.RangeUniqueVisitors,
+	}
+	if stats.Granularity == domain.GranularityDay {
+		resp.Granularity = short_url_v1.StatGranularity_STAT_GRANULARITY_DAY
+	}
+	for _, p := range stats.Points {
+		resp.Points = append(resp.Points, &short_url_v1.ClickStatPoint{
+			Timestamp:      p.Bucket,
+			Clicks:         p.Clicks,
+			UniqueVisitors: p.UniqueVisitors,
+		})
+	}
+	for _, c := range stats.Countries {
+		resp.Countries = append(resp.Countries, &short_url_v1.CountryClicks{
+			Country: c.Country,
+			Clicks:  c.Clicks,
+		})
+	}
+	for _, v := range stats.Variants {
+		resp.Variants = append(resp.Variants, &short_url_v1.VariantClicks{
+			Variant: v.Variant,
+			Target:  v.Target,
+			Weight:  v.Weight,
+			Clicks:  v.Clicks,
+		})
+	}
+	return resp, nil
+}
+
+func (s *ShortUrlServiceServer) ListTopLinks(ctx context.Context, req *short_url_v1.ListTopLinksRequest) (*short_url_v1.ListTopLinksResponse, error) {
+	var window domain.TopWindow
+	switch req.GetWindow() {
+	case short_url_v1.TopWindow_TOP_WINDOW_5M:
+		window = domain.TopWindow5m
+	case short_url_v1.TopWindow_TOP_WINDOW_UNSPECIFIED, short_url_v1.TopWindow_TOP_WINDOW_1H:
+		window = domain.TopWindow1h
+	case short_url_v1.TopWindow_TOP_WINDOW_1D:
+		window = domain.TopWindow1d
+	default:
+		return nil, status.Error(codes.InvalidArgument, "unknown window")
+	}
+	links, err := s.stat.TopLinks(ctx, window, int(req.GetLimit()))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	resp := &short_url_v1.ListTopLinksResponse{
+		Window: req.GetWindow(),
+		Links:  make([]*short_url_v1.TopLink, 0, len(links)),
+	}
+	if resp.Window == short_url_v1.TopWindow_TOP_WINDOW_UNSPECIFIED {
+		resp.Window = short_url_v1.TopWindow_TOP_WINDOW_1H
+	}
+	for _, link := range links {
+		resp.Links = append(resp.Links, &short_url_v1.TopLink{
+			ShortUrl: link.ShortUrl,
+			Clicks:   link.Clicks,
+		})
+	}
+	return resp, nil
+}
+
+func (s *ShortUrlServiceServer) VerifyPassword(ctx context.Context, req *short_url_v1.VerifyPasswordRequest) (*short_url_v1.VerifyPasswordResponse, error) {
+	ok, err := s.svc.VerifyPassword(ctx, req.GetShortUrl(), req.GetPassword())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &short_url_v1.VerifyPasswordResponse{Ok: ok}, nil
+}
+
+func (s *ShortUrlServiceServer) ConsumeClick(ctx context.Context, req *short_url_v1.ConsumeClickRequest) (*short_url_v1.ConsumeClickResponse, error) {
+	remaining, ok, err := s.svc.ConsumeClick(ctx, req.GetShortUrl())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &short_url_v1.ConsumeClickResponse{Ok: ok, Remaining: remaining}, nil
+}
+
+func toShortUrlInfo(su domain.ShortUrl) *short_url_v1.ShortUrlInfo {
+	info := &short_url_v1.ShortUrlInfo{
+		ShortUrl:     su.ShortUrl,
+		OriginUrl:    su.OriginUrl,
+		RedirectCode: int32(su.RedirectCode),
+		PassThrough:  su.PassThrough,
+		Utm:          toUtm(su.Utm),
+		UtmTemplate:  su.UtmTemplate,
+
+		PasswordProtected: su.IsProtected(),
+		MaxClicks:         su.MaxClicks,
+		Rules:             toRoutingRules(su.Rules),
+		Variants:          toVariants(su.Variants),
+		NotBefore:         su.NotBefore,
+	}
+	if su.ExpiredAt == domain.NeverExpire {
+		info.NeverExpire = true
+	} else {
+		info.ExpiredAt = su.ExpiredAt
+	}
+	return info
+}
+
+func fromUtm(utm *short_url_v1.Utm) domain.Utm {
+	return domain.Utm{
+		Source:   utm.GetSource(),
+		Medium:   utm.GetMedium(),
+		Campaign: utm.GetCampaign(),
+		Term:     utm.GetTerm(),
+		Content:  utm.GetContent(),
+	}
+}
+
+// toUtm 未设置 UTM 参数时返回 nil，避免响应中出现空对象
+func toUtm(utm domain.Utm) *short_url_v1.Utm {
+	if utm.IsZero() {
+		return nil
+	}
+	return &short_url_v1.Utm{
+		Source:   utm.Source,
+		Medium:   utm.Medium,
+		Campaign: utm.Campaign,
+		Term:     utm.Term,
+		Content:  utm.Content,
+	}
+}
+
+func fromRoutingRules(rules []*short_url_v1.RoutingRule) []domain.RoutingRule {
+	if len(rules) == 0 {
+		return nil
+	}
+	res := make([]domain.RoutingRule, 0, len(rules))
+	for _, r := range rules {
+		res = append(res, domain.RoutingRule{
+			Platform: domain.Platform(r.GetPlatform()),
+			Country:  r.GetCountry(),
+			Target:   r.GetTarget(),
+		})
+	}
+	return res
+}
+
+func toRoutingRules(rules []domain.RoutingRule) []*short_url_v1.RoutingRule {
+	if len(rules) == 0 {
+		return nil
+	}
+	res := make([]*short_url_v1.RoutingRule, 0, len(rules))
+	for _, r := range rules {
+		res = append(res, &short_url_v1.RoutingRule{
+			Platform: short_url_v1.Platform(r.Platform),
+			Country:  r.Country,
+			Target:   r.Target,
+		})
+	}
+	return res
+}
+
+func fromVariants(variants []*short_url_v1.Variant) []domain.Variant {
+	if len(variants) == 0 {
+		return nil
+	}
+	res := make([]domain.Variant, 0, len(variants))
+	for _, v := range variants {
+		res = append(res, domain.Variant{Target: v.GetTarget(), Weight: v.GetWeight()})
+	}
+	return res
+}
+
+func toVariants(variants []domain.Variant) []*short_url_v1.Variant {
+	if len(variants) == 0 {
+		return nil
+	}
+	res := make([]*short_url_v1.Variant, 0, len(variants))
+	for _, v := range variants {
+		res = append(res, &short_url_v1.Variant{Target: v.Target, Weight: v.Weight})
+	}
+	return res
+}
+
+// VerifySignature 校验 /api 请求的签名，密钥只在 rpc 层使用，不返回给调用方
+func (s *ShortUrlServiceServer) VerifySignature(ctx context.Context, req *short_url_v1.VerifySignatureRequest) (*short_url_v1.VerifySignatureResponse, error) {
+	err := s.apiKeys.Verify(ctx, req.GetKeyId(), req.GetParams(), req.GetSignature())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &short_url_v1.VerifySignatureResponse{}, nil
+}
+
+// toStatusError 将业务错误转换为对应的 gRPC 状态码，便于调用方区分处理
+func toStatusError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidAlias), errors.Is(err, service.ErrInvalidExpiration),
+		errors.Is(err, service.ErrInvalidOriginUrl), errors.Is(err, service.ErrBatchTooLarge),
+		errors.Is(err, service.ErrInvalidStatRange), errors.Is(err, service.ErrInvalidRedirect),
+		errors.Is(err, service.ErrUnknownUtmTemplate), errors.Is(err, service.ErrInvalidUtm),
+		errors.Is(err, service.ErrInvalidPassword), errors.Is(err, service.ErrInvalidMaxClicks),
+		errors.Is(err, service.ErrInvalidRoutingRule),
+		errors.Is(err, service.ErrInvalidVariants):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrAliasConflict):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, service.ErrShortUrlNotFound), errors.Is(err, service.ErrApiKeyNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrNotYetAvailable), errors.Is(err, service.ErrClickLimited):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrPasswordRequired):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, service.ErrInvalidSignature):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, service.ErrBufferFull):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	default:
+		return err
 	}
-	return &short_url_v1.GetOriginUrlResponse{OriginUrl: originUrl}, nil
 }
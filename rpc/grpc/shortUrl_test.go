@@ -0,0 +1,114 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	short_url_v1 "short_url/proto/short_url/v1"
+	"short_url/rpc/domain"
+	"short_url/rpc/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// batchShortUrlService 按原链接返回预设的批量创建结果，并记录收到的条目
+type batchShortUrlService struct {
+	service.ShortUrlService
+	results map[string]service.BatchCreateResult
+	items   []service.BatchCreateItem
+	err     error
+}
+
+func (s *batchShortUrlService) BatchCreate(ctx context.Context, items []service.BatchCreateItem) ([]service.BatchCreateResult, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.items = items
+	results := make([]service.BatchCreateResult, 0, len(items))
+	for _, item := range items {
+		results = append(results, s.results[item.ShortUrl.OriginUrl])
+	}
+	return results, nil
+}
+
+func TestShortUrlServiceServer_BatchGenerateShortUrl(t *testing.T) {
+	svc := &batchShortUrlService{results: map[string]service.BatchCreateResult{
+		"https://example.com/ok":       {ShortUrl: domain.ShortUrl{ShortUrl: "abc1234", ExpiredAt: 1700000000}},
+		"https://example.com/never":    {ShortUrl: domain.ShortUrl{ShortUrl: "abc5678", ExpiredAt: domain.NeverExpire}},
+		"https://example.com/alias":    {Err: service.ErrInvalidAlias},
+		"https://example.com/conflict": {Err: service.ErrAliasConflict},
+		"https://example.com/full":     {Err: service.ErrBufferFull},
+		"https://example.com/db":       {Err: errors.New("db down")},
+	}}
+	server := NewShortUrlServiceServer(svc, nil, nil)
+
+	testCases := []struct {
+		name string
+		req  *short_url_v1.GenerateShortUrlRequest
+		want *short_url_v1.BatchGenerateShortUrlResult
+	}{
+		{
+			name: "成功",
+			req:  &short_url_v1.GenerateShortUrlRequest{OriginUrl: "https://example.com/ok"},
+			want: &short_url_v1.BatchGenerateShortUrlResult{ShortUrl: "abc1234", ExpiredAt: 1700000000},
+		},
+		{
+			name: "参数不合法的条目不交给 service",
+			req:  &short_url_v1.GenerateShortUrlRequest{OriginUrl: "https://example.com/ok", Ttl: -1},
+			want: &short_url_v1.BatchGenerateShortUrlResult{Code: int32(codes.InvalidArgument), Message: "expired_at and ttl must not be negative"},
+		},
+		{
+			name: "永不过期",
+			req:  &short_url_v1.GenerateShortUrlRequest{OriginUrl: "https://example.com/never"},
+			want: &short_url_v1.BatchGenerateShortUrlResult{ShortUrl: "abc5678", NeverExpire: true},
+		},
+		{
+			name: "业务参数错误",
+			req:  &short_url_v1.GenerateShortUrlRequest{OriginUrl: "https://example.com/alias"},
+			want: &short_url_v1.BatchGenerateShortUrlResult{Code: int32(codes.InvalidArgument), Message: service.ErrInvalidAlias.Error()},
+		},
+		{
+			name: "别名冲突",
+			req:  &short_url_v1.GenerateShortUrlRequest{OriginUrl: "https://example.com/conflict"},
+			want: &short_url_v1.BatchGenerateShortUrlResult{Code: int32(codes.AlreadyExists), Message: service.ErrAliasConflict.Error()},
+		},
+		{
+			name: "缓冲区已满",
+			req:  &short_url_v1.GenerateShortUrlRequest{OriginUrl: "https://example.com/full"},
+			want: &short_url_v1.BatchGenerateShortUrlResult{Code: int32(codes.ResourceExhausted), Message: service.ErrBufferFull.Error()},
+		},
+		{
+			name: "未知错误",
+			req:  &short_url_v1.GenerateShortUrlRequest{OriginUrl: "https://example.com/db"},
+			want: &short_url_v1.BatchGenerateShortUrlResult{Code: int32(codes.Unknown), Message: "db down"},
+		},
+	}
+	reqs := make([]*short_url_v1.GenerateShortUrlRequest, 0, len(testCases))
+	for _, tc := range testCases {
+		reqs = append(reqs, tc.req)
+	}
+	resp, err := server.BatchGenerateShortUrl(context.Background(), &short_url_v1.BatchGenerateShortUrlRequest{Items: reqs})
+	require.NoError(t, err)
+	require.Len(t, resp.GetResults(), len(testCases))
+	// 被拦截的条目不占用 service 的结果下标
+	assert.Len(t, svc.items, len(testCases)-1)
+
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := resp.GetResults()[i]
+			assert.Equal(t, tc.want.GetShortUrl(), got.GetShortUrl())
+			assert.Equal(t, tc.want.GetExpiredAt(), got.GetExpiredAt())
+			assert.Equal(t, tc.want.GetNeverExpire(), got.GetNeverExpire())
+			assert.Equal(t, tc.want.GetCode(), got.GetCode())
+			assert.Equal(t, tc.want.GetMessage(), got.GetMessage())
+		})
+	}
+
+	// 整批超过上限时返回请求级错误
+	svc.err = service.ErrBatchTooLarge
+	_, err = server.BatchGenerateShortUrl(context.Background(), &short_url_v1.BatchGenerateShortUrlRequest{Items: reqs})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,52 @@
+package ioc
+
+import (
+	"short_url/pkg/sign/hmacsha256"
+	"short_url/rpc/repository"
+	"short_url/rpc/repository/cache"
+	"short_url/rpc/repository/dao"
+	"short_url/rpc/service"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"github.com/to404hanga/pkg404/logger"
+)
+
+// InitApiKeyCache 初始化 API Key 缓存，与短链接缓存共用 redis.prefix
+func InitApiKeyCache(cmd redis.Cmdable) cache.ApiKeyCache {
+	prefix := viper.GetString("redis.prefix")
+	if prefix == "" {
+		prefix = "short_url"
+	}
+	return cache.NewRedisApiKeyCache(cmd, prefix)
+}
+
+func InitApiKeyRepository(dao dao.ApiKeyDAO, cache cache.ApiKeyCache, l logger.Logger) repository.ApiKeyRepository {
+	type Config struct {
+		// Expiration 密钥的缓存时长，禁用或轮换密钥后最多经过该时长生效
+		Expiration int `yaml:"expiration"`
+		// MissExpiration 不存在的 Key 的缓存时长，创建 Key 后最多经过该时长可用
+		MissExpiration int `yaml:"missExpiration"`
+	}
+	cfg := Config{
+		Expiration:     300, // 单位 秒
+		MissExpiration: 30,
+	}
+	if err := viper.UnmarshalKey("api_key", &cfg); err != nil {
+		panic(err)
+	}
+	if cfg.Expiration <= 0 || cfg.MissExpiration <= 0 {
+		panic("api_key.expiration and missExpiration must be positive")
+	}
+
+	return repository.NewCachedApiKeyRepository(dao, cache, l,
+		time.Duration(cfg.Expiration)*time.Second,
+		time.Duration(cfg.MissExpiration)*time.Second,
+	)
+}
+
+// InitApiKeyService 签名算法需与 web 层约定的一致
+func InitApiKeyService(repo repository.ApiKeyRepository) service.ApiKeyService {
+	return service.NewCachedApiKeyService(repo, hmacsha256.NewHmacSha256SignHandler())
+}
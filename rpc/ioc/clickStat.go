@@ -0,0 +1,82 @@
+package ioc
+
+import (
+	"short_url/rpc/repository"
+	"short_url/rpc/repository/cache"
+	"short_url/rpc/service"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"github.com/to404hanga/pkg404/logger"
+)
+
+func InitClickStatService(repo repository.ClickStatRepository, shortUrls repository.ShortUrlRepository, l logger.Logger) service.ClickStatService {
+	type Config struct {
+		MaxEvents    int   `yaml:"maxEvents"`
+		MaxLateness  int64 `yaml:"maxLateness"`
+		MaxHourRange int   `yaml:"maxHourRange"`
+		MaxDayRange  int   `yaml:"maxDayRange"`
+		// VisitorRetention 独立访客计数保留天数
+		VisitorRetention int `yaml:"visitorRetention"`
+		MaxTopN          int `yaml:"maxTopN"`
+	}
+	cfg := Config{
+		MaxEvents:    5000,
+		MaxLateness:  86400, // 单位 秒
+		MaxHourRange: 24 * 31,
+		MaxDayRange:  366,
+
+		VisitorRetention: 90,
+		MaxTopN:          100,
+	}
+	if err := viper.UnmarshalKey("analytics", &cfg); err != nil {
+		panic(err)
+	}
+	if cfg.MaxEvents <= 0 || cfg.MaxLateness <= 0 || cfg.MaxHourRange <= 0 || cfg.MaxDayRange <= 0 || cfg.VisitorRetention <= 0 || cfg.MaxTopN <= 0 {
+		panic("analytics.maxEvents, maxLateness, maxHourRange, maxDayRange, visitorRetention and maxTopN must be positive")
+	}
+
+	return service.NewAggregatedClickStatService(repo, shortUrls, l, service.ClickStatPolicy{
+		MaxEvents:    cfg.MaxEvents,
+		MaxLateness:  time.Duration(cfg.MaxLateness) * time.Second,
+		MaxHourRange: cfg.MaxHourRange,
+		MaxDayRange:  cfg.MaxDayRange,
+
+		VisitorRetention: time.Duration(cfg.VisitorRetention) * 24 * time.Hour,
+		MaxTopN:          cfg.MaxTopN,
+	})
+}
+
+// InitUniqueVisitorCache 初始化独立访客计数，key 前缀与短链接缓存共用 redis.prefix
+func InitUniqueVisitorCache(cmd redis.Cmdable) cache.UniqueVisitorCache {
+	prefix := viper.GetString("redis.prefix")
+	if prefix == "" {
+		prefix = "short_url"
+	}
+	return cache.NewRedisUniqueVisitorCache(cmd, prefix)
+}
+
+// InitHotLinkCache 初始化热门链接统计，key 前缀与短链接缓存共用 redis.prefix
+func InitHotLinkCache(cmd redis.Cmdable) cache.HotLinkCache {
+	type Config struct {
+		MaxMembers int64 `yaml:"maxMembers"`
+		ResultTTL  int64 `yaml:"resultTTL"`
+	}
+	cfg := Config{
+		MaxMembers: 10000,
+		ResultTTL:  5, // 单位 秒
+	}
+	if err := viper.UnmarshalKey("analytics.topLinks", &cfg); err != nil {
+		panic(err)
+	}
+	if cfg.MaxMembers <= 0 || cfg.ResultTTL <= 0 {
+		panic("analytics.topLinks.maxMembers and resultTTL must be positive")
+	}
+
+	prefix := viper.GetString("redis.prefix")
+	if prefix == "" {
+		prefix = "short_url"
+	}
+	return cache.NewRedisHotLinkCache(cmd, prefix, cfg.MaxMembers, time.Duration(cfg.ResultTTL)*time.Second)
+}
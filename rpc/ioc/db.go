@@ -2,8 +2,10 @@ package ioc
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"expvar"
 	"fmt"
-	"log"
 	"short_url/pkg/generator"
 	"short_url/rpc/repository/dao"
 	"time"
@@ -26,11 +28,14 @@ func InitDB(l logger.Logger, cmd redis.Cmdable) *gorm.DB {
 		Port                   int    `yaml:"port"`
 		Database               string `yaml:"database"`
 		TablePrefix            string `yaml:"tablePrefix"`
-		EnableDBInit           bool   `yaml:"enableDBInit"`
 		SlowThreshold          int64  `yaml:"slowThreshold"`
 		SkipDefaultTransaction bool   `yaml:"skipDefaultTransaction"`
+		// MigrateTimeout 启动时等待迁移锁与执行迁移的最长时间，单位 秒
+		MigrateTimeout int64 `yaml:"migrateTimeout"`
+	}
+	cfg := Config{
+		MigrateTimeout: 600,
 	}
-	var cfg Config
 	err := viper.UnmarshalKey("db", &cfg)
 	if err != nil {
 		panic(err)
@@ -76,24 +81,152 @@ func InitDB(l logger.Logger, cmd redis.Cmdable) *gorm.DB {
 		},
 	}, "short_url"))
 
-	// 通过配置文件决定启动时是否初始化数据库
-	if cfg.EnableDBInit {
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
-		defer cancel()
-		if ok, _ := cmd.SetNX(ctx, "db_init", true, time.Minute).Result(); ok {
-			var rows int64
-			err := db.WithContext(ctx).Model(&dao.Mark{}).Count(&rows).Error
-			if rows == 0 && (err == nil || err.Error() == "Error 1146 (42S02): Table 'short_url.mark' doesn't exist") {
-				go func() {
-					log.Println("Starting database initialization...")
-					dao.InitTables(db)
-					log.Println("Database initialization completed.")
-				}()
+	// 每次启动都执行迁移，新版本增加的表与列在服务处理请求之前就绪
+	if cfg.MigrateTimeout <= 0 {
+		panic("db.migrateTimeout must be positive")
+	}
+	migrateDB(db, cmd, l, time.Duration(cfg.MigrateTimeout)*time.Second)
+
+	return db
+}
+
+// migrateLockTTL 迁移锁的过期时间，持有期间每 1/3 TTL 续期一次，实例崩溃后锁最多残留一个 TTL
+const migrateLockTTL = 30 * time.Second
+
+// 只有持有者（值与 token 相同）才能续期或释放迁移锁，避免锁过期后误删其他实例的锁
+var (
+	renewMigrateLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+	releaseMigrateLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+// migrateDB 多个实例同时启动时通过 redis 锁串行执行迁移，迁移失败时不启动服务。
+// 锁的值为本实例的随机 token，迁移期间持续续期，timeout 同时限制等待锁与执行迁移的时间
+func migrateDB(db *gorm.DB, cmd redis.Cmdable, l logger.Logger, timeout time.Duration) {
+	const lockKey = "db_migrate"
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		panic(fmt.Errorf("failed to generate db migration lock token: %w", err))
+	}
+	token := hex.EncodeToString(buf)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for {
+		ok, err := cmd.SetNX(ctx, lockKey, token, migrateLockTTL).Result()
+		if err != nil {
+			panic(fmt.Errorf("failed to acquire db migration lock: %w", err))
+		}
+		if ok {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			panic("timeout waiting for db migration lock")
+		case <-time.After(time.Second):
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(migrateLockTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				renewed, err := renewMigrateLockScript.Run(ctx, cmd, []string{lockKey}, token, migrateLockTTL.Milliseconds()).Int()
+				if err != nil {
+					l.Error("failed to renew db migration lock", logger.Error(err))
+				} else if renewed == 0 {
+					l.Error("db migration lock lost, another instance may be migrating concurrently")
+				}
 			}
 		}
+	}()
+	defer func() {
+		close(done)
+		if err := releaseMigrateLockScript.Run(context.Background(), cmd, []string{lockKey}, token).Err(); err != nil {
+			l.Error("failed to release db migration lock", logger.Error(err))
+		}
+	}()
+
+	start := time.Now()
+	if err := dao.Migrate(db.WithContext(ctx)); err != nil {
+		panic(fmt.Errorf("failed to migrate database: %w", err))
 	}
+	l.Info("database migration completed", logger.Int64("cost_ms", time.Since(start).Milliseconds()))
+}
 
-	return db
+func InitShortUrlDAO(db *gorm.DB, l logger.Logger) dao.ShortUrlDAO {
+	type Config struct {
+		BufferSize    int    `yaml:"bufferSize"`
+		BatchSize     int    `yaml:"batchSize"`
+		FlushInterval int64  `yaml:"flushInterval"`
+		Linger        int64  `yaml:"linger"`
+		Durability    string `yaml:"durability"`
+		WALPath       string `yaml:"walPath"`
+		WALRetry      int64  `yaml:"walRetry"`
+		Blocking      bool   `yaml:"blocking"`
+		MaxBlock      int64  `yaml:"maxBlock"`
+	}
+	cfg := Config{
+		BufferSize:    2000,
+		BatchSize:     1000,
+		FlushInterval: 50, // 单位 ms
+		Linger:        5,  // 单位 ms
+		Durability:    string(dao.DurabilityAck),
+		WALPath:       "./data/short_url.wal",
+		WALRetry:      1000, // 单位 ms
+		Blocking:      false,
+		MaxBlock:      100, // 单位 ms
+	}
+	if err := viper.UnmarshalKey("dao", &cfg); err != nil {
+		panic(err)
+	}
+	if cfg.BatchSize <= 0 || cfg.BufferSize <= cfg.BatchSize {
+		panic("dao must satisfy 0 < batchSize < bufferSize")
+	}
+	if cfg.FlushInterval <= 0 || cfg.WALRetry <= 0 || cfg.Linger < 0 || cfg.MaxBlock < 0 {
+		panic("dao.flushInterval and dao.walRetry must be positive, dao.linger and dao.maxBlock must not be negative")
+	}
+	switch dao.DurabilityMode(cfg.Durability) {
+	case dao.DurabilityAck:
+	case dao.DurabilityWAL:
+		if cfg.WALPath == "" {
+			panic("dao.walPath is required when dao.durability is wal")
+		}
+	default:
+		panic(fmt.Sprintf("unknown dao.durability: %s", cfg.Durability))
+	}
+
+	d := dao.NewGormShortUrlDAO(db, l, dao.BufferOptions{
+		BufferSize:    cfg.BufferSize,
+		BatchSize:     cfg.BatchSize,
+		FlushInterval: time.Duration(cfg.FlushInterval) * time.Millisecond,
+		Linger:        time.Duration(cfg.Linger) * time.Millisecond,
+		Durability:    dao.DurabilityMode(cfg.Durability),
+		WALPath:       cfg.WALPath,
+		WALRetry:      time.Duration(cfg.WALRetry) * time.Millisecond,
+		Blocking:      cfg.Blocking,
+		MaxBlock:      time.Duration(cfg.MaxBlock) * time.Millisecond,
+	})
+
+	// 通过 expvar 暴露缓冲区占用情况，由 metrics 服务的 /debug/vars 输出
+	if p, ok := d.(dao.BufferStatsProvider); ok {
+		expvar.Publish("short_url_dao_buffer", expvar.Func(func() any {
+			return p.BufferStats()
+		}))
+	}
+	return d
 }
 
 type gormLoggerFunc func(msg string, fields ...logger.Field)
@@ -0,0 +1,41 @@
+package ioc
+
+import (
+	"fmt"
+	"os"
+	"short_url/pkg/election"
+
+	"github.com/spf13/viper"
+	"github.com/to404hanga/pkg404/logger"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// InitElector 初始化定时任务的 leader 选举，关闭选举时本实例始终为 leader
+func InitElector(ecli *clientv3.Client, l logger.Logger) election.Elector {
+	type Config struct {
+		Enabled bool   `yaml:"enabled"`
+		Prefix  string `yaml:"prefix"`
+		TTL     int    `yaml:"ttl"`
+	}
+	cfg := Config{
+		Enabled: true,
+		Prefix:  "/short_url/job_leader",
+		TTL:     10, // 单位 秒
+	}
+	if err := viper.UnmarshalKey("election", &cfg); err != nil {
+		panic(err)
+	}
+	if !cfg.Enabled {
+		return election.NewMemoryElectionGroup().NewElector()
+	}
+	if cfg.TTL <= 0 {
+		panic("election.ttl must be positive")
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		panic(err)
+	}
+	id := fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	return election.NewEtcdElector(ecli, cfg.Prefix, cfg.TTL, id, l)
+}
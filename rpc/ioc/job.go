@@ -1,6 +1,9 @@
 package ioc
 
 import (
+	"expvar"
+	"fmt"
+	"short_url/pkg/election"
 	"short_url/rpc/job"
 	"short_url/rpc/service"
 	"time"
@@ -10,23 +13,58 @@ import (
 	"github.com/to404hanga/pkg404/logger"
 )
 
-func InitCleanerJob(svc service.ShortUrlService) job.Job {
-	timeout := viper.GetInt("job.timeout")
-	if timeout <= 0 {
-		panic("job.timeout must be positive")
+// InitJobList 返回所有可调度的定时任务，是否注册与执行由 job.jobs.<name> 配置决定
+func InitJobList(svc service.ShortUrlService, stat service.ClickStatService) []job.Job {
+	return []job.Job{
+		job.NewCleanerJob(svc),
+		job.NewBloomRebuildJob(svc),
+		job.NewVisitorRetentionJob(stat),
 	}
-	return job.NewCleanerJob(svc, time.Duration(timeout)*time.Second)
 }
 
-func InitJobs(l logger.Logger, j job.Job) *cron.Cron {
-	expr := viper.GetString("job.expr")
-	if expr == "" {
-		panic("job.expr must be set")
+func InitJobs(l logger.Logger, elector election.Elector, jobs []job.Job) *cron.Cron {
+	type Config struct {
+		Expr    string `yaml:"expr"`
+		Timeout int64  `yaml:"timeout"` // 单位 秒
+		Enabled bool   `yaml:"enabled"`
 	}
-	builder := job.NewCronJobBuilder(l)
-	c := cron.New(cron.WithSeconds())
-	if _, err := c.AddJob(expr, builder.Build(j)); err != nil {
+	var cfgs map[string]Config
+	if err := viper.UnmarshalKey("job.jobs", &cfgs); err != nil {
 		panic(err)
 	}
+
+	builder := job.NewCronJobBuilder(l, elector)
+	c := cron.New(cron.WithSeconds(), cron.WithChain(
+		// 上一次执行尚未结束时跳过本次调度
+		cron.SkipIfStillRunning(cron.DefaultLogger),
+	))
+	for _, j := range jobs {
+		name := j.Name()
+		cfg, ok := cfgs[name]
+		if !ok {
+			l.Warn("job not configured, skipped", logger.String("name", name))
+			continue
+		}
+		if cfg.Expr == "" {
+			panic(fmt.Sprintf("job.jobs.%s.expr must be set", name))
+		}
+		if cfg.Timeout <= 0 {
+			panic(fmt.Sprintf("job.jobs.%s.timeout must be positive", name))
+		}
+
+		// enabled 每次执行前从配置读取，支持热更新开关
+		enabledKey := "job.jobs." + name + ".enabled"
+		cj := builder.Build(j, cfg.Expr, time.Duration(cfg.Timeout)*time.Second, func() bool {
+			return viper.GetBool(enabledKey)
+		})
+		if _, err := c.AddJob(cfg.Expr, cj); err != nil {
+			panic(fmt.Errorf("invalid job.jobs.%s.expr: %w", name, err))
+		}
+	}
+
+	// 通过 expvar 暴露各任务最近一次的执行情况
+	expvar.Publish("short_url_jobs", expvar.Func(func() any {
+		return builder.Statuses()
+	}))
 	return c
 }
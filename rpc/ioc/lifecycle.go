@@ -0,0 +1,25 @@
+package ioc
+
+import (
+	"short_url/pkg/lifecycle"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/to404hanga/pkg404/logger"
+)
+
+func InitLifecycle(l logger.Logger) *lifecycle.Manager {
+	type Config struct {
+		Timeout int64 `yaml:"timeout"`
+	}
+	cfg := Config{
+		Timeout: 30, // 单位 秒
+	}
+	if err := viper.UnmarshalKey("shutdown", &cfg); err != nil {
+		panic(err)
+	}
+	if cfg.Timeout <= 0 {
+		panic("shutdown.timeout must be positive")
+	}
+	return lifecycle.NewManager(time.Duration(cfg.Timeout)*time.Second, l)
+}
@@ -0,0 +1,26 @@
+package ioc
+
+import (
+	_ "expvar" // 在 http.DefaultServeMux 上注册 /debug/vars
+	"net/http"
+
+	"github.com/spf13/viper"
+)
+
+// InitMetricsServer 初始化输出 expvar 指标的 http 服务，未配置地址时返回 nil
+func InitMetricsServer() *http.Server {
+	type Config struct {
+		Addr string `yaml:"addr"`
+	}
+	var cfg Config
+	if err := viper.UnmarshalKey("metrics", &cfg); err != nil {
+		panic(err)
+	}
+	if cfg.Addr == "" {
+		return nil
+	}
+	return &http.Server{
+		Addr:    cfg.Addr,
+		Handler: http.DefaultServeMux,
+	}
+}
@@ -1,6 +1,7 @@
 package ioc
 
 import (
+	"fmt"
 	"short_url/rpc/repository/cache"
 	"time"
 
@@ -24,3 +25,26 @@ func InitRedisCache(cmd redis.Cmdable) cache.ShortUrlCache {
 	expiration := time.Duration(cfg.Expiration) * time.Second
 	return cache.NewRedisShortUrlCache(cmd, cfg.Prefix, expiration)
 }
+
+// InitClickBudgetCache 初始化短链接点击预算计数器
+func InitClickBudgetCache(cmd redis.Cmdable) cache.ClickBudgetCache {
+	prefix := viper.GetString("redis.prefix")
+	if prefix == "" {
+		prefix = "short_url"
+	}
+	return cache.NewRedisClickBudgetCache(cmd, prefix)
+}
+
+// InitCacheInvalidator 初始化跨实例的本地缓存失效广播
+func InitCacheInvalidator(cmd redis.Cmdable) cache.CacheInvalidator {
+	channel := viper.GetString("lru.invalidateChannel")
+	if channel == "" {
+		channel = "short_url:lru_invalidate"
+	}
+
+	client, ok := cmd.(*redis.Client)
+	if !ok {
+		panic(fmt.Errorf("unsupported redis client type: %T", cmd))
+	}
+	return cache.NewRedisCacheInvalidator(client, channel)
+}
@@ -13,7 +13,7 @@ import (
 	"github.com/to404hanga/pkg404/logger"
 )
 
-func InitCachedRepository(cache cache.ShortUrlCache, bloomFilter cache.BloomFilterCache, dao dao.ShortUrlDAO, l logger.Logger) repository.ShortUrlRepository {
+func InitCachedRepository(cache cache.ShortUrlCache, bloomFilter cache.BloomFilterCache, invalidator cache.CacheInvalidator, budget cache.ClickBudgetCache, dao dao.ShortUrlDAO, l logger.Logger) repository.ShortUrlRepository {
 	type Config struct {
 		Size       int     `yaml:"size"`
 		Percentage float64 `yaml:"percentage"`
@@ -43,7 +43,7 @@ func InitCachedRepository(cache cache.ShortUrlCache, bloomFilter cache.BloomFilt
 	}
 
 	expiration := time.Duration(cfg.Expiration) * time.Second
-	return repository.NewCachedShortUrlRepository(cfg.Size, expiration, cache, bloomFilter, dao, l)
+	return repository.NewCachedShortUrlRepository(cfg.Size, expiration, cache, bloomFilter, invalidator, budget, dao, l)
 }
 
 // InitBloomFilterCache 初始化布隆过滤器缓存
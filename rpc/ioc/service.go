@@ -1,8 +1,11 @@
 package ioc
 
 import (
+	"short_url/rpc/domain"
 	"short_url/rpc/repository"
 	"short_url/rpc/service"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/to404hanga/pkg404/logger"
@@ -10,15 +13,25 @@ import (
 )
 
 func InitService(ecli *clientv3.Client, repo repository.ShortUrlRepository, l logger.Logger) service.ShortUrlService {
+	type BatchConfig struct {
+		MaxSize int `yaml:"maxSize"`
+	}
 	type Config struct {
-		Suffix string `yaml:"suffix"`
+		Suffix string      `yaml:"suffix"`
+		Batch  BatchConfig `yaml:"batch"`
 	}
 	cfg := &Config{
 		Suffix: "_TO404HANGA",
+		Batch: BatchConfig{
+			MaxSize: 1000,
+		},
 	}
 	if err := viper.UnmarshalKey("short_url", &cfg); err != nil {
 		panic(err)
 	}
+	if cfg.Batch.MaxSize <= 0 {
+		panic("short_url.batch.maxSize must be positive")
+	}
 
 	// // 获取 etcd 键值对 "weights"，并将其转换为 weights 切片
 	// ctx, cancel := context.WithTimeout(context.Background(), time.Second)
@@ -36,7 +49,7 @@ func InitService(ecli *clientv3.Client, repo repository.ShortUrlRepository, l lo
 	// 	}
 	// }
 	weights := viper.GetIntSlice("short_url.weights")
-	svc := service.NewCachedShortUrlService(repo, l, cfg.Suffix, weights)
+	svc := service.NewCachedShortUrlService(repo, l, cfg.Suffix, weights, initExpirationPolicy(), cfg.Batch.MaxSize, initUtmTemplates())
 
 	// // 监听 etcd 键值对的变化并更新 weights
 	// go func() {
@@ -59,3 +72,62 @@ func InitService(ecli *clientv3.Client, repo repository.ShortUrlRepository, l lo
 
 	return svc
 }
+
+func initExpirationPolicy() service.ExpirationPolicy {
+	type Config struct {
+		Default    int64 `yaml:"default"`
+		Min        int64 `yaml:"min"`
+		Max        int64 `yaml:"max"`
+		AllowNever bool  `yaml:"allowNever"`
+	}
+	cfg := Config{
+		Default:    365 * 86400, // 默认有效期一年
+		Min:        60,
+		Max:        5 * 365 * 86400,
+		AllowNever: true,
+	}
+	if err := viper.UnmarshalKey("short_url.expiration", &cfg); err != nil {
+		panic(err)
+	}
+	if cfg.Min <= 0 || cfg.Max < cfg.Min || cfg.Default < cfg.Min || cfg.Default > cfg.Max {
+		panic("short_url.expiration must satisfy 0 < min <= default <= max")
+	}
+
+	return service.ExpirationPolicy{
+		Default:    time.Duration(cfg.Default) * time.Second,
+		Min:        time.Duration(cfg.Min) * time.Second,
+		Max:        time.Duration(cfg.Max) * time.Second,
+		AllowNever: cfg.AllowNever,
+	}
+}
+
+func initUtmTemplates() map[string]domain.Utm {
+	type Config struct {
+		Source   string `yaml:"source"`
+		Medium   string `yaml:"medium"`
+		Campaign string `yaml:"campaign"`
+		Term     string `yaml:"term"`
+		Content  string `yaml:"content"`
+	}
+	var cfg map[string]Config
+	if err := viper.UnmarshalKey("short_url.utmTemplates", &cfg); err != nil {
+		panic(err)
+	}
+
+	templates := make(map[string]domain.Utm, len(cfg))
+	for name, c := range cfg {
+		utm := domain.Utm{
+			Source:   c.Source,
+			Medium:   c.Medium,
+			Campaign: c.Campaign,
+			Term:     c.Term,
+			Content:  c.Content,
+		}
+		if utm.IsZero() {
+			panic("short_url.utmTemplates." + name + " must not be empty")
+		}
+		// viper 会将键名转为小写，这里再统一一次以防配置来自其他来源
+		templates[strings.ToLower(name)] = utm
+	}
+	return templates
+}
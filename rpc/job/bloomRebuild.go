@@ -0,0 +1,27 @@
+package job
+
+import (
+	"context"
+	"short_url/rpc/service"
+)
+
+// BloomRebuildJob 重建布隆过滤器，移除已过期和已删除短链接残留的位
+type BloomRebuildJob struct {
+	svc service.ShortUrlService
+}
+
+var _ Job = (*BloomRebuildJob)(nil)
+
+func NewBloomRebuildJob(svc service.ShortUrlService) Job {
+	return &BloomRebuildJob{
+		svc: svc,
+	}
+}
+
+func (j *BloomRebuildJob) Name() string {
+	return "bloom_rebuild"
+}
+
+func (j *BloomRebuildJob) Run(ctx context.Context) error {
+	return j.svc.RebuildBloomFilter(ctx)
+}
@@ -3,20 +3,18 @@ package job
 import (
 	"context"
 	"short_url/rpc/service"
-	"time"
 )
 
+// CleanerJob 清理过期短链接
 type CleanerJob struct {
-	svc     service.ShortUrlService
-	timeout time.Duration
+	svc service.ShortUrlService
 }
 
 var _ Job = (*CleanerJob)(nil)
 
-func NewCleanerJob(svc service.ShortUrlService, timeout time.Duration) Job {
+func NewCleanerJob(svc service.ShortUrlService) Job {
 	return &CleanerJob{
-		svc:     svc,
-		timeout: timeout,
+		svc: svc,
 	}
 }
 
@@ -24,18 +22,6 @@ func (j *CleanerJob) Name() string {
 	return "cleaner"
 }
 
-func (j *CleanerJob) Run() error {
-	ctx, cancel := context.WithTimeout(context.Background(), j.timeout)
-	defer cancel()
-	// 清理过期短链接
-	if err := j.svc.CleanExpired(ctx); err != nil {
-		return err
-	}
-
-	// 重建布隆过滤器
-	if err := j.svc.RebuildBloomFilter(ctx); err != nil {
-		return err
-	}
-
-	return nil
+func (j *CleanerJob) Run(ctx context.Context) error {
+	return j.svc.CleanExpired(ctx)
 }
@@ -1,43 +1,109 @@
 package job
 
 import (
+	"context"
+	"short_url/pkg/election"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/robfig/cron/v3"
-	"github.com/spf13/viper"
 	"github.com/to404hanga/pkg404/logger"
 )
 
 type CronJobBuilder struct {
-	l logger.Logger
+	l        logger.Logger
+	elector  election.Elector
+	mu       sync.RWMutex
+	statuses map[string]*Status
 }
 
-func NewCronJobBuilder(l logger.Logger) *CronJobBuilder {
+func NewCronJobBuilder(l logger.Logger, elector election.Elector) *CronJobBuilder {
 	return &CronJobBuilder{
-		l: l,
+		l:        l,
+		elector:  elector,
+		statuses: make(map[string]*Status),
 	}
 }
 
-func (b *CronJobBuilder) Build(job Job) cron.Job {
+// Build 将 Job 适配为 cron.Job，每次执行前检查 enabled 以及当前实例是否为 leader，
+// 执行时附带 timeout 并记录执行情况
+func (b *CronJobBuilder) Build(job Job, expr string, timeout time.Duration, enabled func() bool) cron.Job {
 	name := job.Name()
+	b.mu.Lock()
+	b.statuses[name] = &Status{Name: name, Expr: expr}
+	b.mu.Unlock()
+
 	return cronJobAdapterFunc(func() {
-		if viper.GetBool("job.enabled") {
-			start := time.Now()
-			b.l.Debug("Job started", logger.String("name", name))
-			err := job.Run()
+		if !enabled() {
+			b.l.Debug("Job disabled", logger.String("name", name))
+			b.update(name, func(s *Status) {
+				s.Skipped++
+			})
+			return
+		}
+		// 多副本部署时只有 leader 执行任务
+		if !b.elector.IsLeader() {
+			b.l.Debug("Job skipped, not leader", logger.String("name", name))
+			b.update(name, func(s *Status) {
+				s.NotLeader++
+			})
+			return
+		}
+
+		start := time.Now()
+		b.update(name, func(s *Status) {
+			s.Running = true
+			s.LastStart = start
+		})
+		b.l.Debug("Job started", logger.String("name", name))
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := job.Run(ctx)
+		cancel()
+
+		duration := time.Since(start).Milliseconds()
+		b.update(name, func(s *Status) {
+			s.Running = false
+			s.LastDurationMs = duration
+			s.Runs++
+			s.LastError = ""
 			if err != nil {
-				b.l.Error("Job failed", logger.String("name", name), logger.Error(err))
-			} else {
-				b.l.Debug("Job finished", logger.String("name", name))
+				s.Failures++
+				s.LastError = err.Error()
 			}
-			duration := time.Since(start).Milliseconds()
-			b.l.Info("Job duration", logger.String("name", name), logger.Int64("duration_ms", duration))
+		})
+		if err != nil {
+			b.l.Error("Job failed", logger.String("name", name), logger.Error(err))
 		} else {
-			b.l.Debug("Job disabled", logger.String("name", name))
+			b.l.Debug("Job finished", logger.String("name", name))
 		}
+		b.l.Info("Job duration", logger.String("name", name), logger.Int64("duration_ms", duration))
 	})
 }
 
+// Statuses 返回所有已注册任务的执行情况，按名称排序
+func (b *CronJobBuilder) Statuses() []Status {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	ret := make([]Status, 0, len(b.statuses))
+	for _, s := range b.statuses {
+		ret = append(ret, *s)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
+
+func (b *CronJobBuilder) update(name string, fn func(s *Status)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	fn(b.statuses[name])
+}
+
 type cronJobAdapterFunc func()
 
 func (f cronJobAdapterFunc) Run() {
@@ -0,0 +1,113 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"short_url/pkg/election"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/to404hanga/pkg404/logger"
+)
+
+type countJob struct {
+	runs atomic.Int64
+	err  error
+}
+
+func (j *countJob) Name() string {
+	return "count"
+}
+
+func (j *countJob) Run(ctx context.Context) error {
+	j.runs.Add(1)
+	return j.err
+}
+
+func TestCronJobBuilder_Build(t *testing.T) {
+	testCases := []struct {
+		name          string
+		enabled       bool
+		err           error
+		wantRuns      []int64 // 各副本上任务的执行次数
+		wantLastError string
+	}{
+		{
+			name:     "只有 leader 执行任务",
+			enabled:  true,
+			wantRuns: []int64{1, 0, 0},
+		},
+		{
+			name:     "任务禁用时均不执行",
+			enabled:  false,
+			wantRuns: []int64{0, 0, 0},
+		},
+		{
+			name:          "记录执行失败",
+			enabled:       true,
+			err:           errors.New("job failed"),
+			wantRuns:      []int64{1, 0, 0},
+			wantLastError: "job failed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			g := election.NewMemoryElectionGroup()
+			builders := make([]*CronJobBuilder, len(tc.wantRuns))
+			jobs := make([]*countJob, len(tc.wantRuns))
+			for i := range builders {
+				e := g.NewElector()
+				go e.Run(ctx)
+				// 保证第一个副本成为 leader
+				assert.Eventually(t, func() bool { return i > 0 || e.IsLeader() }, time.Second, time.Millisecond)
+
+				builders[i] = NewCronJobBuilder(logger.NewNopLogger(), e)
+				jobs[i] = &countJob{err: tc.err}
+			}
+
+			for i, b := range builders {
+				b.Build(jobs[i], "@every 1m", time.Second, func() bool { return tc.enabled }).Run()
+			}
+
+			for i, j := range jobs {
+				assert.Equal(t, tc.wantRuns[i], j.runs.Load())
+			}
+			assert.Equal(t, tc.wantLastError, builders[0].Statuses()[0].LastError)
+		})
+	}
+}
+
+func TestCronJobBuilder_Failover(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	g := election.NewMemoryElectionGroup()
+	leaderCtx, stopLeader := context.WithCancel(ctx)
+	leader, follower := g.NewElector(), g.NewElector()
+	go leader.Run(leaderCtx)
+	assert.Eventually(t, leader.IsLeader, time.Second, time.Millisecond)
+	go follower.Run(ctx)
+
+	leaderJob, followerJob := &countJob{}, &countJob{}
+	leaderCron := NewCronJobBuilder(logger.NewNopLogger(), leader).Build(leaderJob, "@every 1m", time.Second, func() bool { return true })
+	followerCron := NewCronJobBuilder(logger.NewNopLogger(), follower).Build(followerJob, "@every 1m", time.Second, func() bool { return true })
+
+	leaderCron.Run()
+	followerCron.Run()
+	assert.Equal(t, int64(1), leaderJob.runs.Load())
+	assert.Equal(t, int64(0), followerJob.runs.Load())
+
+	// leader 退出后由 follower 接替执行
+	stopLeader()
+	assert.Eventually(t, follower.IsLeader, time.Second, time.Millisecond)
+	leaderCron.Run()
+	followerCron.Run()
+	assert.Equal(t, int64(1), leaderJob.runs.Load())
+	assert.Equal(t, int64(1), followerJob.runs.Load())
+}
@@ -1,6 +1,26 @@
 package job
 
+import (
+	"context"
+	"time"
+)
+
 type Job interface {
 	Name() string
-	Run() error
+	// Run 执行任务，ctx 携带该任务配置的超时时间
+	Run(ctx context.Context) error
+}
+
+// Status 定时任务的执行情况
+type Status struct {
+	Name           string    `json:"name"`
+	Expr           string    `json:"expr"`
+	Running        bool      `json:"running"`
+	LastStart      time.Time `json:"last_start"`
+	LastDurationMs int64     `json:"last_duration_ms"`
+	LastError      string    `json:"last_error"` // 最近一次执行成功时为空
+	Runs           int64     `json:"runs"`
+	Failures       int64     `json:"failures"`
+	Skipped        int64     `json:"skipped"`    // 因禁用而跳过的次数
+	NotLeader      int64     `json:"not_leader"` // 因当前实例不是 leader 而跳过的次数
 }
@@ -0,0 +1,27 @@
+package job
+
+import (
+	"context"
+	"short_url/rpc/service"
+)
+
+// VisitorRetentionJob 删除超过保留期的独立访客 HyperLogLog
+type VisitorRetentionJob struct {
+	svc service.ClickStatService
+}
+
+var _ Job = (*VisitorRetentionJob)(nil)
+
+func NewVisitorRetentionJob(svc service.ClickStatService) Job {
+	return &VisitorRetentionJob{
+		svc: svc,
+	}
+}
+
+func (j *VisitorRetentionJob) Name() string {
+	return "visitor_retention"
+}
+
+func (j *VisitorRetentionJob) Run(ctx context.Context) error {
+	return j.svc.CleanUniqueVisitors(ctx)
+}
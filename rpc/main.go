@@ -1,17 +1,98 @@
 package main
 
 import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"short_url/pkg/lifecycle"
+
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
-	"path/filepath"
 )
 
 func main() {
 	initViperWatch()
 	app := Init()
-	err := app.GrpcServer.Serve()
-	if err != nil {
-		panic(err)
+
+	errCh := make(chan error, 2)
+	if app.MetricsServer != nil {
+		go func() {
+			if err := app.MetricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("metrics server stopped: %v", err)
+			}
+		}()
+	}
+	go func() {
+		if err := app.GrpcServer.Serve(); err != nil {
+			errCh <- err
+		}
+	}()
+
+	electionCtx, stopElection := context.WithCancel(context.Background())
+	electionDone := make(chan struct{})
+	go func() {
+		defer close(electionDone)
+		app.Elector.Run(electionCtx)
+	}()
+	app.Cron.Start()
+
+	registerShutdownHooks(app, func(ctx context.Context) error {
+		stopElection()
+		select {
+		case <-electionDone:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	if err := app.Lifecycle.Wait(errCh); err != nil {
+		log.Printf("shutdown finished with error: %v", err)
+	}
+}
+
+// registerShutdownHooks 按依赖顺序注册关闭钩子：
+// 先等待进行中的定时任务并让出 leader，再从 etcd 注销并停止接收 gRPC 请求，最后刷新 DAO 写入缓冲区
+func registerShutdownHooks(app *App, resign lifecycle.Hook) {
+	app.Lifecycle.Register("cron", func(ctx context.Context) error {
+		select {
+		case <-app.Cron.Stop().Done():
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	// 需在 grpc 关闭 etcd 客户端之前撤销选举租约，其他实例才能立即接替
+	app.Lifecycle.Register("election", resign)
+	app.Lifecycle.Register("grpc", func(ctx context.Context) error {
+		done := make(chan error, 1)
+		go func() {
+			// Close 会注销 etcd 节点、关闭 etcd 客户端并 GracefulStop
+			err := app.GrpcServer.Close()
+			if err != nil {
+				// 注销失败时 Close 会提前返回，仍需停止服务
+				app.GrpcServer.GracefulStop()
+			}
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			return err
+		case <-ctx.Done():
+			// 超时后强制断开剩余连接
+			app.GrpcServer.Stop()
+			return ctx.Err()
+		}
+	})
+	app.Lifecycle.Register("dao", func(ctx context.Context) error {
+		if c, ok := app.DAO.(io.Closer); ok {
+			return c.Close()
+		}
+		return nil
+	})
+	if app.MetricsServer != nil {
+		app.Lifecycle.Register("metrics", app.MetricsServer.Shutdown)
 	}
 }
 
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"short_url/rpc/domain"
+	"short_url/rpc/repository/cache"
+	"short_url/rpc/repository/dao"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/to404hanga/pkg404/logger"
+)
+
+type CachedApiKeyRepository struct {
+	dao   dao.ApiKeyDAO
+	cache cache.ApiKeyCache
+	l     logger.Logger
+	// expiration 存在的 Key 的缓存时长，禁用或轮换密钥后最多经过该时长生效
+	expiration time.Duration
+	// missExpiration 不存在的 Key 的缓存时长，避免随机 Key 的请求全部落到数据库
+	missExpiration time.Duration
+}
+
+var _ ApiKeyRepository = (*CachedApiKeyRepository)(nil)
+
+func NewCachedApiKeyRepository(dao dao.ApiKeyDAO, cache cache.ApiKeyCache, l logger.Logger, expiration, missExpiration time.Duration) ApiKeyRepository {
+	return &CachedApiKeyRepository{
+		dao:            dao,
+		cache:          cache,
+		l:              l,
+		expiration:     expiration,
+		missExpiration: missExpiration,
+	}
+}
+
+// cachedApiKey redis 缓存中保存的 API Key，编码为 JSON
+type cachedApiKey struct {
+	Secret   string `json:"secret"`
+	Name     string `json:"name,omitempty"`
+	Disabled bool   `json:"disabled,omitempty"`
+}
+
+func (r *CachedApiKeyRepository) FindByKeyID(ctx context.Context, keyID string) (domain.ApiKey, error) {
+	val, err := r.cache.Get(ctx, keyID)
+	switch {
+	case err == nil:
+		if val == "" {
+			return domain.ApiKey{}, ErrDataNotFound
+		}
+		var c cachedApiKey
+		if err := json.Unmarshal([]byte(val), &c); err == nil {
+			return domain.ApiKey{KeyID: keyID, Secret: c.Secret, Name: c.Name, Disabled: c.Disabled}, nil
+		}
+		r.l.Error("failed to decode cached api key", logger.String("key_id", keyID))
+	case !errors.Is(err, redis.Nil):
+		// redis 不可用时直接查询数据库
+		r.l.Error("cache.Get failed",
+			logger.Error(err),
+			logger.String("key_id", keyID),
+		)
+	}
+
+	k, err := r.dao.FindByKeyID(ctx, keyID)
+	if errors.Is(err, dao.ErrDataNotFound) {
+		if err := r.cache.Set(ctx, keyID, "", r.missExpiration); err != nil {
+			r.l.Error("failed to set redis cache", logger.Error(err), logger.String("key_id", keyID))
+		}
+		return domain.ApiKey{}, ErrDataNotFound
+	}
+	if err != nil {
+		return domain.ApiKey{}, err
+	}
+
+	ak := domain.ApiKey{KeyID: k.KeyID, Secret: k.Secret, Name: k.Name, Disabled: k.Disabled}
+	b, _ := json.Marshal(cachedApiKey{Secret: ak.Secret, Name: ak.Name, Disabled: ak.Disabled})
+	if err := r.cache.Set(ctx, keyID, string(b), r.expiration); err != nil {
+		r.l.Error("failed to set redis cache", logger.Error(err), logger.String("key_id", keyID))
+	}
+	return ak, nil
+}
@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type RedisApiKeyCache struct {
+	cmd    redis.Cmdable
+	prefix string
+}
+
+var _ ApiKeyCache = (*RedisApiKeyCache)(nil)
+
+func NewRedisApiKeyCache(cmd redis.Cmdable, prefix string) ApiKeyCache {
+	return &RedisApiKeyCache{
+		cmd:    cmd,
+		prefix: prefix,
+	}
+}
+
+func (r *RedisApiKeyCache) Get(ctx context.Context, keyID string) (string, error) {
+	return r.cmd.Get(ctx, r.key(keyID)).Result()
+}
+
+func (r *RedisApiKeyCache) Set(ctx context.Context, keyID string, val string, ttl time.Duration) error {
+	return r.cmd.Set(ctx, r.key(keyID), val, ttl).Err()
+}
+
+func (r *RedisApiKeyCache) Del(ctx context.Context, keyID string) error {
+	return r.cmd.Del(ctx, r.key(keyID)).Err()
+}
+
+func (r *RedisApiKeyCache) key(keyID string) string {
+	return r.prefix + ":api_key:" + keyID
+}
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+	"embed"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+//go:embed scripts/*.lua
+var luaScripts embed.FS
+
+// 预加载的Lua脚本
+var clickBudgetScript = redis.NewScript(mustReadScript("scripts/click_budget.lua"))
+
+func mustReadScript(name string) string {
+	src, err := luaScripts.ReadFile(name)
+	if err != nil {
+		panic(err)
+	}
+	return string(src)
+}
+
+// RedisClickBudgetCache 使用 lua 脚本原子扣减点击预算，多个实例并发跳转时不会超出预算
+type RedisClickBudgetCache struct {
+	cmd    redis.Cmdable
+	prefix string
+}
+
+var _ ClickBudgetCache = (*RedisClickBudgetCache)(nil)
+
+func NewRedisClickBudgetCache(cmd redis.Cmdable, prefix string) ClickBudgetCache {
+	return &RedisClickBudgetCache{
+		cmd:    cmd,
+		prefix: prefix,
+	}
+}
+
+func (r *RedisClickBudgetCache) Reset(ctx context.Context, shortUrl string, budget int64, ttl time.Duration) error {
+	return r.cmd.Set(ctx, r.key(shortUrl), budget, ttl).Err()
+}
+
+func (r *RedisClickBudgetCache) Consume(ctx context.Context, shortUrl string) (int64, error) {
+	return clickBudgetScript.Run(ctx, r.cmd, []string{r.key(shortUrl)}).Int64()
+}
+
+func (r *RedisClickBudgetCache) Expire(ctx context.Context, shortUrl string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return r.cmd.Persist(ctx, r.key(shortUrl)).Err()
+	}
+	return r.cmd.PExpire(ctx, r.key(shortUrl), ttl).Err()
+}
+
+func (r *RedisClickBudgetCache) Del(ctx context.Context, shortUrl string) error {
+	return r.cmd.Del(ctx, r.key(shortUrl)).Err()
+}
+
+func (r *RedisClickBudgetCache) key(shortUrl string) string {
+	return r.prefix + ":budget:" + shortUrl
+}
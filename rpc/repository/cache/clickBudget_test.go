@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisClickBudgetCache_Consume(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := NewRedisClickBudgetCache(redis.NewClient(&redis.Options{Addr: mr.Addr()}), "short_url")
+	ctx := context.Background()
+
+	testCases := []struct {
+		name string
+		// before 在扣减前执行
+		before        func(t *testing.T)
+		shortUrl      string
+		wantRemaining int64
+	}{
+		{
+			name: "创建后第一次点击",
+			before: func(t *testing.T) {
+				require.NoError(t, c.Reset(ctx, "once", 2, time.Hour))
+			},
+			shortUrl:      "once",
+			wantRemaining: 1,
+		},
+		{name: "最后一次点击", shortUrl: "once", wantRemaining: 0},
+		{name: "预算已耗尽", shortUrl: "once", wantRemaining: -1},
+		{name: "耗尽后不再递减", shortUrl: "once", wantRemaining: -1},
+		{
+			name: "计数器丢失时按已耗尽处理",
+			before: func(t *testing.T) {
+				require.NoError(t, c.Reset(ctx, "flushed", 1, time.Hour))
+				mr.FlushAll()
+			},
+			shortUrl:      "flushed",
+			wantRemaining: -1,
+		},
+		{
+			name: "计数器过期后按已耗尽处理",
+			before: func(t *testing.T) {
+				require.NoError(t, c.Reset(ctx, "expired", 1, time.Minute))
+				mr.FastForward(2 * time.Minute)
+			},
+			shortUrl:      "expired",
+			wantRemaining: -1,
+		},
+		{name: "从未创建的计数器", shortUrl: "missing", wantRemaining: -1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.before != nil {
+				tc.before(t)
+			}
+			remaining, err := c.Consume(ctx, tc.shortUrl)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantRemaining, remaining)
+		})
+	}
+	// 预算耗尽后计数器不会被重新初始化
+	assert.False(t, mr.Exists("short_url:budget:missing"))
+}
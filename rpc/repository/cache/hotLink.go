@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisHotLinkCache 每个时间桶一个有序集合，成员为短链接，分值为点击数。
+// 查询时合并窗口内的时间桶并缓存合并结果，所有 key 使用同一个 hash tag 以支持集群模式下的 ZUNIONSTORE
+type RedisHotLinkCache struct {
+	cmd        redis.Cmdable
+	prefix     string
+	maxMembers int64         // 单个时间桶保留的最大成员数，超出时淘汰点击数最少的成员
+	resultTTL  time.Duration // 合并结果的缓存时长
+}
+
+var _ HotLinkCache = (*RedisHotLinkCache)(nil)
+
+func NewRedisHotLinkCache(cmd redis.Cmdable, prefix string, maxMembers int64, resultTTL time.Duration) HotLinkCache {
+	return &RedisHotLinkCache{
+		cmd:        cmd,
+		prefix:     prefix,
+		maxMembers: maxMembers,
+		resultTTL:  resultTTL,
+	}
+}
+
+func (r *RedisHotLinkCache) Incr(ctx context.Context, counts []HotLinkCount) error {
+	if len(counts) == 0 {
+		return nil
+	}
+	pipe := r.cmd.Pipeline()
+	buckets := make(map[string]time.Duration)
+	for _, c := range counts {
+		key := r.bucketKey(c.BucketSize, c.Bucket)
+		pipe.ZIncrBy(ctx, key, float64(c.Clicks), c.ShortUrl)
+		if c.Retention > buckets[key] {
+			buckets[key] = c.Retention
+		}
+	}
+	for key, retention := range buckets {
+		// 只保留点击数最多的 maxMembers 个成员，控制长尾链接占用的内存
+		pipe.ZRemRangeByRank(ctx, key, 0, -r.maxMembers-1)
+		pipe.Expire(ctx, key, retention)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *RedisHotLinkCache) Top(ctx context.Context, bucketSize time.Duration, start, end int64, n int) ([]HotLinkCount, error) {
+	size := int64(bucketSize / time.Second)
+	resultKey := r.resultKey(bucketSize, start, end)
+
+	exists, err := r.cmd.Exists(ctx, resultKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	if exists == 0 {
+		keys := make([]string, 0, (end-start)/size)
+		for bucket := start; bucket < end; bucket += size {
+			keys = append(keys, r.bucketKey(bucketSize, bucket))
+		}
+		pipe := r.cmd.TxPipeline()
+		pipe.ZUnionStore(ctx, resultKey, &redis.ZStore{Keys: keys})
+		pipe.Expire(ctx, resultKey, r.resultTTL)
+		if _, err = pipe.Exec(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	zs, err := r.cmd.ZRevRangeWithScores(ctx, resultKey, 0, int64(n)-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	links := make([]HotLinkCount, 0, len(zs))
+	for _, z := range zs {
+		shortUrl, _ := z.Member.(string)
+		links = append(links, HotLinkCount{
+			ShortUrl:   shortUrl,
+			BucketSize: bucketSize,
+			Clicks:     int64(z.Score),
+		})
+	}
+	return links, nil
+}
+
+func (r *RedisHotLinkCache) bucketKey(bucketSize time.Duration, bucket int64) string {
+	return r.prefix + ":{hot}:" + strconv.FormatInt(int64(bucketSize/time.Second), 10) + ":" + strconv.FormatInt(bucket, 10)
+}
+
+func (r *RedisHotLinkCache) resultKey(bucketSize time.Duration, start, end int64) string {
+	return r.prefix + ":{hot}:top:" + strconv.FormatInt(int64(bucketSize/time.Second), 10) + ":" +
+		strconv.FormatInt(start, 10) + ":" + strconv.FormatInt(end, 10)
+}
@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCacheInvalidator 基于 Redis Pub/Sub 的本地缓存失效广播
+type RedisCacheInvalidator struct {
+	client  *redis.Client
+	channel string
+}
+
+var _ CacheInvalidator = (*RedisCacheInvalidator)(nil)
+
+func NewRedisCacheInvalidator(client *redis.Client, channel string) CacheInvalidator {
+	return &RedisCacheInvalidator{
+		client:  client,
+		channel: channel,
+	}
+}
+
+func (r *RedisCacheInvalidator) Publish(ctx context.Context, shortUrl string) error {
+	return r.client.Publish(ctx, r.channel, shortUrl).Err()
+}
+
+func (r *RedisCacheInvalidator) Subscribe(ctx context.Context, fn func(shortUrl string)) error {
+	pubsub := r.client.Subscribe(ctx, r.channel)
+	defer pubsub.Close()
+
+	// 等待订阅确认，确保订阅成功后再返回消息
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return err
+	}
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			fn(msg.Payload)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+local budgetKey = KEYS[1]
+
+-- 计数器在创建短链接时写入。计数器缺失（写入失败、被淘汰或 redis 数据丢失）时无法确认剩余次数，
+-- 按预算已耗尽处理，避免一次性链接在 redis 清空后重新可用
+local remaining = tonumber(redis.call('GET', budgetKey))
+if not remaining or remaining <= 0 then
+    return -1
+end
+
+return redis.call('DECR', budgetKey)
@@ -24,12 +24,12 @@ func NewRedisShortUrlCache(cmd redis.Cmdable, prefix string, expiration time.Dur
 	}
 }
 
-func (r *RedisShortUrlCache) Get(ctx context.Context, shortUrl string) (originUrl string, err error) {
+func (r *RedisShortUrlCache) Get(ctx context.Context, shortUrl string) (val string, err error) {
 	return r.cmd.Get(ctx, r.key(shortUrl)).Result()
 }
 
-func (r *RedisShortUrlCache) Set(ctx context.Context, shortUrl string, originUrl string) error {
-	_, err := r.cmd.Set(ctx, r.key(shortUrl), originUrl, r.expiration+time.Duration(rand.IntN(7201)-3600)).Result() // 随机加减一小时过期时间
+func (r *RedisShortUrlCache) Set(ctx context.Context, shortUrl string, val string) error {
+	_, err := r.cmd.Set(ctx, r.key(shortUrl), val, r.expiration+time.Duration(rand.IntN(7201)-3600)).Result() // 随机加减一小时过期时间
 	return err
 }
 
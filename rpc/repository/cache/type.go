@@ -1,14 +1,38 @@
 package cache
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type ShortUrlCache interface {
-	Get(ctx context.Context, shortUrl string) (originUrl string, err error)
-	Set(ctx context.Context, shortUrl string, originUrl string) error
+	Get(ctx context.Context, shortUrl string) (val string, err error)
+	Set(ctx context.Context, shortUrl string, val string) error
 	Del(ctx context.Context, shortUrl string) error
 	Refresh(ctx context.Context, shortUrl string) error
 }
 
+// ApiKeyCache API Key 的 redis 缓存，值由 repository 编码
+type ApiKeyCache interface {
+	// Get 返回缓存的值，未缓存时返回 redis.Nil
+	Get(ctx context.Context, keyID string) (string, error)
+	// Set 缓存 ttl 时长，val 为空字符串表示 Key 不存在
+	Set(ctx context.Context, keyID string, val string, ttl time.Duration) error
+	Del(ctx context.Context, keyID string) error
+}
+
+// ClickBudgetCache 短链接的剩余点击次数，计数器过期时间不早于短链接本身，ttl 为 0 表示不过期
+type ClickBudgetCache interface {
+	// Reset 将剩余点击次数重置为 budget，创建短链接时调用
+	Reset(ctx context.Context, shortUrl string, budget int64, ttl time.Duration) error
+	// Consume 原子扣减一次点击，返回扣减后的剩余次数。
+	// 预算已耗尽或计数器不存在时返回 -1，计数器只由 Reset 创建
+	Consume(ctx context.Context, shortUrl string) (int64, error)
+	// Expire 调整计数器的过期时间，计数器不存在时不做任何操作
+	Expire(ctx context.Context, shortUrl string, ttl time.Duration) error
+	Del(ctx context.Context, shortUrl string) error
+}
+
 // BloomFilterCache 布隆过滤器缓存接口
 type BloomFilterCache interface {
 	// Exist 检查短链接是否可能存在
@@ -22,3 +46,47 @@ type BloomFilterCache interface {
 	// IsInitialized 检查布隆过滤器是否已经初始化
 	IsInitialized(ctx context.Context) (bool, error)
 }
+
+// CacheInvalidator 本地缓存失效广播接口，用于在多个 rpc 实例之间同步 lru 缓存
+type CacheInvalidator interface {
+	// Publish 广播短链接缓存失效消息
+	Publish(ctx context.Context, shortUrl string) error
+	// Subscribe 订阅缓存失效消息并回调 fn，直到 ctx 被取消
+	Subscribe(ctx context.Context, fn func(shortUrl string)) error
+}
+
+// UniqueVisitorCache 基于 HyperLogLog 的每日独立访客计数
+type UniqueVisitorCache interface {
+	// Add 将访客指纹加入对应短链接与日期的 HyperLogLog
+	Add(ctx context.Context, visitors []DailyVisitors) error
+	// Count 分别返回 days 中每一天的独立访客数
+	Count(ctx context.Context, shortUrl string, days []int64) ([]int64, error)
+	// CountUnion 返回 days 合并后的独立访客数，同一访客在多天访问只计一次
+	CountUnion(ctx context.Context, shortUrl string, days []int64) (int64, error)
+	// Expire 删除日期早于 before 的全部计数，返回删除的 key 数量
+	Expire(ctx context.Context, before int64) (int, error)
+}
+
+// DailyVisitors 某短链接在某一天的访客指纹
+type DailyVisitors struct {
+	ShortUrl     string
+	Day          int64 // 当天 0 点的时间戳（秒，UTC）
+	Fingerprints []string
+}
+
+// HotLinkCache 基于有序集合与时间桶的热门链接统计
+type HotLinkCache interface {
+	// Incr 将点击数累加到对应时间桶
+	Incr(ctx context.Context, counts []HotLinkCount) error
+	// Top 合并 [start, end) 内的时间桶，返回点击数最多的 n 个短链接
+	Top(ctx context.Context, bucketSize time.Duration, start, end int64, n int) ([]HotLinkCount, error)
+}
+
+// HotLinkCount 短链接在某个时间桶内的点击数
+type HotLinkCount struct {
+	ShortUrl   string
+	BucketSize time.Duration
+	Bucket     int64         // 时间桶起始时间戳（秒）
+	Retention  time.Duration // 时间桶的保留时长，不短于使用该时间桶的窗口
+	Clicks     int64
+}
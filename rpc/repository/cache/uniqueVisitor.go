@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 清理过期计数时单次 SSCAN 与 DEL 处理的 key 数量
+const uvExpireBatch = 500
+
+// RedisUniqueVisitorCache 每个短链接每天一个 HyperLogLog，
+// 同时按天记录写入过的短链接索引，供保留期清理使用，避免对全库执行 SCAN
+type RedisUniqueVisitorCache struct {
+	cmd    redis.Cmdable
+	prefix string
+}
+
+var _ UniqueVisitorCache = (*RedisUniqueVisitorCache)(nil)
+
+func NewRedisUniqueVisitorCache(cmd redis.Cmdable, prefix string) UniqueVisitorCache {
+	return &RedisUniqueVisitorCache{
+		cmd:    cmd,
+		prefix: prefix,
+	}
+}
+
+func (r *RedisUniqueVisitorCache) Add(ctx context.Context, visitors []DailyVisitors) error {
+	if len(visitors) == 0 {
+		return nil
+	}
+	pipe := r.cmd.Pipeline()
+	for _, v := range visitors {
+		if len(v.Fingerprints) == 0 {
+			continue
+		}
+		elements := make([]any, 0, len(v.Fingerprints))
+		for _, fp := range v.Fingerprints {
+			elements = append(elements, fp)
+		}
+		pipe.PFAdd(ctx, r.key(v.ShortUrl, v.Day), elements...)
+		pipe.SAdd(ctx, r.indexKey(v.Day), v.ShortUrl)
+		pipe.ZAdd(ctx, r.daysKey(), redis.Z{Score: float64(v.Day), Member: v.Day})
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *RedisUniqueVisitorCache) Count(ctx context.Context, shortUrl string, days []int64) ([]int64, error) {
+	if len(days) == 0 {
+		return nil, nil
+	}
+	pipe := r.cmd.Pipeline()
+	cmds := make([]*redis.IntCmd, 0, len(days))
+	for _, day := range days {
+		cmds = append(cmds, pipe.PFCount(ctx, r.key(shortUrl, day)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	counts := make([]int64, 0, len(days))
+	for _, cmd := range cmds {
+		counts = append(counts, cmd.Val())
+	}
+	return counts, nil
+}
+
+func (r *RedisUniqueVisitorCache) CountUnion(ctx context.Context, shortUrl string, days []int64) (int64, error) {
+	if len(days) == 0 {
+		return 0, nil
+	}
+	keys := make([]string, 0, len(days))
+	for _, day := range days {
+		keys = append(keys, r.key(shortUrl, day))
+	}
+	return r.cmd.PFCount(ctx, keys...).Result()
+}
+
+func (r *RedisUniqueVisitorCache) Expire(ctx context.Context, before int64) (int, error) {
+	days, err := r.cmd.ZRangeByScore(ctx, r.daysKey(), &redis.ZRangeBy{
+		Min: "-inf",
+		Max: "(" + strconv.FormatInt(before, 10),
+	}).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, d := range days {
+		day, err := strconv.ParseInt(d, 10, 64)
+		if err != nil {
+			// 非法成员直接移除，避免每次清理都报错
+			r.cmd.ZRem(ctx, r.daysKey(), d)
+			continue
+		}
+		n, err := r.expireDay(ctx, day)
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+		if err = r.cmd.ZRem(ctx, r.daysKey(), d).Err(); err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
+
+// expireDay 删除某一天的全部 HyperLogLog 及其索引
+func (r *RedisUniqueVisitorCache) expireDay(ctx context.Context, day int64) (int, error) {
+	indexKey := r.indexKey(day)
+	deleted := 0
+	var cursor uint64
+	for {
+		shortUrls, next, err := r.cmd.SScan(ctx, indexKey, cursor, "", uvExpireBatch).Result()
+		if err != nil {
+			return deleted, err
+		}
+		if len(shortUrls) > 0 {
+			// 使用 hash tag 后各 key 可能位于不同的 slot，逐个删除以兼容集群模式
+			pipe := r.cmd.Pipeline()
+			for _, shortUrl := range shortUrls {
+				pipe.Del(ctx, r.key(shortUrl, day))
+			}
+			if _, err = pipe.Exec(ctx); err != nil {
+				return deleted, err
+			}
+			deleted += len(shortUrls)
+		}
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+	return deleted, r.cmd.Del(ctx, indexKey).Err()
+}
+
+// key 使用 hash tag 让同一短链接各天的 HyperLogLog 落在同一个 slot，便于 PFCOUNT 合并计数
+func (r *RedisUniqueVisitorCache) key(shortUrl string, day int64) string {
+	return r.prefix + ":uv:{" + shortUrl + "}:" + strconv.FormatInt(day, 10)
+}
+
+func (r *RedisUniqueVisitorCache) indexKey(day int64) string {
+	return r.prefix + ":uv_index:" + strconv.FormatInt(day, 10)
+}
+
+func (r *RedisUniqueVisitorCache) daysKey() string {
+	return r.prefix + ":uv_days"
+}
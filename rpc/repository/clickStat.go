@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"short_url/rpc/domain"
+	"short_url/rpc/repository/cache"
+	"short_url/rpc/repository/dao"
+)
+
+type ClickStatRepositoryImpl struct {
+	dao      dao.ClickStatDAO
+	visitors cache.UniqueVisitorCache
+	hotLinks cache.HotLinkCache
+}
+
+var _ ClickStatRepository = (*ClickStatRepositoryImpl)(nil)
+
+func NewClickStatRepository(dao dao.ClickStatDAO, visitors cache.UniqueVisitorCache, hotLinks cache.HotLinkCache) ClickStatRepository {
+	return &ClickStatRepositoryImpl{
+		dao:      dao,
+		visitors: visitors,
+		hotLinks: hotLinks,
+	}
+}
+
+func (r *ClickStatRepositoryImpl) IncrClicks(ctx context.Context, counts []domain.ClickCount) error {
+	stats := make([]dao.ClickStat, 0, len(counts))
+	for _, c := range counts {
+		stats = append(stats, dao.ClickStat{
+			ShortUrl:    c.ShortUrl,
+			Granularity: int8(c.Granularity),
+			Bucket:      c.Bucket,
+			Clicks:      c.Clicks,
+		})
+	}
+	return r.dao.Incr(ctx, stats)
+}
+
+func (r *ClickStatRepositoryImpl) FindClickStats(ctx context.Context, shortUrl string, granularity domain.Granularity, start, end int64) ([]domain.ClickStatPoint, error) {
+	stats, err := r.dao.FindRange(ctx, shortUrl, int8(granularity), start, end)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]domain.ClickStatPoint, 0, len(stats))
+	for _, st := range stats {
+		points = append(points, domain.ClickStatPoint{
+			Bucket: st.Bucket,
+			Clicks: st.Clicks,
+		})
+	}
+	return points, nil
+}
+
+// TotalClicks 历史总点击数，按天聚合的行数远少于按小时聚合，使用天粒度求和
+func (r *ClickStatRepositoryImpl) TotalClicks(ctx context.Context, shortUrl string) (int64, error) {
+	return r.dao.SumClicks(ctx, shortUrl, int8(domain.GranularityDay))
+}
+
+func (r *ClickStatRepositoryImpl) AddUniqueVisitors(ctx context.Context, visitors []domain.DailyVisitors) error {
+	entries := make([]cache.DailyVisitors, 0, len(visitors))
+	for _, v := range visitors {
+		entries = append(entries, cache.DailyVisitors{
+			ShortUrl:     v.ShortUrl,
+			Day:          v.Day,
+			Fingerprints: v.Fingerprints,
+		})
+	}
+	return r.visitors.Add(ctx, entries)
+}
+
+func (r *ClickStatRepositoryImpl) UniqueVisitors(ctx context.Context, shortUrl string, days []int64) ([]int64, error) {
+	return r.visitors.Count(ctx, shortUrl, days)
+}
+
+func (r *ClickStatRepositoryImpl) RangeUniqueVisitors(ctx context.Context, shortUrl string, days []int64) (int64, error) {
+	return r.visitors.CountUnion(ctx, shortUrl, days)
+}
+
+func (r *ClickStatRepositoryImpl) CleanUniqueVisitors(ctx context.Context, before int64) (int, error) {
+	return r.visitors.Expire(ctx, before)
+}
+
+func (r *ClickStatRepositoryImpl) IncrHotLinks(ctx context.Context, counts []domain.HotLinkCount) error {
+	entries := make([]cache.HotLinkCount, 0, len(counts))
+	for _, c := range counts {
+		entries = append(entries, cache.HotLinkCount{
+			ShortUrl:   c.ShortUrl,
+			BucketSize: c.Window.BucketSize(),
+			Bucket:     c.Bucket,
+			// 多保留一个时间桶，窗口跨越桶边界时仍能取到完整数据
+			Retention: c.Window.Duration() + c.Window.BucketSize(),
+			Clicks:    c.Clicks,
+		})
+	}
+	return r.hotLinks.Incr(ctx, entries)
+}
+
+func (r *ClickStatRepositoryImpl) TopLinks(ctx context.Context, window domain.TopWindow, start, end int64, n int) ([]domain.LinkClicks, error) {
+	top, err := r.hotLinks.Top(ctx, window.BucketSize(), start, end, n)
+	if err != nil {
+		return nil, err
+	}
+	links := make([]domain.LinkClicks, 0, len(top))
+	for _, t := range top {
+		links = append(links, domain.LinkClicks{
+			ShortUrl: t.ShortUrl,
+			Clicks:   t.Clicks,
+		})
+	}
+	return links, nil
+}
+
+func (r *ClickStatRepositoryImpl) IncrCountryClicks(ctx context.Context, counts []domain.CountryClickCount) error {
+	stats := make([]dao.CountryClickStat, 0, len(counts))
+	for _, c := range counts {
+		stats = append(stats, dao.CountryClickStat{
+			ShortUrl: c.ShortUrl,
+			Day:      c.Day,
+			Country:  c.Country,
+			Clicks:   c.Clicks,
+		})
+	}
+	return r.dao.IncrCountries(ctx, stats)
+}
+
+func (r *ClickStatRepositoryImpl) FindCountryClicks(ctx context.Context, shortUrl string, start, end int64) ([]domain.CountryClicks, error) {
+	stats, err := r.dao.SumCountries(ctx, shortUrl, start, end)
+	if err != nil {
+		return nil, err
+	}
+	countries := make([]domain.CountryClicks, 0, len(stats))
+	for _, st := range stats {
+		countries = append(countries, domain.CountryClicks{
+			Country: st.Country,
+			Clicks:  st.Clicks,
+		})
+	}
+	return countries, nil
+}
+
+func (r *ClickStatRepositoryImpl) IncrVariantClicks(ctx context.Context, counts []domain.VariantClickCount) error {
+	stats := make([]dao.VariantClickStat, 0, len(counts))
+	for _, c := range counts {
+		stats = append(stats, dao.VariantClickStat{
+			ShortUrl: c.ShortUrl,
+			Day:      c.Day,
+			Variant:  c.Variant,
+			Clicks:   c.Clicks,
+		})
+	}
+	return r.dao.IncrVariants(ctx, stats)
+}
+
+func (r *ClickStatRepositoryImpl) FindVariantClicks(ctx context.Context, shortUrl string, start, end int64) ([]domain.VariantClicks, error) {
+	stats, err := r.dao.SumVariants(ctx, shortUrl, start, end)
+	if err != nil {
+		return nil, err
+	}
+	variants := make([]domain.VariantClicks, 0, len(stats))
+	for _, st := range stats {
+		variants = append(variants, domain.VariantClicks{
+			Variant: st.Variant,
+			Clicks:  st.Clicks,
+		})
+	}
+	return variants, nil
+}
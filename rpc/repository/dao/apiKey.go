@@ -0,0 +1,33 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type GormApiKeyDAO struct {
+	db *gorm.DB
+}
+
+var _ ApiKeyDAO = (*GormApiKeyDAO)(nil)
+
+func NewGormApiKeyDAO(db *gorm.DB) ApiKeyDAO {
+	return &GormApiKeyDAO{db: db}
+}
+
+func (g *GormApiKeyDAO) FindByKeyID(ctx context.Context, keyID string) (ApiKey, error) {
+	var k ApiKey
+	err := g.db.WithContext(ctx).Where("key_id = ?", keyID).First(&k).Error
+	return k, err
+}
+
+// ApiKey 调用 /api 接口的凭证，由运维通过 scripts/apikey 创建
+type ApiKey struct {
+	KeyID     string `gorm:"type:varchar(32) CHARACTER SET ascii COLLATE ascii_bin;not null;primaryKey;column:key_id"`
+	Secret    string `gorm:"type:varchar(128) CHARACTER SET ascii COLLATE ascii_bin;not null"`
+	Name      string `gorm:"type:varchar(64);not null;default:''"`
+	Disabled  bool   `gorm:"type:tinyint(1);not null;default:0"`
+	CreatedAt int64  `gorm:"type:bigint;not null;autoCreateTime"`
+	UpdatedAt int64  `gorm:"type:bigint;not null;autoUpdateTime"`
+}
@@ -0,0 +1,127 @@
+package dao
+
+import (
+	"context"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormClickStatDAO struct {
+	db *gorm.DB
+}
+
+var _ ClickStatDAO = (*GormClickStatDAO)(nil)
+
+func NewGormClickStatDAO(db *gorm.DB) ClickStatDAO {
+	return &GormClickStatDAO{db: db}
+}
+
+func (g *GormClickStatDAO) Incr(ctx context.Context, stats []ClickStat) error {
+	if len(stats) == 0 {
+		return nil
+	}
+	// 多个实例并发累加同一批行时按主键顺序加锁，降低死锁概率
+	sort.Slice(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		if a.ShortUrl != b.ShortUrl {
+			return a.ShortUrl < b.ShortUrl
+		}
+		if a.Granularity != b.Granularity {
+			return a.Granularity < b.Granularity
+		}
+		return a.Bucket < b.Bucket
+	})
+	return g.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "short_url"}, {Name: "granularity"}, {Name: "bucket"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"clicks": gorm.Expr("clicks + VALUES(clicks)"),
+		}),
+	}).Create(&stats).Error
+}
+
+func (g *GormClickStatDAO) FindRange(ctx context.Context, shortUrl string, granularity int8, start, end int64) ([]ClickStat, error) {
+	var stats []ClickStat
+	err := g.db.WithContext(ctx).
+		Where("short_url = ? AND granularity = ?", shortUrl, granularity).
+		Where("bucket >= ? AND bucket < ?", start, end).
+		Order("bucket").
+		Find(&stats).Error
+	return stats, err
+}
+
+func (g *GormClickStatDAO) SumClicks(ctx context.Context, shortUrl string, granularity int8) (int64, error) {
+	var total int64
+	err := g.db.WithContext(ctx).Model(&ClickStat{}).
+		Where("short_url = ? AND granularity = ?", shortUrl, granularity).
+		Select("COALESCE(SUM(clicks), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+func (g *GormClickStatDAO) IncrCountries(ctx context.Context, stats []CountryClickStat) error {
+	if len(stats) == 0 {
+		return nil
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		if a.ShortUrl != b.ShortUrl {
+			return a.ShortUrl < b.ShortUrl
+		}
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+		return a.Country < b.Country
+	})
+	return g.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "short_url"}, {Name: "day"}, {Name: "country"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"clicks": gorm.Expr("clicks + VALUES(clicks)"),
+		}),
+	}).Create(&stats).Error
+}
+
+func (g *GormClickStatDAO) SumCountries(ctx context.Context, shortUrl string, start, end int64) ([]CountryClickStat, error) {
+	var stats []CountryClickStat
+	err := g.db.WithContext(ctx).Model(&CountryClickStat{}).
+		Select("country, SUM(clicks) AS clicks").
+		Where("short_url = ? AND day >= ? AND day < ?", shortUrl, start, end).
+		Group("country").
+		Order("clicks DESC, country").
+		Scan(&stats).Error
+	return stats, err
+}
+
+func (g *GormClickStatDAO) IncrVariants(ctx context.Context, stats []VariantClickStat) error {
+	if len(stats) == 0 {
+		return nil
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		if a.ShortUrl != b.ShortUrl {
+			return a.ShortUrl < b.ShortUrl
+		}
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+		return a.Variant < b.Variant
+	})
+	return g.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "short_url"}, {Name: "day"}, {Name: "variant"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"clicks": gorm.Expr("clicks + VALUES(clicks)"),
+		}),
+	}).Create(&stats).Error
+}
+
+func (g *GormClickStatDAO) SumVariants(ctx context.Context, shortUrl string, start, end int64) ([]VariantClickStat, error) {
+	var stats []VariantClickStat
+	err := g.db.WithContext(ctx).Model(&VariantClickStat{}).
+		Select("variant, SUM(clicks) AS clicks").
+		Where("short_url = ? AND day >= ? AND day < ?", shortUrl, start, end).
+		Group("variant").
+		Order("variant").
+		Scan(&stats).Error
+	return stats, err
+}
@@ -1,19 +1,49 @@
 package dao
 
 import (
-	"context"
+	"short_url/pkg/generator"
 
 	"gorm.io/gorm"
 )
 
-func InitTables(db *gorm.DB) {
-	db.AutoMigrate(&Mark{})
-	db.AutoMigrate(&ShortUrl{})
-	db.WithContext(context.Background()).Create(&Mark{
-		Inited: true,
-	})
+// legacyOriginUrlIndex 旧版本分表在 origin_url 上建立的唯一索引。
+// 同一原链接允许创建多个别名后该索引不再适用，AutoMigrate 也不会删除它
+const legacyOriginUrlIndex = "uk_origin_url"
+
+// Migrate 创建缺失的表并补齐新增的列与索引，AutoMigrate 不会删除已有的列，可重复执行
+func Migrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(
+		&ShortUrl{},
+		&ClickStat{},
+		&CountryClickStat{},
+		&VariantClickStat{},
+		&ApiKey{},
+	); err != nil {
+		return err
+	}
+	return migrateOriginUrlIndex(db)
 }
 
-type Mark struct {
-	Inited bool `gorm:"type:tinyint(1)"`
+// migrateOriginUrlIndex 将各分表 origin_url 上的唯一索引替换为普通索引。
+// 保留唯一索引时，为已缩短的原链接创建别名会被 ON DUPLICATE KEY 静默忽略
+func migrateOriginUrlIndex(db *gorm.DB) error {
+	for _, c := range generator.BASE62CHARSET {
+		table := "short_url_" + string(c)
+		m := db.Table(table).Migrator()
+		if !m.HasTable(table) {
+			continue
+		}
+		// 先建普通索引再删唯一索引，迁移期间按原链接查询始终有索引可用
+		if !m.HasIndex(&ShortUrl{}, "idx_origin_url") {
+			if err := m.CreateIndex(&ShortUrl{}, "idx_origin_url"); err != nil {
+				return err
+			}
+		}
+		if m.HasIndex(table, legacyOriginUrlIndex) {
+			if err := m.DropIndex(table, legacyOriginUrlIndex); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
@@ -0,0 +1,25 @@
+package dao
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateOriginUrlIndex(t *testing.T) {
+	db := newTestDB(t, "a")
+	require.NoError(t, db.Exec("CREATE UNIQUE INDEX uk_origin_url ON short_url_a(origin_url)").Error)
+
+	// 可重复执行
+	for i := 0; i < 2; i++ {
+		require.NoError(t, migrateOriginUrlIndex(db))
+	}
+	m := db.Table("short_url_a").Migrator()
+	assert.False(t, m.HasIndex("short_url_a", legacyOriginUrlIndex))
+	assert.True(t, m.HasIndex("short_url_a", "idx_origin_url"))
+
+	// 同一原链接可以对应多个短链接
+	require.NoError(t, db.Table("short_url_a").Create(&ShortUrl{ShortUrl: "a1", OriginUrl: "https://example.com", ExpiredAt: NeverExpire}).Error)
+	require.NoError(t, db.Table("short_url_a").Create(&ShortUrl{ShortUrl: "a2", OriginUrl: "https://example.com", ExpiredAt: NeverExpire}).Error)
+}
@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"short_url/pkg/generator"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/to404hanga/pkg404/logger"
@@ -18,18 +20,80 @@ import (
 type GormShortUrlDAO struct {
 	db            *gorm.DB
 	l             logger.Logger
-	buffer        []ShortUrl      // 环形缓冲区
-	bufferSize    int             // 缓冲区大小
-	readPos       int             // 读取位置
-	writePos      int             // 写入位置
-	batchSize     int             // 批量大小
-	flushInterval time.Duration   // 刷新间隔
-	wg            sync.WaitGroup  // 用于等待worker完成
-	stopChan      chan struct{}   // 用于停止worker
-	flushPool     sync.Pool       // 用于批量处理的slice池
-	flushChan     chan []ShortUrl // 用于异步刷新
-	mu            sync.Mutex      // 保护缓冲区访问
-	closed        bool            // 标记是否已关闭
+	buffer        []bufferedShortUrl      // 环形缓冲区
+	bufferSize    int                     // 缓冲区大小
+	readPos       int                     // 读取位置
+	writePos      int                     // 写入位置
+	reserved      int                     // 已预留但尚未写入缓冲区的位置数
+	batchSize     int                     // 批量大小
+	flushInterval time.Duration           // 刷新间隔
+	linger        time.Duration           // 新数据进入空缓冲区后最多等待多久触发刷新
+	wg            sync.WaitGroup          // 用于等待worker完成
+	enqueueWg     sync.WaitGroup          // 用于等待已预留空间、正在写预写日志的写入完成
+	flushWg       sync.WaitGroup          // 用于等待消费者与进行中的刷新完成
+	stopChan      chan struct{}           // 用于停止worker
+	flushPool     sync.Pool               // 用于批量处理的slice池
+	flushChan     chan []bufferedShortUrl // 用于异步刷新
+	mu            sync.Mutex              // 保护缓冲区访问
+	closed        bool                    // 标记是否已关闭
+	durability    DurabilityMode          // 写入确认模式
+	wal           *shortUrlWAL            // 预写日志，仅 DurabilityWAL 模式下使用
+	walRetry      []bufferedShortUrl      // 落库失败、等待重试的预写日志记录
+	walRetryMu    sync.Mutex              // 保护 walRetry
+	walRetryEvery time.Duration           // 预写日志记录重试间隔
+	walDeleteMu   sync.RWMutex            // 串行化预写日志记录的落库与删除，避免进行中的写入把刚删除的短链接写回
+	blocking      bool                    // 缓冲区满时是否阻塞等待
+	maxBlock      time.Duration           // 阻塞等待的最长时间，0 表示仅受 ctx 限制
+	spaceFreed    chan struct{}           // 缓冲区腾出空间时关闭并替换，用于唤醒阻塞的写入
+	stats         bufferCounters          // 缓冲区运行指标
+}
+
+// bufferCounters 缓冲区累计计数，均使用原子操作更新
+type bufferCounters struct {
+	enqueued atomic.Int64 // 成功写入缓冲区的记录数
+	rejected atomic.Int64 // 因缓冲区满被拒绝的写入次数
+	blocked  atomic.Int64 // 因缓冲区满进入阻塞等待的写入次数
+	flushed  atomic.Int64 // 交给刷新协程的批次数
+}
+
+// BufferStats 环形缓冲区运行指标快照
+type BufferStats struct {
+	Capacity  int   `json:"capacity"`  // 可容纳的记录数
+	Occupancy int   `json:"occupancy"` // 当前待刷新的记录数
+	Enqueued  int64 `json:"enqueued"`
+	Rejected  int64 `json:"rejected"`
+	Blocked   int64 `json:"blocked"`
+	Flushed   int64 `json:"flushed"`
+}
+
+// bufferedShortUrl 缓冲区中等待写入的记录，写入结果通过 result 回传给调用方
+type bufferedShortUrl struct {
+	su     ShortUrl
+	result chan error
+	seq    uint64 // 预写日志序号，仅 DurabilityWAL 模式下使用
+}
+
+// DurabilityMode 决定写入何时向调用方确认
+type DurabilityMode string
+
+const (
+	// DurabilityAck 等待批量插入落库后确认，冲突与数据库错误会返回给调用方
+	DurabilityAck DurabilityMode = "ack"
+	// DurabilityWAL 写入本地预写日志并刷盘后立即确认，落库失败的记录在进程内定期重试，启动时重放日志中未落库的记录
+	DurabilityWAL DurabilityMode = "wal"
+)
+
+// BufferOptions 环形缓冲区写入配置，零值字段使用默认值
+type BufferOptions struct {
+	BufferSize    int            // 缓冲区大小，默认 2000
+	BatchSize     int            // 单批最大插入条数，默认 1000
+	FlushInterval time.Duration  // 定时刷新间隔，默认 50ms
+	Linger        time.Duration  // 新数据最多等待多久即触发刷新，越小写入延迟越低、批量越小
+	Durability    DurabilityMode // 写入确认模式，默认 DurabilityAck
+	WALPath       string         // 预写日志文件路径，仅 DurabilityWAL 模式下使用
+	WALRetry      time.Duration  // 落库失败的预写日志记录重试间隔，默认 1s
+	Blocking      bool           // 缓冲区满时阻塞等待空间，而不是立即返回 ErrBufferFull
+	MaxBlock      time.Duration  // 阻塞等待的最长时间，0 表示仅受 ctx 限制
 }
 
 func (g *GormShortUrlDAO) batchWorker() {
@@ -55,6 +119,15 @@ func (g *GormShortUrlDAO) flushIfNeeded() {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
+	// 关闭后剩余数据由 flushRemaining 负责，避免向已关闭的 flushChan 发送
+	if g.closed {
+		return
+	}
+	g.flushLocked()
+}
+
+// flushLocked 取出一批数据交给刷新协程，调用方需持有 g.mu
+func (g *GormShortUrlDAO) flushLocked() {
 	if g.readPos == g.writePos {
 		return
 	}
@@ -77,67 +150,333 @@ func (g *GormShortUrlDAO) flushRemaining() {
 	}
 }
 
-func (g *GormShortUrlDAO) getBatch() []ShortUrl {
-	batch := g.flushPool.Get().([]ShortUrl)
+func (g *GormShortUrlDAO) getBatch() []bufferedShortUrl {
+	batch := g.flushPool.Get().([]bufferedShortUrl)
 	batch = batch[:0]
 
 	for g.readPos != g.writePos && len(batch) < g.batchSize {
 		batch = append(batch, g.buffer[g.readPos])
+		g.buffer[g.readPos] = bufferedShortUrl{} // 释放对 result channel 的引用
 		g.readPos = (g.readPos + 1) % g.bufferSize
 	}
 
+	// 唤醒等待空间的写入
+	if len(batch) > 0 {
+		g.stats.flushed.Add(1)
+		close(g.spaceFreed)
+		g.spaceFreed = make(chan struct{})
+	}
+
 	return batch
 }
 
-func (g *GormShortUrlDAO) flushBatch(ctx context.Context, batch []ShortUrl) {
+func (g *GormShortUrlDAO) flushBatch(ctx context.Context, batch []bufferedShortUrl) {
 	defer g.flushPool.Put(batch)
 
 	// 按表名分组
-	groups := make(map[string][]ShortUrl)
-	for _, su := range batch {
-		table := g.tableName(su.ShortUrl)
-		groups[table] = append(groups[table], su)
+	groups := make(map[string][]bufferedShortUrl)
+	for _, item := range batch {
+		table := g.tableName(item.su.ShortUrl)
+		groups[table] = append(groups[table], item)
 	}
 
 	// 使用worker池处理每个表
 	var wg sync.WaitGroup
 	wg.Add(len(groups))
 
-	for table, sus := range groups {
-		go func(table string, sus []ShortUrl) {
+	for table, items := range groups {
+		go func(table string, items []bufferedShortUrl) {
 			defer wg.Done()
-			g.db.WithContext(ctx).Table(table).Transaction(func(tx *gorm.DB) error {
-				result := tx.Clauses(clause.OnConflict{
-					Columns:   []clause.Column{{Name: "short_url"}},
-					DoUpdates: clause.Assignments(map[string]any{}),
-				}).Create(&sus)
-
-				if result.RowsAffected != int64(len(sus)) {
-					// 处理冲突
-					for i := result.RowsAffected; i < int64(len(sus)); i++ {
-						var existing ShortUrl
-						if err := tx.Where("short_url = ?", sus[i].ShortUrl).First(&existing).Error; err != nil {
-							g.l.Error("batch insert conflict check failed",
-								logger.Error(err),
-								logger.String("short_url", sus[i].ShortUrl))
-							continue
-						}
-						if existing.OriginUrl != sus[i].OriginUrl {
-							g.l.Warn("primary key conflict detected",
-								logger.String("short_url", sus[i].ShortUrl),
-								logger.String("existing_origin_url", existing.OriginUrl),
-								logger.String("new_origin_url", sus[i].OriginUrl))
-						}
-					}
+			if g.wal != nil {
+				g.insertWAL(ctx, table, items)
+				return
+			}
+			errs := g.insertBatch(ctx, table, items)
+			for i, item := range items {
+				if item.result != nil {
+					item.result <- errs[i]
 				}
-				return nil
-			})
-		}(table, sus)
+			}
+		}(table, items)
 	}
 
 	wg.Wait()
 }
 
+// insertBatch 在单表内批量插入，返回与 items 一一对应的写入结果：
+// 短链接已被其他原链接占用时为 ErrPrimaryKeyConflict，同一原链接已生成过该短链接时为 ErrUniqueIndexConflict，
+// 同批次内重复的短链接只写入首条，事务失败时整组返回该错误
+func (g *GormShortUrlDAO) insertBatch(ctx context.Context, table string, items []bufferedShortUrl) []error {
+	errs := make([]error, len(items))
+	shortUrls := make([]string, 0, len(items))
+	for _, item := range items {
+		shortUrls = append(shortUrls, item.su.ShortUrl)
+	}
+
+	err := g.db.WithContext(ctx).Table(table).Transaction(func(tx *gorm.DB) error {
+		var existing []ShortUrl
+		if err := tx.Select("short_url", "origin_url").Where("short_url IN ?", shortUrls).Find(&existing).Error; err != nil {
+			return err
+		}
+		origins := make(map[string]string, len(existing)+len(items))
+		for _, su := range existing {
+			origins[su.ShortUrl] = su.OriginUrl
+		}
+
+		fresh := make([]ShortUrl, 0, len(items))
+		freshIdx := make([]int, 0, len(items))
+		for i, item := range items {
+			origin, ok := origins[item.su.ShortUrl]
+			switch {
+			case !ok:
+				origins[item.su.ShortUrl] = item.su.OriginUrl
+				fresh = append(fresh, item.su)
+				freshIdx = append(freshIdx, i)
+			case origin == item.su.OriginUrl:
+				errs[i] = ErrUniqueIndexConflict
+			default:
+				g.l.Warn("primary key conflict detected",
+					logger.String("short_url", item.su.ShortUrl),
+					logger.String("existing_origin_url", origin),
+					logger.String("new_origin_url", item.su.OriginUrl))
+				errs[i] = ErrPrimaryKeyConflict
+			}
+		}
+		if len(fresh) == 0 {
+			return nil
+		}
+
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "short_url"}},
+			DoNothing: true,
+		}).Create(&fresh)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == int64(len(fresh)) {
+			return nil
+		}
+
+		// 查询之后被其他实例抢先写入的短链接，按原链接区分主键冲突，同一原链接视为写入成功
+		freshUrls := make([]string, 0, len(fresh))
+		for _, su := range fresh {
+			freshUrls = append(freshUrls, su.ShortUrl)
+		}
+		existing = existing[:0]
+		if err := tx.Select("short_url", "origin_url").Where("short_url IN ?", freshUrls).Find(&existing).Error; err != nil {
+			return err
+		}
+		for _, su := range existing {
+			origins[su.ShortUrl] = su.OriginUrl
+		}
+		for j, su := range fresh {
+			if origin := origins[su.ShortUrl]; origin != su.OriginUrl {
+				g.l.Warn("primary key conflict detected",
+					logger.String("short_url", su.ShortUrl),
+					logger.String("existing_origin_url", origin),
+					logger.String("new_origin_url", su.OriginUrl))
+				errs[freshIdx[j]] = ErrPrimaryKeyConflict
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		g.l.Error("batch insert failed",
+			logger.Error(err),
+			logger.String("table", table),
+			logger.Int("count", len(items)))
+		for i := range errs {
+			errs[i] = err
+		}
+	}
+	return errs
+}
+
+// ackWAL 按序号确认预写日志中已有最终结果的记录：落库成功或同一原链接已存在的直接确认；
+// 主键冲突的无法再通知调用方，写入死信文件后确认；数据库错误的保留在日志中交给重试协程
+func (g *GormShortUrlDAO) ackWAL(items []bufferedShortUrl, errs []error) {
+	seqs := make([]uint64, 0, len(items))
+	var failed []bufferedShortUrl
+	for i, err := range errs {
+		switch err {
+		case nil, ErrUniqueIndexConflict:
+			seqs = append(seqs, items[i].seq)
+		case ErrPrimaryKeyConflict:
+			g.l.Error("acknowledged short url moved to dead letter due to primary key conflict",
+				logger.String("short_url", items[i].su.ShortUrl),
+				logger.String("origin_url", items[i].su.OriginUrl))
+			if err := g.wal.DeadLetter(items[i].su, err.Error()); err != nil {
+				g.l.Error("failed to write dead letter", logger.Error(err))
+				failed = append(failed, items[i])
+				continue
+			}
+			seqs = append(seqs, items[i].seq)
+		default:
+			failed = append(failed, items[i])
+		}
+	}
+	if err := g.wal.Ack(seqs); err != nil {
+		g.l.Error("failed to ack wal", logger.Error(err))
+	}
+	if len(failed) > 0 {
+		g.l.Warn("batch insert failed, records kept in wal for retry",
+			logger.Int("count", len(failed)))
+		g.walRetryMu.Lock()
+		g.walRetry = append(g.walRetry, failed...)
+		g.walRetryMu.Unlock()
+	}
+}
+
+// walRetryWorker 定期重试落库失败的预写日志记录，直到落库、转入死信或被删除。
+// 关闭时仍未成功的记录保留在日志中，由下次启动时重放
+func (g *GormShortUrlDAO) walRetryWorker() {
+	defer g.wg.Done()
+
+	ticker := time.NewTicker(g.walRetryEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-g.stopChan:
+			return
+		case <-ticker.C:
+			g.retryWAL()
+		}
+	}
+}
+
+func (g *GormShortUrlDAO) retryWAL() {
+	g.walRetryMu.Lock()
+	items := g.walRetry
+	g.walRetry = nil
+	g.walRetryMu.Unlock()
+
+	if len(items) > 0 {
+		g.l.Info("retrying wal records", logger.Int("count", len(items)))
+		g.flushWAL(items, time.Second*5)
+	}
+}
+
+// insertWAL 在单表内写入预写日志中的记录并确认结果。与删除互斥，
+// 跳过等待期间已被删除的记录，保证删除要么发生在落库之后，要么使记录不再落库
+func (g *GormShortUrlDAO) insertWAL(ctx context.Context, table string, items []bufferedShortUrl) {
+	g.walDeleteMu.RLock()
+	defer g.walDeleteMu.RUnlock()
+
+	items = slices.DeleteFunc(items, func(item bufferedShortUrl) bool {
+		return !g.wal.Live(item.seq)
+	})
+	if len(items) > 0 {
+		g.ackWAL(items, g.insertBatch(ctx, table, items))
+	}
+}
+
+// flushWAL 按表分批同步写入预写日志中的记录，用于启动重放与失败重试
+func (g *GormShortUrlDAO) flushWAL(items []bufferedShortUrl, timeout time.Duration) {
+	groups := make(map[string][]bufferedShortUrl)
+	for _, item := range items {
+		table := g.tableName(item.su.ShortUrl)
+		groups[table] = append(groups[table], item)
+	}
+
+	for table, items := range groups {
+		for start := 0; start < len(items); start += g.batchSize {
+			end := min(start+g.batchSize, len(items))
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			g.insertWAL(ctx, table, items[start:end])
+			cancel()
+		}
+	}
+}
+
+// checkWALConflicts 预写日志模式下写入前检查短链接是否已存在（数据库中或日志中尚未落库），
+// 返回与 sus 一一对应的结果，使调用方仍能收到 ErrPrimaryKeyConflict 与 ErrUniqueIndexConflict
+func (g *GormShortUrlDAO) checkWALConflicts(ctx context.Context, sus []ShortUrl) ([]error, error) {
+	errs := make([]error, len(sus))
+	groups := make(map[string][]string)
+	for i, su := range sus {
+		if pending, ok := g.wal.Pending(su.ShortUrl); ok {
+			errs[i] = ErrUniqueIndexConflict
+			if pending.OriginUrl != su.OriginUrl {
+				errs[i] = ErrPrimaryKeyConflict
+			}
+			continue
+		}
+		table := g.tableName(su.ShortUrl)
+		groups[table] = append(groups[table], su.ShortUrl)
+	}
+
+	origins := make(map[string]string)
+	for table, shortUrls := range groups {
+		var existing []ShortUrl
+		err := g.db.WithContext(ctx).Table(table).Select("short_url", "origin_url").
+			Where("short_url IN ?", shortUrls).Find(&existing).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, su := range existing {
+			origins[su.ShortUrl] = su.OriginUrl
+		}
+	}
+	for i, su := range sus {
+		origin, ok := origins[su.ShortUrl]
+		switch {
+		case !ok:
+		case origin == su.OriginUrl:
+			errs[i] = ErrUniqueIndexConflict
+		default:
+			errs[i] = ErrPrimaryKeyConflict
+		}
+	}
+	return errs, nil
+}
+
+// discardWAL 删除短链接后确认预写日志中尚未落库的同名记录，避免重试或重放把已删除的短链接写回，调用方需持有 walDeleteMu
+func (g *GormShortUrlDAO) discardWAL(shortUrls []string) {
+	if g.wal == nil || len(shortUrls) == 0 {
+		return
+	}
+	seqs, err := g.wal.Discard(shortUrls)
+	if err != nil {
+		g.l.Error("failed to discard deleted short urls from wal", logger.Error(err))
+		return
+	}
+	if len(seqs) > 0 {
+		g.l.Info("discarded deleted short urls from wal", logger.Int("count", len(seqs)))
+	}
+}
+
+// discardExpiredWAL 清理过期短链接后确认预写日志中已过期的未落库记录，调用方需持有 walDeleteMu
+func (g *GormShortUrlDAO) discardExpiredWAL(now int64) {
+	if g.wal == nil {
+		return
+	}
+	seqs, err := g.wal.DiscardExpired(now)
+	if err != nil {
+		g.l.Error("failed to discard expired short urls from wal", logger.Error(err))
+		return
+	}
+	if len(seqs) > 0 {
+		g.l.Info("discarded expired short urls from wal", logger.Int("count", len(seqs)))
+	}
+}
+
+// updateWAL 修改预写日志中尚未落库的记录，返回是否存在这样的记录。
+// 旧记录随修改被确认，批量写入与重试都会跳过它，修改后的记录交给重试协程落库。调用方需持有 walDeleteMu
+func (g *GormShortUrlDAO) updateWAL(shortUrl string, fn func(su *ShortUrl)) (bool, error) {
+	if g.wal == nil {
+		return false, nil
+	}
+	record, ok, err := g.wal.Update(shortUrl, fn)
+	if err != nil || !ok {
+		return false, err
+	}
+	g.walRetryMu.Lock()
+	g.walRetry = append(g.walRetry, bufferedShortUrl{su: record.Su, seq: record.Seq})
+	g.walRetryMu.Unlock()
+	return true, nil
+}
+
 func (g *GormShortUrlDAO) Close() error {
 	g.mu.Lock()
 	if g.closed {
@@ -147,14 +486,23 @@ func (g *GormShortUrlDAO) Close() error {
 	g.closed = true
 	g.mu.Unlock()
 
-	// 1. 停止batch worker
+	// 1. 等待已预留空间的写入进入缓冲区，再停止batch worker
+	g.enqueueWg.Wait()
 	close(g.stopChan)
 
-	// 2. 等待batch worker完成
+	// 2. 等待batch worker将剩余数据交给刷新协程
 	g.wg.Wait()
 
-	// 3. 关闭flush channel
+	// 3. 关闭flush channel并等待进行中的刷新完成
 	close(g.flushChan)
+	g.flushWg.Wait()
+
+	// 4. 关闭预写日志
+	if g.wal != nil {
+		if err := g.wal.Close(); err != nil {
+			g.l.Error("failed to close wal", logger.Error(err))
+		}
+	}
 
 	g.l.Info("GormShortUrlDAO closed successfully")
 	return nil
@@ -166,39 +514,86 @@ var (
 	ErrPrimaryKeyConflict  = errors.New("primary key conflict")
 	ErrUniqueIndexConflict = errors.New("unique index conflict")
 	ErrDataNotFound        = gorm.ErrRecordNotFound
+	ErrDAOClosed           = errors.New("short url dao closed")
+	ErrBufferFull          = errors.New("short url buffer full")
 )
 
-func NewGormShortUrlDAO(db *gorm.DB, l logger.Logger) ShortUrlDAO {
+func NewGormShortUrlDAO(db *gorm.DB, l logger.Logger, opts BufferOptions) ShortUrlDAO {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 2000
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1000
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 50 * time.Millisecond
+	}
+
 	flushChanBuffer := 10
 	dao := &GormShortUrlDAO{
 		db:            db,
 		l:             l,
-		buffer:        make([]ShortUrl, 2000), // 双倍大小以提供缓冲
-		bufferSize:    2000,
-		batchSize:     1000,
-		flushInterval: 50 * time.Millisecond,
+		buffer:        make([]bufferedShortUrl, opts.BufferSize), // 通常为批量大小的两倍以提供缓冲
+		bufferSize:    opts.BufferSize,
+		batchSize:     opts.BatchSize,
+		flushInterval: opts.FlushInterval,
+		linger:        opts.Linger,
 		stopChan:      make(chan struct{}),
-		flushChan:     make(chan []ShortUrl, flushChanBuffer),
+		flushChan:     make(chan []bufferedShortUrl, flushChanBuffer),
 		closed:        false,
+		durability:    opts.Durability,
+		blocking:      opts.Blocking,
+		maxBlock:      opts.MaxBlock,
+		spaceFreed:    make(chan struct{}),
+	}
+	if dao.durability == "" {
+		dao.durability = DurabilityAck
+	}
+	dao.walRetryEvery = opts.WALRetry
+	if dao.walRetryEvery <= 0 {
+		dao.walRetryEvery = time.Second
+	}
+
+	// 预写日志模式下先重放上次未落库的记录，再开始接受新的写入，重放失败的记录交给重试协程
+	if dao.durability == DurabilityWAL {
+		wal, records, err := openShortUrlWAL(opts.WALPath)
+		if err != nil {
+			panic(fmt.Errorf("failed to open wal: %w", err))
+		}
+		dao.wal = wal
+		if len(records) > 0 {
+			l.Info("replaying wal", logger.Int("count", len(records)))
+			items := make([]bufferedShortUrl, 0, len(records))
+			for _, record := range records {
+				items = append(items, bufferedShortUrl{su: record.Su, seq: record.Seq})
+			}
+			dao.flushWAL(items, time.Second*30)
+		}
 	}
 
 	// 初始化slice池
 	dao.flushPool.New = func() interface{} {
-		return make([]ShortUrl, 0, dao.batchSize)
+		return make([]bufferedShortUrl, 0, dao.batchSize)
 	}
 
 	// 启动batch worker
 	dao.wg.Add(1)
 	go dao.batchWorker()
+	if dao.wal != nil {
+		dao.wg.Add(1)
+		go dao.walRetryWorker()
+	}
 
 	// 启动batch消费者goroutine - 串行消费，并发执行
-	dao.wg.Add(1)
+	dao.flushWg.Add(1)
 	go func() {
-		defer dao.wg.Done()
+		defer dao.flushWg.Done()
 		for batch := range dao.flushChan {
 			// 直接使用goroutine处理batch
 			batchCopy := batch // 避免闭包问题
+			dao.flushWg.Add(1)
 			go func() {
+				defer dao.flushWg.Done()
 				ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 				defer cancel()
 				dao.flushBatch(ctx, batchCopy)
@@ -210,7 +605,11 @@ func NewGormShortUrlDAO(db *gorm.DB, l logger.Logger) ShortUrlDAO {
 	l.Info("ShortUrlDAO initialized with channel-based batch processing",
 		logger.Int("buffer_size", dao.bufferSize),
 		logger.Int("batch_size", dao.batchSize),
-		logger.Int("flush_chan_buffer", flushChanBuffer))
+		logger.String("flush_interval", dao.flushInterval.String()),
+		logger.Bool("blocking", dao.blocking),
+		logger.Int("flush_chan_buffer", flushChanBuffer),
+		logger.String("linger", dao.linger.String()),
+		logger.String("durability", string(dao.durability)))
 
 	return dao
 }
@@ -222,26 +621,216 @@ func (g *GormShortUrlDAO) tableName(shortUrlOrSuffix string) string {
 	return fmt.Sprintf("short_url_%s", string(shortUrlOrSuffix[0]))
 }
 
+// Insert 将记录写入环形缓冲区并等待其随批量插入落库，冲突与数据库错误会原样返回。
+// ctx 只约束进入缓冲区前的等待；记录一旦入队必然被刷新，此后忽略 ctx 等待真实结果，
+// 避免调用方收到 ctx.Err() 而记录实际已经写入。刷新自带超时，等待时间有上限。
+// DurabilityWAL 模式下写入预写日志后立即返回
 func (g *GormShortUrlDAO) Insert(ctx context.Context, su ShortUrl) error {
+	if g.durability == DurabilityWAL {
+		errs, err := g.checkWALConflicts(ctx, []ShortUrl{su})
+		if err != nil {
+			return err
+		}
+		if errs[0] != nil {
+			return errs[0]
+		}
+		return g.enqueue(ctx, []bufferedShortUrl{{su: su}})
+	}
+
+	result := make(chan error, 1)
+	if err := g.enqueue(ctx, []bufferedShortUrl{{su: su, result: result}}); err != nil {
+		return err
+	}
+	return <-result
+}
+
+// BatchInsert 将一批记录整体写入环形缓冲区，剩余空间不足时整批拒绝，不会部分写入。
+// 返回与 sus 一一对应的写入结果，入队后与 Insert 一样等待真实结果而不再响应 ctx
+func (g *GormShortUrlDAO) BatchInsert(ctx context.Context, sus []ShortUrl) ([]error, error) {
+	if g.durability == DurabilityWAL {
+		errs, err := g.checkWALConflicts(ctx, sus)
+		if err != nil {
+			return nil, err
+		}
+		items := make([]bufferedShortUrl, 0, len(sus))
+		for i, su := range sus {
+			if errs[i] == nil {
+				items = append(items, bufferedShortUrl{su: su})
+			}
+		}
+		if len(items) > 0 {
+			if err := g.enqueue(ctx, items); err != nil {
+				return nil, err
+			}
+		}
+		return errs, nil
+	}
+
+	items := make([]bufferedShortUrl, 0, len(sus))
+	for _, su := range sus {
+		items = append(items, bufferedShortUrl{su: su, result: make(chan error, 1)})
+	}
+	if err := g.enqueue(ctx, items); err != nil {
+		return nil, err
+	}
+
+	errs := make([]error, len(items))
+	for i, item := range items {
+		errs[i] = <-item.result
+	}
+	return errs, nil
+}
+
+// enqueue 将记录写入环形缓冲区。空间不足时非阻塞模式立即返回 ErrBufferFull，
+// 阻塞模式等待刷新腾出空间，直到 ctx 结束或超过 maxBlock。
+// 预写日志在预留空间之后、缓冲区锁之外写入，刷盘期间不阻塞其他写入与刷新
+func (g *GormShortUrlDAO) enqueue(ctx context.Context, items []bufferedShortUrl) error {
+	if err := g.reserve(ctx, len(items)); err != nil {
+		return err
+	}
+	defer g.enqueueWg.Done()
+
+	if g.wal != nil {
+		sus := make([]ShortUrl, 0, len(items))
+		for _, item := range items {
+			sus = append(sus, item.su)
+		}
+		seqs, err := g.wal.Append(sus)
+		if err != nil {
+			g.mu.Lock()
+			g.reserved -= len(items)
+			// 归还的空间可能满足正在等待的写入
+			close(g.spaceFreed)
+			g.spaceFreed = make(chan struct{})
+			g.mu.Unlock()
+			return err
+		}
+		for i := range items {
+			items[i].seq = seqs[i]
+		}
+	}
+
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
-	nextPos := (g.writePos + 1) % g.bufferSize
-	if nextPos == g.readPos {
-		return errors.New("buffer full")
+	// 预留的空间保证写入成功；Close 会等待预留完成，此时即使已关闭也由 flushRemaining 处理
+	g.reserved -= len(items)
+	wasEmpty := g.readPos == g.writePos
+	for _, item := range items {
+		g.buffer[g.writePos] = item
+		g.writePos = (g.writePos + 1) % g.bufferSize
 	}
-
-	g.buffer[g.writePos] = su
-	g.writePos = nextPos
+	g.stats.enqueued.Add(int64(len(items)))
 
 	// 如果达到批量大小，立即刷新
-	if (g.writePos-g.readPos+g.bufferSize)%g.bufferSize >= g.batchSize {
-		g.flushIfNeeded()
+	for g.pendingLocked() >= g.batchSize {
+		g.flushLocked()
+	}
+	// 空缓冲区迎来第一批数据时按 linger 安排刷新，不必等到下一个 ticker 周期
+	if wasEmpty && g.readPos != g.writePos && g.linger < g.flushInterval {
+		time.AfterFunc(g.linger, g.flushIfNeeded)
 	}
 
 	return nil
 }
 
+// reserve 在缓冲区中为 n 条记录预留空间，成功时已调用 g.enqueueWg.Add，调用方写入后需调用 Done
+func (g *GormShortUrlDAO) reserve(ctx context.Context, n int) error {
+	// 环形缓冲区保留一个空位用于区分空和满，超过容量的写入永远无法满足
+	if n > g.bufferSize-1 {
+		g.stats.rejected.Add(1)
+		return ErrBufferFull
+	}
+
+	var timeout <-chan time.Time
+	for {
+		g.mu.Lock()
+		if g.closed {
+			g.mu.Unlock()
+			return ErrDAOClosed
+		}
+		if g.bufferSize-1-g.pendingLocked()-g.reserved >= n {
+			break
+		}
+		if !g.blocking {
+			g.mu.Unlock()
+			g.stats.rejected.Add(1)
+			return ErrBufferFull
+		}
+		spaceFreed := g.spaceFreed
+		g.mu.Unlock()
+
+		if timeout == nil {
+			g.stats.blocked.Add(1)
+			if g.maxBlock > 0 {
+				timer := time.NewTimer(g.maxBlock)
+				defer timer.Stop()
+				timeout = timer.C
+			}
+		}
+		select {
+		case <-spaceFreed:
+		case <-timeout:
+			g.stats.rejected.Add(1)
+			return ErrBufferFull
+		case <-ctx.Done():
+			g.stats.rejected.Add(1)
+			return fmt.Errorf("%w: %w", ErrBufferFull, ctx.Err())
+		}
+	}
+	g.reserved += n
+	g.enqueueWg.Add(1)
+	g.mu.Unlock()
+	return nil
+}
+
+// BufferStats 返回环形缓冲区运行指标快照
+func (g *GormShortUrlDAO) BufferStats() BufferStats {
+	g.mu.Lock()
+	occupancy := g.pendingLocked()
+	g.mu.Unlock()
+
+	return BufferStats{
+		Capacity:  g.bufferSize - 1,
+		Occupancy: occupancy,
+		Enqueued:  g.stats.enqueued.Load(),
+		Rejected:  g.stats.rejected.Load(),
+		Blocked:   g.stats.blocked.Load(),
+		Flushed:   g.stats.flushed.Load(),
+	}
+}
+
+// pendingLocked 返回缓冲区中待刷新的记录数，调用方需持有 g.mu
+func (g *GormShortUrlDAO) pendingLocked() int {
+	return (g.writePos - g.readPos + g.bufferSize) % g.bufferSize
+}
+
+// Reserve 同步插入单条记录，不经过环形缓冲区
+// 用于自定义别名等需要立即得知冲突结果的场景
+func (g *GormShortUrlDAO) Reserve(ctx context.Context, su ShortUrl) error {
+	return g.db.WithContext(ctx).Table(g.tableName(su.ShortUrl)).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "short_url"}},
+			DoNothing: true,
+		}).Create(&su)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			var existing ShortUrl
+			if err := tx.Where("short_url = ?", su.ShortUrl).First(&existing).Error; err != nil {
+				return err
+			}
+			if existing.OriginUrl != su.OriginUrl {
+				return ErrPrimaryKeyConflict
+			}
+			return ErrUniqueIndexConflict
+		}
+		return nil
+	})
+}
+
 /*
 // 原单次插入实现，保留作为参考
 func (g *GormShortUrlDAO) Insert(ctx context.Context, su ShortUrl) error {
@@ -276,13 +865,20 @@ func (g *GormShortUrlDAO) Insert(ctx context.Context, su ShortUrl) error {
 
 func (g *GormShortUrlDAO) FindByShortUrlWithExpired(ctx context.Context, shortUrl string, now int64) (ShortUrl, error) {
 	var su ShortUrl
-	err := g.db.WithContext(ctx).Table(g.tableName(shortUrl)).Where("short_url = ?", shortUrl).Where("expired_at > ?", now).First(&su).Error
+	err := g.db.WithContext(ctx).Table(g.tableName(shortUrl)).Where("short_url = ?", shortUrl).Where("(expired_at > ? OR expired_at = ?)", now, NeverExpire).
+		Where("not_before <= ?", now).First(&su).Error
 	return su, err
 }
 
+// FindByShortUrl 查询短链接，预写日志模式下已确认但尚未落库的记录同样可见
 func (g *GormShortUrlDAO) FindByShortUrl(ctx context.Context, shortUrl string) (ShortUrl, error) {
 	var su ShortUrl
 	err := g.db.WithContext(ctx).Table(g.tableName(shortUrl)).Where("short_url = ?", shortUrl).First(&su).Error
+	if errors.Is(err, ErrDataNotFound) && g.wal != nil {
+		if pending, ok := g.wal.Pending(shortUrl); ok {
+			return pending, nil
+		}
+	}
 	return su, err
 }
 
@@ -313,7 +909,7 @@ func (g *GormShortUrlDAO) FindByOriginUrlWithExpired(ctx context.Context, origin
 			err := g.db.WithContext(groupCtx).
 				Table(g.tableName(suffix)).
 				Where("origin_url = ?", originUrl).
-				Where("expired_at > ?", now).
+				Where("(expired_at > ? OR expired_at = ?)", now, NeverExpire).
 				First(&internalSu).Error
 
 			if err != nil {
@@ -361,7 +957,7 @@ func (g *GormShortUrlDAO) FindByOriginUrlWithExpiredV1(ctx context.Context, orig
 		if err := db.WithContext(iCtx).
 			Table(g.tableName(suffix)).
 			Where("origin_url =?", originUrl).
-			Where("expired_at >?", now).
+			Where("(expired_at > ? OR expired_at = ?)", now, NeverExpire).
 			First(&internalSu).Error; err != nil {
 			g.l.Error("FindByOriginUrlWithExpiredV1 failed",
 				logger.Error(err),
@@ -506,6 +1102,7 @@ func (g *GormShortUrlDAO) FindExpiredList(ctx context.Context, now int64) ([]Sho
 			var internalSus []ShortUrl
 			err := g.db.WithContext(groupCtx).
 				Table(g.tableName(suffix)).
+				Where("expired_at <> ?", NeverExpire).
 				Where("expired_at <= ?", now).
 				Find(&internalSus).Error
 
@@ -548,7 +1145,8 @@ func (g *GormShortUrlDAO) FindExpiredListV1(ctx context.Context, now int64) ([]S
 		var internalSus []ShortUrl
 		err := db.WithContext(iCtx).
 			Table(g.tableName(suffix)).
-			Where("expired_at <=?", now).
+			Where("expired_at <> ?", NeverExpire).
+			Where("expired_at <= ?", now).
 			Find(&internalSus).Error
 		if err != nil {
 			g.l.Error("FindExpiredListV1 failed",
@@ -603,8 +1201,56 @@ func (g *GormShortUrlDAO) executeUnshardedQuery(ctx context.Context, fn func(iCt
 	return group.Wait()
 }
 
+func (g *GormShortUrlDAO) UpdateOriginUrl(ctx context.Context, shortUrl, originUrl string) error {
+	return g.update(ctx, shortUrl, "origin_url", originUrl, func(su *ShortUrl) {
+		su.OriginUrl = originUrl
+	})
+}
+
+func (g *GormShortUrlDAO) UpdateExpiredAt(ctx context.Context, shortUrl string, expiredAt int64) error {
+	return g.update(ctx, shortUrl, "expired_at", expiredAt, func(su *ShortUrl) {
+		su.ExpiredAt = expiredAt
+	})
+}
+
+// update 修改短链接的单个字段，短链接不存在时返回 ErrDataNotFound。
+// 预写日志模式下尚未落库的记录直接在日志中修改，与写入、删除互斥，避免之后落库时写回旧值
+func (g *GormShortUrlDAO) update(ctx context.Context, shortUrl, column string, value any, fn func(su *ShortUrl)) error {
+	if g.wal != nil {
+		g.walDeleteMu.Lock()
+		defer g.walDeleteMu.Unlock()
+	}
+	pending, err := g.updateWAL(shortUrl, fn)
+	if err != nil || pending {
+		return err
+	}
+
+	res := g.db.WithContext(ctx).Table(g.tableName(shortUrl)).Where("short_url = ?", shortUrl).Update(column, value)
+	if res.Error != nil || res.RowsAffected > 0 {
+		return res.Error
+	}
+	// MySQL 默认只统计值发生变化的行，未修改任何行时确认短链接是否存在
+	var n int64
+	if err := g.db.WithContext(ctx).Table(g.tableName(shortUrl)).Where("short_url = ?", shortUrl).Count(&n).Error; err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrDataNotFound
+	}
+	return nil
+}
+
 func (g *GormShortUrlDAO) DeleteByShortUrl(ctx context.Context, shortUrl string) error {
-	return g.db.WithContext(ctx).Table(g.tableName(shortUrl)).Where("short_url = ?", shortUrl).Delete(&ShortUrl{}).Error
+	if g.wal != nil {
+		g.walDeleteMu.Lock()
+		defer g.walDeleteMu.Unlock()
+	}
+	err := g.db.WithContext(ctx).Table(g.tableName(shortUrl)).Where("short_url = ?", shortUrl).Delete(&ShortUrl{}).Error
+	if err != nil {
+		return err
+	}
+	g.discardWAL([]string{shortUrl})
+	return nil
 }
 
 func (g *GormShortUrlDAO) DeleteExpiredList(ctx context.Context, now int64) ([]string, error) {
@@ -620,6 +1266,7 @@ func (g *GormShortUrlDAO) DeleteExpiredList(ctx context.Context, now int64) ([]s
 				var ret []string
 				// 查询可删除列表
 				err := g.db.WithContext(ctx).Table(tableName).Select("short_url").
+					Where("expired_at <> ?", NeverExpire).
 					Where("expired_at < ?", now).Order("expired_at ASC").Limit(100).
 					Find(&ret).Error
 				if err != nil {
@@ -628,7 +1275,7 @@ func (g *GormShortUrlDAO) DeleteExpiredList(ctx context.Context, now int64) ([]s
 				if len(ret) == 0 {
 					break // 无更多数据可删除
 				}
-				err = g.db.WithContext(ctx).Table(tableName).Where("short_url IN ?", ret).Delete(&ShortUrl{}).Error
+				err = g.deleteExpired(ctx, tableName, ret, now)
 				if err != nil {
 					return err
 				}
@@ -645,6 +1292,21 @@ func (g *GormShortUrlDAO) DeleteExpiredList(ctx context.Context, now int64) ([]s
 	return retList, group.Wait()
 }
 
+// deleteExpired 删除一批过期短链接。与 DeleteByShortUrl 一样和预写日志的写入互斥，
+// 并确认日志中已过期的未落库记录，避免重试或重放把刚删除的短链接写回
+func (g *GormShortUrlDAO) deleteExpired(ctx context.Context, tableName string, shortUrls []string, now int64) error {
+	if g.wal != nil {
+		g.walDeleteMu.Lock()
+		defer g.walDeleteMu.Unlock()
+	}
+	err := g.db.WithContext(ctx).Table(tableName).Where("short_url IN ?", shortUrls).Delete(&ShortUrl{}).Error
+	if err != nil {
+		return err
+	}
+	g.discardExpiredWAL(now)
+	return nil
+}
+
 func (g *GormShortUrlDAO) Transaction(ctx context.Context, fc func(tx *gorm.DB) error, opts ...*sql.TxOptions) error {
 	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		return fc(tx)
@@ -662,7 +1324,7 @@ func (g *GormShortUrlDAO) FindAllValidShortUrls(ctx context.Context, now int64)
 		var internalSus []ShortUrl
 		err := db.WithContext(iCtx).
 			Table(g.tableName(suffix)).
-			Where("expired_at > ?", now).
+			Where("(expired_at > ? OR expired_at = ?)", now, NeverExpire).
 			Find(&internalSus).Error
 		if err != nil {
 			g.l.Error("FindAllValidShortUrls failed",
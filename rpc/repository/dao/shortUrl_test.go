@@ -0,0 +1,458 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"short_url/pkg/generator"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/to404hanga/pkg404/logger"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	glogger "gorm.io/gorm/logger"
+)
+
+// newTestDB 创建 sqlite 数据库并建好指定首字符的分表，列与 MySQL 建表一致
+func newTestDB(t *testing.T, suffixes ...string) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "short_url.db")), &gorm.Config{
+		Logger: glogger.Discard,
+	})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	// sqlite 同一时刻只允许一个写事务
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	for _, suffix := range suffixes {
+		createShortUrlTable(t, db, suffix)
+	}
+	return db
+}
+
+func createShortUrlTable(t *testing.T, db *gorm.DB, suffix string) {
+	t.Helper()
+	require.NoError(t, db.Exec(fmt.Sprintf(`CREATE TABLE short_url_%s (
+		short_url TEXT NOT NULL PRIMARY KEY,
+		origin_url TEXT NOT NULL DEFAULT '',
+		expired_at INTEGER DEFAULT -1,
+		not_before INTEGER NOT NULL DEFAULT 0,
+		redirect_code INTEGER NOT NULL DEFAULT 0,
+		pass_through NUMERIC NOT NULL DEFAULT 0,
+		utm_template TEXT NOT NULL DEFAULT '',
+		utm TEXT NOT NULL DEFAULT '',
+		password_hash TEXT NOT NULL DEFAULT '',
+		max_clicks INTEGER NOT NULL DEFAULT 0,
+		rules TEXT NOT NULL DEFAULT '',
+		variants TEXT NOT NULL DEFAULT ''
+	)`, suffix)).Error)
+}
+
+func newTestDAO(t *testing.T, db *gorm.DB, opts BufferOptions) *GormShortUrlDAO {
+	t.Helper()
+	d := NewGormShortUrlDAO(db, logger.NewNopLogger(), opts).(*GormShortUrlDAO)
+	t.Cleanup(func() { d.Close() })
+	return d
+}
+
+func countShortUrls(t *testing.T, db *gorm.DB, shortUrls ...string) int {
+	t.Helper()
+	total := 0
+	for _, shortUrl := range shortUrls {
+		var n int64
+		require.NoError(t, db.Table("short_url_"+shortUrl[:1]).Where("short_url = ?", shortUrl).Count(&n).Error)
+		total += int(n)
+	}
+	return total
+}
+
+func TestGormShortUrlDAO_Insert(t *testing.T) {
+	db := newTestDB(t, "a", "b")
+	require.NoError(t, db.Table("short_url_a").Create(&ShortUrl{
+		ShortUrl:  "aTaken",
+		OriginUrl: "https://example.com/taken",
+		ExpiredAt: NeverExpire,
+	}).Error)
+	d := newTestDAO(t, db, BufferOptions{BufferSize: 16, BatchSize: 8, FlushInterval: 10 * time.Millisecond})
+
+	testCases := []struct {
+		name      string
+		su        ShortUrl
+		wantErr   error
+		wantDBErr bool // 错误内容由驱动决定，只检查是否返回
+	}{
+		{
+			name: "写入成功",
+			su:   ShortUrl{ShortUrl: "aNew01", OriginUrl: "https://example.com/a", ExpiredAt: NeverExpire},
+		},
+		{
+			name: "不同分表",
+			su:   ShortUrl{ShortUrl: "bNew01", OriginUrl: "https://example.com/b", ExpiredAt: NeverExpire},
+		},
+		{
+			name:    "短码已被其他原链接占用",
+			su:      ShortUrl{ShortUrl: "aTaken", OriginUrl: "https://example.com/other", ExpiredAt: NeverExpire},
+			wantErr: ErrPrimaryKeyConflict,
+		},
+		{
+			name:    "同一原链接已生成过该短码",
+			su:      ShortUrl{ShortUrl: "aTaken", OriginUrl: "https://example.com/taken", ExpiredAt: NeverExpire},
+			wantErr: ErrUniqueIndexConflict,
+		},
+		{
+			name:      "分表不存在时返回数据库错误",
+			su:        ShortUrl{ShortUrl: "cNew01", OriginUrl: "https://example.com/c", ExpiredAt: NeverExpire},
+			wantDBErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := d.Insert(context.Background(), tc.su)
+			if tc.wantDBErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tc.wantErr)
+			if tc.wantErr == nil {
+				found, err := d.FindByShortUrl(context.Background(), tc.su.ShortUrl)
+				require.NoError(t, err)
+				assert.Equal(t, tc.su, found)
+			}
+		})
+	}
+}
+
+func TestGormShortUrlDAO_Flush(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts BufferOptions
+		n    int
+		// 写入返回的最长等待时间
+		within time.Duration
+	}{
+		{
+			// 定时刷新间隔远大于等待时间，只能由攒满一批触发
+			name:   "攒满一批立即刷新",
+			opts:   BufferOptions{BufferSize: 16, BatchSize: 4, FlushInterval: time.Hour, Linger: time.Hour},
+			n:      4,
+			within: time.Second,
+		},
+		{
+			name:   "未满一批时按间隔刷新",
+			opts:   BufferOptions{BufferSize: 16, BatchSize: 8, FlushInterval: 20 * time.Millisecond, Linger: time.Hour},
+			n:      3,
+			within: time.Second,
+		},
+		{
+			name:   "空缓冲区按 linger 刷新",
+			opts:   BufferOptions{BufferSize: 16, BatchSize: 8, FlushInterval: time.Hour, Linger: 5 * time.Millisecond},
+			n:      1,
+			within: time.Second,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := newTestDB(t, "a")
+			d := newTestDAO(t, db, tc.opts)
+
+			sus := make([]ShortUrl, 0, tc.n)
+			shortUrls := make([]string, 0, tc.n)
+			for i := 0; i < tc.n; i++ {
+				su := ShortUrl{ShortUrl: fmt.Sprintf("a%05d", i), OriginUrl: fmt.Sprintf("https://example.com/%d", i), ExpiredAt: NeverExpire}
+				sus = append(sus, su)
+				shortUrls = append(shortUrls, su.ShortUrl)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), tc.within)
+			defer cancel()
+			errs, err := d.BatchInsert(ctx, sus)
+			require.NoError(t, err)
+			assert.Equal(t, make([]error, tc.n), errs)
+			assert.Equal(t, tc.n, countShortUrls(t, db, shortUrls...))
+
+			stats := d.BufferStats()
+			assert.Equal(t, 0, stats.Occupancy)
+			assert.Equal(t, int64(tc.n), stats.Enqueued)
+		})
+	}
+}
+
+func TestGormShortUrlDAO_BatchInsertDuplicates(t *testing.T) {
+	db := newTestDB(t, "a")
+	d := newTestDAO(t, db, BufferOptions{BufferSize: 16, BatchSize: 8, FlushInterval: 10 * time.Millisecond})
+
+	// 同批次内重复的短链接只写入首条，其余按原链接区分冲突类型
+	errs, err := d.BatchInsert(context.Background(), []ShortUrl{
+		{ShortUrl: "aDup01", OriginUrl: "https://example.com/dup", ExpiredAt: NeverExpire},
+		{ShortUrl: "aDup01", OriginUrl: "https://example.com/dup", ExpiredAt: NeverExpire},
+		{ShortUrl: "aDup01", OriginUrl: "https://example.com/other", ExpiredAt: NeverExpire},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []error{nil, ErrUniqueIndexConflict, ErrPrimaryKeyConflict}, errs)
+	found, err := d.FindByShortUrl(context.Background(), "aDup01")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/dup", found.OriginUrl)
+}
+
+// fillBuffer 在后台写满缓冲区，刷新间隔足够长时记录会一直停留在缓冲区中
+func fillBuffer(t *testing.T, d *GormShortUrlDAO) {
+	t.Helper()
+	capacity := d.BufferStats().Capacity
+	sus := make([]ShortUrl, 0, capacity)
+	for i := 0; i < capacity; i++ {
+		sus = append(sus, ShortUrl{ShortUrl: fmt.Sprintf("aFill%d", i), OriginUrl: fmt.Sprintf("https://example.com/fill/%d", i), ExpiredAt: NeverExpire})
+	}
+	go d.BatchInsert(context.Background(), sus)
+	require.Eventually(t, func() bool {
+		return d.BufferStats().Occupancy == capacity
+	}, time.Second, time.Millisecond)
+}
+
+func TestGormShortUrlDAO_BufferFull(t *testing.T) {
+	testCases := []struct {
+		name    string
+		opts    BufferOptions
+		timeout time.Duration
+		n       int
+		wantErr error
+	}{
+		{
+			name: "非阻塞模式立即拒绝",
+			opts: BufferOptions{},
+			n:    1,
+		},
+		{
+			name: "阻塞模式超过 maxBlock 后拒绝",
+			opts: BufferOptions{Blocking: true, MaxBlock: 20 * time.Millisecond},
+			n:    1,
+		},
+		{
+			name:    "阻塞模式 ctx 超时后拒绝",
+			opts:    BufferOptions{Blocking: true},
+			timeout: 20 * time.Millisecond,
+			n:       1,
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name: "单批超过容量时阻塞模式也立即拒绝",
+			opts: BufferOptions{Blocking: true},
+			n:    4,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := newTestDB(t, "a")
+			tc.opts.BufferSize, tc.opts.BatchSize = 4, 8
+			tc.opts.FlushInterval, tc.opts.Linger = time.Hour, time.Hour
+			d := newTestDAO(t, db, tc.opts)
+			fillBuffer(t, d)
+
+			ctx := context.Background()
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+			sus := make([]ShortUrl, 0, tc.n)
+			for i := 0; i < tc.n; i++ {
+				sus = append(sus, ShortUrl{ShortUrl: fmt.Sprintf("aFull%d", i), OriginUrl: fmt.Sprintf("https://example.com/full/%d", i), ExpiredAt: NeverExpire})
+			}
+			_, err := d.BatchInsert(ctx, sus)
+			assert.ErrorIs(t, err, ErrBufferFull)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+			}
+			assert.Equal(t, int64(1), d.BufferStats().Rejected)
+		})
+	}
+}
+
+func TestGormShortUrlDAO_Backpressure(t *testing.T) {
+	db := newTestDB(t, "a")
+	d := newTestDAO(t, db, BufferOptions{BufferSize: 4, BatchSize: 8, FlushInterval: time.Hour, Linger: time.Hour, Blocking: true})
+	fillBuffer(t, d)
+
+	su := ShortUrl{ShortUrl: "aWait01", OriginUrl: "https://example.com/wait", ExpiredAt: NeverExpire}
+	done := make(chan error, 1)
+	go func() { done <- d.Insert(context.Background(), su) }()
+	require.Eventually(t, func() bool {
+		return d.BufferStats().Blocked == 1
+	}, time.Second, time.Millisecond)
+
+	// 刷新腾出空间后阻塞的写入入队，并随下一次刷新落库
+	d.flushIfNeeded()
+	require.Eventually(t, func() bool {
+		return d.BufferStats().Occupancy == 1
+	}, time.Second, time.Millisecond)
+	d.flushIfNeeded()
+	require.NoError(t, <-done)
+	assert.Equal(t, 1, countShortUrls(t, db, su.ShortUrl))
+	assert.Equal(t, int64(0), d.BufferStats().Rejected)
+}
+
+func TestGormShortUrlDAO_InsertCtxCancelledAfterEnqueue(t *testing.T) {
+	db := newTestDB(t, "a")
+	d := newTestDAO(t, db, BufferOptions{BufferSize: 4, BatchSize: 8, FlushInterval: time.Hour, Linger: time.Hour})
+
+	su := ShortUrl{ShortUrl: "aCtx01", OriginUrl: "https://example.com/ctx", ExpiredAt: NeverExpire}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- d.Insert(ctx, su) }()
+	require.Eventually(t, func() bool {
+		return d.BufferStats().Occupancy == 1
+	}, time.Second, time.Millisecond)
+
+	// 入队后取消 ctx，调用方仍应得到真实的写入结果
+	cancel()
+	d.flushIfNeeded()
+	require.NoError(t, <-done)
+	assert.Equal(t, 1, countShortUrls(t, db, su.ShortUrl))
+}
+
+// failInserts 让指定分表的插入失败，返回恢复函数，用于模拟落库失败
+func failInserts(t *testing.T, db *gorm.DB, suffix string) func() {
+	t.Helper()
+	require.NoError(t, db.Exec(fmt.Sprintf(`CREATE TRIGGER fail_short_url_%s BEFORE INSERT ON short_url_%s
+		BEGIN SELECT RAISE(ABORT, 'injected failure'); END`, suffix, suffix)).Error)
+	return func() {
+		require.NoError(t, db.Exec(fmt.Sprintf("DROP TRIGGER fail_short_url_%s", suffix)).Error)
+	}
+}
+
+func TestGormShortUrlDAO_WALReplay(t *testing.T) {
+	db := newTestDB(t, "a", "b")
+	require.NoError(t, db.Table("short_url_a").Create(&ShortUrl{
+		ShortUrl:  "aTaken",
+		OriginUrl: "https://example.com/taken",
+		ExpiredAt: NeverExpire,
+	}).Error)
+	path := filepath.Join(t.TempDir(), "short_url.wal")
+
+	// 模拟上次进程崩溃时留下的日志
+	w, _, err := openShortUrlWAL(path)
+	require.NoError(t, err)
+	_, err = w.Append([]ShortUrl{
+		{ShortUrl: "aRep01", OriginUrl: "https://example.com/replay", ExpiredAt: NeverExpire},
+		{ShortUrl: "aTaken", OriginUrl: "https://example.com/other", ExpiredAt: NeverExpire},
+		{ShortUrl: "bRep01", OriginUrl: "https://example.com/replay/b", ExpiredAt: NeverExpire},
+	})
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	restore := failInserts(t, db, "b")
+	d := newTestDAO(t, db, BufferOptions{BufferSize: 16, BatchSize: 8, FlushInterval: 10 * time.Millisecond,
+		Durability: DurabilityWAL, WALPath: path, WALRetry: 10 * time.Millisecond})
+
+	// 重放成功的记录落库，主键冲突的记录转入死信文件，失败的记录保留在日志中
+	assert.Equal(t, 1, countShortUrls(t, db, "aRep01"))
+	dead, err := os.ReadFile(path + ".dead")
+	require.NoError(t, err)
+	assert.Contains(t, string(dead), "https://example.com/other")
+	_, ok := d.wal.Pending("bRep01")
+	assert.True(t, ok)
+	_, ok = d.wal.Pending("aRep01")
+	assert.False(t, ok)
+
+	// 恢复后由重试协程落库并清空日志
+	restore()
+	require.Eventually(t, func() bool {
+		return countShortUrls(t, db, "bRep01") == 1
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, 0, walLines(t, path))
+
+	// 已落库后被删除的短链接重启后不再重放
+	require.NoError(t, d.DeleteByShortUrl(context.Background(), "aRep01"))
+	require.NoError(t, d.Close())
+	newTestDAO(t, db, BufferOptions{Durability: DurabilityWAL, WALPath: path})
+	assert.Equal(t, 0, countShortUrls(t, db, "aRep01"))
+}
+
+func TestGormShortUrlDAO_WALPartialFailure(t *testing.T) {
+	db := newTestDB(t, "a", "b")
+	require.NoError(t, db.Table("short_url_a").Create(&ShortUrl{
+		ShortUrl:  "aTaken",
+		OriginUrl: "https://example.com/taken",
+		ExpiredAt: NeverExpire,
+	}).Error)
+	path := filepath.Join(t.TempDir(), "short_url.wal")
+	restore := failInserts(t, db, "b")
+	d := newTestDAO(t, db, BufferOptions{BufferSize: 16, BatchSize: 8, FlushInterval: 10 * time.Millisecond,
+		Durability: DurabilityWAL, WALPath: path, WALRetry: 10 * time.Millisecond})
+
+	errs, err := d.BatchInsert(context.Background(), []ShortUrl{
+		{ShortUrl: "aPart01", OriginUrl: "https://example.com/a", ExpiredAt: NeverExpire},
+		{ShortUrl: "aTaken", OriginUrl: "https://example.com/other", ExpiredAt: NeverExpire},
+		{ShortUrl: "bPart01", OriginUrl: "https://example.com/b", ExpiredAt: NeverExpire},
+		{ShortUrl: "bPart02", OriginUrl: "https://example.com/b/deleted", ExpiredAt: NeverExpire},
+	})
+	require.NoError(t, err)
+	// 主键冲突在写入日志前检查，仍能返回给调用方
+	assert.Equal(t, []error{nil, ErrPrimaryKeyConflict, nil, nil}, errs)
+
+	require.Eventually(t, func() bool {
+		return countShortUrls(t, db, "aPart01") == 1
+	}, time.Second, time.Millisecond)
+	// 落库失败的记录留在日志中，同一短链接的不同原链接也会被拒绝
+	_, ok := d.wal.Pending("bPart01")
+	assert.True(t, ok)
+	assert.ErrorIs(t, d.Insert(context.Background(), ShortUrl{ShortUrl: "bPart01", OriginUrl: "https://example.com/b/other", ExpiredAt: NeverExpire}), ErrPrimaryKeyConflict)
+
+	// 重试期间修改的短链接以修改后的值落库，不存在的短链接修改失败
+	require.NoError(t, d.UpdateOriginUrl(context.Background(), "bPart01", "https://example.com/b/edited"))
+	su, err := d.FindByShortUrl(context.Background(), "bPart01")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/b/edited", su.OriginUrl)
+	assert.ErrorIs(t, d.UpdateExpiredAt(context.Background(), "bMissing", time.Now().Unix()), ErrDataNotFound)
+	assert.NoError(t, d.UpdateExpiredAt(context.Background(), "aPart01", NeverExpire))
+
+	// 重试期间删除的短链接不再写回
+	require.NoError(t, d.DeleteByShortUrl(context.Background(), "bPart02"))
+	restore()
+	require.Eventually(t, func() bool {
+		return countShortUrls(t, db, "bPart01") == 1
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, 0, countShortUrls(t, db, "bPart02"))
+	require.NoError(t, db.Table("short_url_b").Where("short_url = ?", "bPart01").First(&su).Error)
+	assert.Equal(t, "https://example.com/b/edited", su.OriginUrl)
+	assert.Equal(t, 0, walLines(t, path))
+}
+
+func TestGormShortUrlDAO_WALDeleteExpired(t *testing.T) {
+	// sqlite 表名不区分大小写，小写字母与数字的分表即可覆盖全部 62 张表
+	db := newTestDB(t, strings.Split(generator.BASE62CHARSET[:36], "")...)
+	path := filepath.Join(t.TempDir(), "short_url.wal")
+	restore := failInserts(t, db, "b")
+	d := newTestDAO(t, db, BufferOptions{BufferSize: 16, BatchSize: 8, FlushInterval: 10 * time.Millisecond,
+		Durability: DurabilityWAL, WALPath: path, WALRetry: 10 * time.Millisecond})
+
+	now := time.Now().Unix()
+	require.NoError(t, d.Insert(context.Background(), ShortUrl{ShortUrl: "aExp01", OriginUrl: "https://example.com/a", ExpiredAt: now - 10}))
+	require.NoError(t, d.Insert(context.Background(), ShortUrl{ShortUrl: "bExp01", OriginUrl: "https://example.com/b", ExpiredAt: now - 10}))
+	require.NoError(t, d.Insert(context.Background(), ShortUrl{ShortUrl: "bLive01", OriginUrl: "https://example.com/b/live", ExpiredAt: NeverExpire}))
+	require.Eventually(t, func() bool {
+		return countShortUrls(t, db, "aExp01") == 1
+	}, time.Second, time.Millisecond)
+
+	deleted, err := d.DeleteExpiredList(context.Background(), now)
+	require.NoError(t, err)
+	// 大小写不同的分表在 sqlite 中是同一张表，可能被两个协程同时查到
+	assert.Contains(t, deleted, "aExp01")
+	// 尚未落库的过期记录随清理一并确认，未过期的记录保留
+	_, ok := d.wal.Pending("bExp01")
+	assert.False(t, ok)
+	_, ok = d.wal.Pending("bLive01")
+	assert.True(t, ok)
+
+	restore()
+	require.Eventually(t, func() bool {
+		return countShortUrls(t, db, "bLive01") == 1
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, 0, countShortUrls(t, db, "aExp01", "bExp01"))
+}
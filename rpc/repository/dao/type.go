@@ -9,20 +9,96 @@ import (
 
 type ShortUrlDAO interface {
 	Insert(ctx context.Context, su ShortUrl) error
+	BatchInsert(ctx context.Context, sus []ShortUrl) ([]error, error)
+	Reserve(ctx context.Context, su ShortUrl) error
 	FindByShortUrl(ctx context.Context, shortUrl string) (ShortUrl, error)
 	FindByShortUrlWithExpired(ctx context.Context, shortUrl string, now int64) (ShortUrl, error)
 	FindExpiredList(ctx context.Context, now int64) ([]ShortUrl, error)
 	// FindByOriginUrlWithExpired(ctx context.Context, originUrl string, now int64) (ShortUrl, error)
 	// FindByOriginUrl(ctx context.Context, originUrl string) (ShortUrl, error)
 	FindAllValidShortUrls(ctx context.Context, now int64) ([]ShortUrl, error)
+	UpdateOriginUrl(ctx context.Context, shortUrl, originUrl string) error
+	UpdateExpiredAt(ctx context.Context, shortUrl string, expiredAt int64) error
 	DeleteByShortUrl(ctx context.Context, shortUrl string) error
 	DeleteExpiredList(ctx context.Context, now int64) ([]string, error)
 	Transaction(ctx context.Context, fc func(tx *gorm.DB) error, opts ...*sql.TxOptions) error
 	WithTransaction(ctx context.Context, fc func(txDAO ShortUrlDAO) error, opts ...*sql.TxOptions) error
 }
 
+// BufferStatsProvider 暴露写入缓冲区运行指标的 DAO
+type BufferStatsProvider interface {
+	BufferStats() BufferStats
+}
+
+// ClickStatDAO 按时间桶聚合的点击数存储
+type ClickStatDAO interface {
+	// Incr 按 (短链接, 粒度, 时间桶) 累加点击数，记录不存在时插入
+	Incr(ctx context.Context, stats []ClickStat) error
+	// FindRange 查询时间桶起始时间落在 [start, end) 内的记录，按时间桶升序
+	FindRange(ctx context.Context, shortUrl string, granularity int8, start, end int64) ([]ClickStat, error)
+	// SumClicks 统计指定粒度下的点击数总和
+	SumClicks(ctx context.Context, shortUrl string, granularity int8) (int64, error)
+	// IncrCountries 按 (短链接, 天, 国家) 累加点击数，记录不存在时插入
+	IncrCountries(ctx context.Context, stats []CountryClickStat) error
+	// SumCountries 按国家汇总天落在 [start, end) 内的点击数，按点击数降序
+	SumCountries(ctx context.Context, shortUrl string, start, end int64) ([]CountryClickStat, error)
+	// IncrVariants 按 (短链接, 天, 分流序号) 累加点击数，记录不存在时插入
+	IncrVariants(ctx context.Context, stats []VariantClickStat) error
+	// SumVariants 按分流序号汇总天落在 [start, end) 内的点击数，按序号升序
+	SumVariants(ctx context.Context, shortUrl string, start, end int64) ([]VariantClickStat, error)
+}
+
+// ApiKeyDAO API Key 存储
+type ApiKeyDAO interface {
+	// FindByKeyID 查询 API Key，不存在时返回 ErrDataNotFound
+	FindByKeyID(ctx context.Context, keyID string) (ApiKey, error)
+}
+
+// NeverExpire 表示短链接永不过期
+const NeverExpire int64 = -1
+
 type ShortUrl struct {
-	ShortUrl  string `gorm:"type:char(7) CHARACTER SET ascii COLLATE ascii_bin;not null;primaryKey;column:short_url"`
-	OriginUrl string `gorm:"type:varchar(200) CHARACTER SET ascii COLLATE ascii_bin;not null;default '';uniqueIndex:uk_origin_url"`
+	ShortUrl  string `gorm:"type:varchar(32) CHARACTER SET ascii COLLATE ascii_bin;not null;primaryKey;column:short_url"`
+	OriginUrl string `gorm:"type:varchar(200) CHARACTER SET ascii COLLATE ascii_bin;not null;default '';index:idx_origin_url"`
 	ExpiredAt int64  `gorm:"type:bigint;default '-1':index:idx_expired_at"`
+	// NotBefore 生效时间戳（秒），此前不跳转，0 表示立即生效
+	NotBefore int64 `gorm:"type:bigint;not null;default:0"`
+	// RedirectCode 跳转使用的 HTTP 状态码，0 表示使用 web 层配置的默认值
+	RedirectCode int16 `gorm:"type:smallint;not null;default:0"`
+	// PassThrough 跳转时是否透传查询参数与路径
+	PassThrough bool `gorm:"type:tinyint(1);not null;default:0"`
+	// UtmTemplate 创建时使用的 UTM 模板名，Utm 为合并模板后的查询字符串
+	UtmTemplate string `gorm:"type:varchar(64);not null;default:''"`
+	Utm         string `gorm:"type:varchar(512);not null;default:''"`
+	// PasswordHash 访问密码的 bcrypt 哈希，为空表示无需密码
+	PasswordHash string `gorm:"type:varchar(60);not null;default:''"`
+	// MaxClicks 最大点击次数，0 表示不限；剩余次数保存在 redis 中
+	MaxClicks int64 `gorm:"not null;default:0"`
+	// Rules 按客户端平台与国家选择跳转目标的规则，JSON 编码
+	Rules string `gorm:"type:varchar(4096);not null;default:''"`
+	// Variants A/B 分流的跳转目标与权重，JSON 编码
+	Variants string `gorm:"type:varchar(2048);not null;default:''"`
+}
+
+type ClickStat struct {
+	ShortUrl    string `gorm:"type:varchar(32) CHARACTER SET ascii COLLATE ascii_bin;not null;primaryKey;column:short_url"`
+	Granularity int8   `gorm:"type:tinyint;not null;primaryKey;autoIncrement:false"` // 1 小时，2 天
+	Bucket      int64  `gorm:"type:bigint;not null;primaryKey;autoIncrement:false"`  // 时间桶起始时间戳（秒）
+	Clicks      int64  `gorm:"type:bigint;not null;default:0"`
+}
+
+// CountryClickStat 按天与国家聚合的点击数
+type CountryClickStat struct {
+	ShortUrl string `gorm:"type:varchar(32) CHARACTER SET ascii COLLATE ascii_bin;not null;primaryKey;column:short_url"`
+	Day      int64  `gorm:"type:bigint;not null;primaryKey;autoIncrement:false"` // 当天 0 点的时间戳（秒，UTC）
+	Country  string `gorm:"type:char(2) CHARACTER SET ascii COLLATE ascii_bin;not null;primaryKey"`
+	Clicks   int64  `gorm:"type:bigint;not null;default:0"`
+}
+
+// VariantClickStat 按天与 A/B 分流目标聚合的点击数
+type VariantClickStat struct {
+	ShortUrl string `gorm:"type:varchar(32) CHARACTER SET ascii COLLATE ascii_bin;not null;primaryKey;column:short_url"`
+	Day      int64  `gorm:"type:bigint;not null;primaryKey;autoIncrement:false"`  // 当天 0 点的时间戳（秒，UTC）
+	Variant  int32  `gorm:"type:tinyint;not null;primaryKey;autoIncrement:false"` // 从 1 开始的分流序号
+	Clicks   int64  `gorm:"type:bigint;not null;default:0"`
 }
@@ -0,0 +1,377 @@
+package dao
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// walOp 预写日志中一行记录的类型
+type walOp string
+
+const (
+	walOpInsert walOp = "insert" // 待落库的记录
+	walOpAck    walOp = "ack"    // 已有最终结果（落库、转入死信或被删除）的记录序号
+)
+
+// walEntry 预写日志中的一行
+type walEntry struct {
+	Op   walOp     `json:"op"`
+	Seq  uint64    `json:"seq,omitempty"`
+	Su   *ShortUrl `json:"su,omitempty"`
+	Seqs []uint64  `json:"seqs,omitempty"`
+}
+
+// deadLetter 死信文件中的一行，保存无法落库且无法再通知调用方的记录，供人工处理
+type deadLetter struct {
+	Su     ShortUrl `json:"su"`
+	Reason string   `json:"reason"`
+	At     int64    `json:"at"`
+}
+
+// walCompactMinLines 日志行数不少于该值且超过存活记录数的 walCompactRatio 倍时重写日志
+const (
+	walCompactMinLines = 1024
+	walCompactRatio    = 4
+)
+
+// shortUrlWAL 环形缓冲区的预写日志，每行一条 JSON 编码的 walEntry。
+// 每条记录带有递增序号，写入日志并 fsync 后即可向调用方确认，落库后按序号逐条确认；
+// 进程崩溃后由下次启动时重放尚未确认的记录。
+// 并发的 Append 共用一次 fsync：先写入的记录由后到达的刷盘一并持久化
+type shortUrlWAL struct {
+	mu      sync.Mutex
+	syncMu  sync.Mutex // 串行化刷盘、确认与重写，需先于 mu 获取
+	path    string
+	file    *os.File
+	dead    *os.File            // 死信文件
+	nextSeq uint64              // 下一条记录的序号
+	live    map[uint64]ShortUrl // 已写入日志但尚未确认的记录
+	index   map[string]uint64   // 短链接到其存活记录序号的索引
+	lines   int                 // 日志文件当前行数
+	written uint64              // 已写入文件的批次数
+	synced  uint64              // 已刷盘的批次数
+}
+
+// walRecord 预写日志中一条尚未确认的记录
+type walRecord struct {
+	Seq uint64
+	Su  ShortUrl
+}
+
+// openShortUrlWAL 打开（不存在时创建）预写日志，返回其中尚未确认的记录。
+// 打开时会把存活记录重写为新日志，丢弃已确认的记录和崩溃时可能留下的半行
+func openShortUrlWAL(path string) (*shortUrlWAL, []walRecord, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDONLY, 0644)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	w := &shortUrlWAL{
+		path:    path,
+		nextSeq: 1,
+		live:    make(map[uint64]ShortUrl),
+		index:   make(map[string]uint64),
+	}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 4096), 1<<20)
+	for scanner.Scan() {
+		var entry walEntry
+		// 崩溃时可能留下写了一半的末行，解析失败的行直接跳过
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		switch entry.Op {
+		case walOpInsert:
+			if entry.Su == nil || entry.Su.ShortUrl == "" {
+				continue
+			}
+			w.addLocked(entry.Seq, *entry.Su)
+			w.nextSeq = max(w.nextSeq, entry.Seq+1)
+		case walOpAck:
+			for _, seq := range entry.Seqs {
+				w.removeLocked(seq)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if err := w.rewriteLocked(); err != nil {
+		return nil, nil, err
+	}
+	w.dead, err = os.OpenFile(path+".dead", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		w.file.Close()
+		return nil, nil, err
+	}
+	return w, w.liveLocked(), nil
+}
+
+// Append 将记录追加到日志并刷盘，返回与 sus 一一对应的序号，返回 nil 后记录即视为持久化
+func (w *shortUrlWAL) Append(sus []ShortUrl) ([]uint64, error) {
+	w.mu.Lock()
+	seqs := make([]uint64, 0, len(sus))
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for i := range sus {
+		seq := w.nextSeq + uint64(i)
+		if err := enc.Encode(walEntry{Op: walOpInsert, Seq: seq, Su: &sus[i]}); err != nil {
+			w.mu.Unlock()
+			return nil, err
+		}
+		seqs = append(seqs, seq)
+	}
+	if _, err := w.file.Write(buf.Bytes()); err != nil {
+		w.mu.Unlock()
+		return nil, err
+	}
+	w.nextSeq += uint64(len(sus))
+	for i, seq := range seqs {
+		w.addLocked(seq, sus[i])
+	}
+	w.lines += len(sus)
+	w.written++
+	batch := w.written
+	w.mu.Unlock()
+
+	w.syncMu.Lock()
+	defer w.syncMu.Unlock()
+	// 等待期间其他协程的刷盘或重写已经覆盖本批次
+	if w.synced >= batch {
+		return seqs, nil
+	}
+	w.mu.Lock()
+	target := w.written
+	w.mu.Unlock()
+	if err := w.file.Sync(); err != nil {
+		return nil, err
+	}
+	w.synced = target
+	return seqs, nil
+}
+
+// Ack 按序号确认记录已有最终结果。全部确认时清空日志，已确认的行过多时重写日志
+func (w *shortUrlWAL) Ack(seqs []uint64) error {
+	if len(seqs) == 0 {
+		return nil
+	}
+	w.syncMu.Lock()
+	defer w.syncMu.Unlock()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, seq := range seqs {
+		w.removeLocked(seq)
+	}
+	if len(w.live) == 0 {
+		if err := w.file.Truncate(0); err != nil {
+			return err
+		}
+		w.lines = 0
+		w.synced = w.written
+		return w.file.Sync()
+	}
+	if w.lines >= walCompactMinLines && w.lines > walCompactRatio*len(w.live) {
+		return w.rewriteLocked()
+	}
+
+	line, err := json.Marshal(walEntry{Op: walOpAck, Seqs: seqs})
+	if err != nil {
+		return err
+	}
+	if _, err := w.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	w.lines++
+	w.synced = w.written
+	return w.file.Sync()
+}
+
+// Discard 确认指定短链接尚未落库的记录，用于删除短链接后避免重试或重放把它重新写回。
+// 返回被确认的记录序号
+func (w *shortUrlWAL) Discard(shortUrls []string) ([]uint64, error) {
+	w.mu.Lock()
+	seqs := make([]uint64, 0)
+	for _, shortUrl := range shortUrls {
+		if seq, ok := w.index[shortUrl]; ok {
+			seqs = append(seqs, seq)
+		}
+	}
+	w.mu.Unlock()
+
+	return seqs, w.Ack(seqs)
+}
+
+// DiscardExpired 确认 now 时已过期的未落库记录，用于清理过期短链接后避免重试或重放把它们写回。
+// 返回被确认的记录序号
+func (w *shortUrlWAL) DiscardExpired(now int64) ([]uint64, error) {
+	w.mu.Lock()
+	seqs := make([]uint64, 0)
+	for seq, su := range w.live {
+		if su.ExpiredAt != NeverExpire && su.ExpiredAt < now {
+			seqs = append(seqs, seq)
+		}
+	}
+	w.mu.Unlock()
+
+	return seqs, w.Ack(seqs)
+}
+
+// Update 修改短链接尚未确认的记录：以新序号写入修改后的记录并确认旧记录，两行在同一次写入中刷盘。
+// 返回新记录，短链接没有未确认的记录时返回 false
+func (w *shortUrlWAL) Update(shortUrl string, fn func(su *ShortUrl)) (walRecord, bool, error) {
+	w.syncMu.Lock()
+	defer w.syncMu.Unlock()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	old, ok := w.index[shortUrl]
+	if !ok {
+		return walRecord{}, false, nil
+	}
+	su := w.live[old]
+	fn(&su)
+	seq := w.nextSeq
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	if err := enc.Encode(walEntry{Op: walOpInsert, Seq: seq, Su: &su}); err != nil {
+		return walRecord{}, false, err
+	}
+	if err := enc.Encode(walEntry{Op: walOpAck, Seqs: []uint64{old}}); err != nil {
+		return walRecord{}, false, err
+	}
+	if _, err := w.file.Write(buf.Bytes()); err != nil {
+		return walRecord{}, false, err
+	}
+	w.nextSeq++
+	w.removeLocked(old)
+	w.addLocked(seq, su)
+	w.lines += 2
+	w.written++
+	if err := w.file.Sync(); err != nil {
+		return walRecord{}, false, err
+	}
+	w.synced = w.written
+	return walRecord{Seq: seq, Su: su}, true, nil
+}
+
+// Live 返回序号对应的记录是否仍未确认
+func (w *shortUrlWAL) Live(seq uint64) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, ok := w.live[seq]
+	return ok
+}
+
+// Pending 返回短链接尚未确认的记录
+func (w *shortUrlWAL) Pending(shortUrl string) (ShortUrl, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	seq, ok := w.index[shortUrl]
+	if !ok {
+		return ShortUrl{}, false
+	}
+	return w.live[seq], true
+}
+
+// DeadLetter 将无法落库的记录写入死信文件
+func (w *shortUrlWAL) DeadLetter(su ShortUrl, reason string) error {
+	line, err := json.Marshal(deadLetter{Su: su, Reason: reason, At: time.Now().UnixMilli()})
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.dead.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return w.dead.Sync()
+}
+
+func (w *shortUrlWAL) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return errors.Join(w.file.Close(), w.dead.Close())
+}
+
+func (w *shortUrlWAL) addLocked(seq uint64, su ShortUrl) {
+	w.live[seq] = su
+	w.index[su.ShortUrl] = seq
+}
+
+func (w *shortUrlWAL) removeLocked(seq uint64) {
+	su, ok := w.live[seq]
+	if !ok {
+		return
+	}
+	delete(w.live, seq)
+	if w.index[su.ShortUrl] == seq {
+		delete(w.index, su.ShortUrl)
+	}
+}
+
+// liveLocked 按序号顺序返回存活记录
+func (w *shortUrlWAL) liveLocked() []walRecord {
+	records := make([]walRecord, 0, len(w.live))
+	for seq, su := range w.live {
+		records = append(records, walRecord{Seq: seq, Su: su})
+	}
+	slices.SortFunc(records, func(a, b walRecord) int {
+		return cmp.Compare(a.Seq, b.Seq)
+	})
+	return records
+}
+
+// rewriteLocked 将存活记录写入临时文件并原子替换日志，调用方需持有 syncMu 与 mu（打开时除外）
+func (w *shortUrlWAL) rewriteLocked() error {
+	records := w.liveLocked()
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for i := range records {
+		if err := enc.Encode(walEntry{Op: walOpInsert, Seq: records[i].Seq, Su: &records[i].Su}); err != nil {
+			return err
+		}
+	}
+
+	tmp := w.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(tmp, os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := os.Rename(tmp, w.path); err != nil {
+		f.Close()
+		return err
+	}
+
+	if w.file != nil {
+		w.file.Close()
+	}
+	w.file = f
+	w.lines = len(records)
+	w.synced = w.written
+	return nil
+}
@@ -0,0 +1,155 @@
+package dao
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// walLines 返回日志文件中的行数
+func walLines(t *testing.T, path string) int {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return bytes.Count(data, []byte("\n"))
+}
+
+func walShortUrls(n int) []ShortUrl {
+	sus := make([]ShortUrl, 0, n)
+	for i := 0; i < n; i++ {
+		sus = append(sus, ShortUrl{ShortUrl: fmt.Sprintf("w%03d", i), OriginUrl: fmt.Sprintf("https://example.com/wal/%d", i), ExpiredAt: NeverExpire})
+	}
+	return sus
+}
+
+func TestShortUrlWAL(t *testing.T) {
+	testCases := []struct {
+		name string
+		// run 在打开的日志上执行操作，返回后日志被关闭并重新打开
+		run        func(t *testing.T, w *shortUrlWAL, path string)
+		wantLines  int
+		wantReplay []string
+		// wantOrigins 重放记录中指定短链接的原链接
+		wantOrigins map[string]string
+	}{
+		{
+			name: "只重放未确认的记录",
+			run: func(t *testing.T, w *shortUrlWAL, path string) {
+				seqs, err := w.Append(walShortUrls(3))
+				require.NoError(t, err)
+				require.NoError(t, w.Ack(seqs[1:2]))
+			},
+			wantLines:  2,
+			wantReplay: []string{"w000", "w002"},
+		},
+		{
+			name: "全部确认后清空日志",
+			run: func(t *testing.T, w *shortUrlWAL, path string) {
+				seqs, err := w.Append(walShortUrls(3))
+				require.NoError(t, err)
+				require.NoError(t, w.Ack(seqs[:1]))
+				require.NoError(t, w.Ack(seqs[1:]))
+				assert.Equal(t, 0, walLines(t, path))
+			},
+			wantLines: 0,
+		},
+		{
+			name: "已确认的行过多时重写日志",
+			run: func(t *testing.T, w *shortUrlWAL, path string) {
+				seqs, err := w.Append(walShortUrls(walCompactMinLines))
+				require.NoError(t, err)
+				require.NoError(t, w.Ack(seqs[1:]))
+				assert.Equal(t, 1, walLines(t, path))
+			},
+			wantLines:  1,
+			wantReplay: []string{"w000"},
+		},
+		{
+			name: "删除的短链接不再重放",
+			run: func(t *testing.T, w *shortUrlWAL, path string) {
+				_, err := w.Append(walShortUrls(2))
+				require.NoError(t, err)
+				seqs, err := w.Discard([]string{"w001", "wNone"})
+				require.NoError(t, err)
+				assert.Len(t, seqs, 1)
+			},
+			wantLines:  1,
+			wantReplay: []string{"w000"},
+		},
+		{
+			name: "修改未确认的记录后重放修改后的值",
+			run: func(t *testing.T, w *shortUrlWAL, path string) {
+				_, err := w.Append(walShortUrls(2))
+				require.NoError(t, err)
+				record, ok, err := w.Update("w001", func(su *ShortUrl) { su.OriginUrl = "https://example.com/edited" })
+				require.NoError(t, err)
+				require.True(t, ok)
+				assert.True(t, w.Live(record.Seq))
+				_, ok, err = w.Update("wNone", func(su *ShortUrl) {})
+				require.NoError(t, err)
+				assert.False(t, ok)
+			},
+			wantLines:   2,
+			wantReplay:  []string{"w000", "w001"},
+			wantOrigins: map[string]string{"w001": "https://example.com/edited"},
+		},
+		{
+			name: "确认已过期的记录",
+			run: func(t *testing.T, w *shortUrlWAL, path string) {
+				sus := walShortUrls(3)
+				sus[0].ExpiredAt = 100
+				sus[1].ExpiredAt = 200
+				_, err := w.Append(sus)
+				require.NoError(t, err)
+				seqs, err := w.DiscardExpired(150)
+				require.NoError(t, err)
+				assert.Len(t, seqs, 1)
+			},
+			wantLines:  2,
+			wantReplay: []string{"w001", "w002"},
+		},
+		{
+			name: "跳过崩溃时写了一半的末行",
+			run: func(t *testing.T, w *shortUrlWAL, path string) {
+				_, err := w.Append(walShortUrls(1))
+				require.NoError(t, err)
+				_, err = w.file.WriteString(`{"op":"insert","seq":2,"su":{"short_u`)
+				require.NoError(t, err)
+			},
+			wantLines:  1,
+			wantReplay: []string{"w000"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "short_url.wal")
+			w, records, err := openShortUrlWAL(path)
+			require.NoError(t, err)
+			require.Empty(t, records)
+			tc.run(t, w, path)
+			require.NoError(t, w.Close())
+
+			w, records, err = openShortUrlWAL(path)
+			require.NoError(t, err)
+			defer w.Close()
+			replay := make([]string, 0, len(records))
+			for _, record := range records {
+				replay = append(replay, record.Su.ShortUrl)
+			}
+			assert.ElementsMatch(t, tc.wantReplay, replay)
+			assert.Equal(t, tc.wantLines, walLines(t, path))
+
+			// 重新打开后新记录的序号不与存活记录重复
+			seqs, err := w.Append(walShortUrls(1))
+			require.NoError(t, err)
+			for _, record := range records {
+				assert.Less(t, record.Seq, seqs[0])
+			}
+		})
+	}
+}
@@ -2,10 +2,14 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand/v2"
+	"short_url/rpc/domain"
 	"short_url/rpc/repository/cache"
 	"short_url/rpc/repository/dao"
+	"strings"
 	"time"
 
 	"github.com/to404hanga/pkg404/cachex/lru"
@@ -18,41 +22,202 @@ type CachedShortUrlRepository struct {
 	lruExpiration time.Duration
 	cache         cache.ShortUrlCache
 	bloomFilter   cache.BloomFilterCache
+	invalidator   cache.CacheInvalidator
+	budget        cache.ClickBudgetCache
 	dao           dao.ShortUrlDAO
 	l             logger.Logger
 	requestGroup  singleflight.Group
 }
 
+// 延迟双删的间隔
+const invalidateDelay = 500 * time.Millisecond
+
+// 点击预算计数器比短链接多保留的时长，避免时钟误差导致计数器先于短链接过期而提前耗尽
+const clickBudgetGrace = time.Hour
+
 type lruItem struct {
-	originUrl string
+	su        domain.ShortUrl
 	expiredAt int64
 }
 
+// cachedShortUrl redis 缓存中保存的跳转信息，编码为 JSON
+type cachedShortUrl struct {
+	OriginUrl    string        `json:"origin_url"`
+	ExpiredAt    int64         `json:"expired_at,omitempty"`
+	NotBefore    int64         `json:"not_before,omitempty"`
+	RedirectCode int           `json:"redirect_code,omitempty"`
+	PassThrough  bool          `json:"pass_through,omitempty"`
+	Utm          string        `json:"utm,omitempty"`
+	PasswordHash string        `json:"password_hash,omitempty"`
+	MaxClicks    int64         `json:"max_clicks,omitempty"`
+	Rules        []routingRule `json:"rules,omitempty"`
+	Variants     []variant     `json:"variants,omitempty"`
+}
+
+// variant A/B 分流目标在 redis 缓存与数据库中的 JSON 编码
+type variant struct {
+	Target string `json:"target"`
+	Weight int32  `json:"weight"`
+}
+
+func toVariants(variants []domain.Variant) []variant {
+	if len(variants) == 0 {
+		return nil
+	}
+	res := make([]variant, 0, len(variants))
+	for _, v := range variants {
+		res = append(res, variant{Target: v.Target, Weight: v.Weight})
+	}
+	return res
+}
+
+func fromVariants(variants []variant) []domain.Variant {
+	if len(variants) == 0 {
+		return nil
+	}
+	res := make([]domain.Variant, 0, len(variants))
+	for _, v := range variants {
+		res = append(res, domain.Variant{Target: v.Target, Weight: v.Weight})
+	}
+	return res
+}
+
+// encodeVariants 编码为数据库中保存的 JSON，没有分流目标时为空字符串
+func encodeVariants(variants []domain.Variant) string {
+	if len(variants) == 0 {
+		return ""
+	}
+	val, _ := json.Marshal(toVariants(variants))
+	return string(val)
+}
+
+func decodeVariants(val string) []domain.Variant {
+	var variants []variant
+	if val == "" || json.Unmarshal([]byte(val), &variants) != nil {
+		return nil
+	}
+	return fromVariants(variants)
+}
+
+// routingRule 跳转规则在 redis 缓存与数据库中的 JSON 编码
+type routingRule struct {
+	Platform int8   `json:"platform,omitempty"`
+	Country  string `json:"country,omitempty"`
+	Target   string `json:"target"`
+}
+
+func toRoutingRules(rules []domain.RoutingRule) []routingRule {
+	if len(rules) == 0 {
+		return nil
+	}
+	res := make([]routingRule, 0, len(rules))
+	for _, r := range rules {
+		res = append(res, routingRule{Platform: int8(r.Platform), Country: r.Country, Target: r.Target})
+	}
+	return res
+}
+
+func fromRoutingRules(rules []routingRule) []domain.RoutingRule {
+	if len(rules) == 0 {
+		return nil
+	}
+	res := make([]domain.RoutingRule, 0, len(rules))
+	for _, r := range rules {
+		res = append(res, domain.RoutingRule{Platform: domain.Platform(r.Platform), Country: r.Country, Target: r.Target})
+	}
+	return res
+}
+
+// encodeRoutingRules 编码为数据库中保存的 JSON，没有规则时为空字符串
+func encodeRoutingRules(rules []domain.RoutingRule) string {
+	if len(rules) == 0 {
+		return ""
+	}
+	val, _ := json.Marshal(toRoutingRules(rules))
+	return string(val)
+}
+
+func decodeRoutingRules(val string) []domain.RoutingRule {
+	var rules []routingRule
+	if val == "" || json.Unmarshal([]byte(val), &rules) != nil {
+		return nil
+	}
+	return fromRoutingRules(rules)
+}
+
+func encodeCachedShortUrl(su domain.ShortUrl) string {
+	val, _ := json.Marshal(cachedShortUrl{
+		OriginUrl:    su.OriginUrl,
+		ExpiredAt:    su.ExpiredAt,
+		NotBefore:    su.NotBefore,
+		RedirectCode: su.RedirectCode,
+		PassThrough:  su.PassThrough,
+		Utm:          su.Utm.Encode(),
+		PasswordHash: su.PasswordHash,
+		MaxClicks:    su.MaxClicks,
+		Rules:        toRoutingRules(su.Rules),
+		Variants:     toVariants(su.Variants),
+	})
+	return string(val)
+}
+
+// decodeCachedShortUrl 解析 redis 缓存值，兼容升级前直接保存原链接的旧缓存。
+// 旧缓存与未记录过期时间的缓存视为永不过期，由缓存自身的过期时间兜底
+func decodeCachedShortUrl(shortUrl, val string) domain.ShortUrl {
+	var cached cachedShortUrl
+	if !strings.HasPrefix(val, "{") || json.Unmarshal([]byte(val), &cached) != nil {
+		cached = cachedShortUrl{OriginUrl: val}
+	}
+	if cached.ExpiredAt == 0 {
+		cached.ExpiredAt = domain.NeverExpire
+	}
+	return domain.ShortUrl{
+		ShortUrl:     shortUrl,
+		OriginUrl:    cached.OriginUrl,
+		ExpiredAt:    cached.ExpiredAt,
+		NotBefore:    cached.NotBefore,
+		RedirectCode: cached.RedirectCode,
+		PassThrough:  cached.PassThrough,
+		Utm:          domain.ParseUtm(cached.Utm),
+		PasswordHash: cached.PasswordHash,
+		MaxClicks:    cached.MaxClicks,
+		Rules:        fromRoutingRules(cached.Rules),
+		Variants:     fromVariants(cached.Variants),
+	}
+}
+
 var _ ShortUrlRepository = (*CachedShortUrlRepository)(nil)
 
 var (
 	ErrPrimaryKeyConflict  = dao.ErrPrimaryKeyConflict
 	ErrUniqueIndexConflict = dao.ErrUniqueIndexConflict
+	ErrDataNotFound        = dao.ErrDataNotFound
+	ErrBufferFull          = dao.ErrBufferFull
+	// ErrNotYetAvailable 短链接尚未到生效时间，ResolveShortUrl 返回该错误时同时返回短链接信息
+	ErrNotYetAvailable = errors.New("short url not yet available")
 )
 
-func NewCachedShortUrlRepository(lruSize int, lruExpiration time.Duration, cache cache.ShortUrlCache, bloomFilter cache.BloomFilterCache, dao dao.ShortUrlDAO, l logger.Logger) ShortUrlRepository {
+func NewCachedShortUrlRepository(lruSize int, lruExpiration time.Duration, cache cache.ShortUrlCache, bloomFilter cache.BloomFilterCache, invalidator cache.CacheInvalidator, budget cache.ClickBudgetCache, dao dao.ShortUrlDAO, l logger.Logger) ShortUrlRepository {
 	lru, err := lru.New(lruSize)
 	if err != nil {
 		panic(err)
 	}
-	return &CachedShortUrlRepository{
+	repo := &CachedShortUrlRepository{
 		lru:           lru,
 		lruExpiration: lruExpiration,
 		cache:         cache,
 		bloomFilter:   bloomFilter,
+		invalidator:   invalidator,
+		budget:        budget,
 		dao:           dao,
 		l:             l,
 		requestGroup:  singleflight.Group{},
 	}
-
+	go repo.watchInvalidation()
+	return repo
 }
 
-func (c *CachedShortUrlRepository) GetOriginUrlByShortUrl(ctx context.Context, shortUrl string) (string, error) {
+func (c *CachedShortUrlRepository) ResolveShortUrl(ctx context.Context, shortUrl string) (domain.ShortUrl, error) {
 	now := time.Now().Unix()
 
 	result, err, _ := c.requestGroup.Do("lru_redis_"+shortUrl, func() (interface{}, error) {
@@ -60,13 +225,14 @@ func (c *CachedShortUrlRepository) GetOriginUrlByShortUrl(ctx context.Context, s
 		val, ok := c.lru.Get(shortUrl)
 		if ok {
 			if item, ok := val.(lruItem); ok && item.expiredAt >= now {
-				return item.originUrl, nil
+				return item.su, nil
 			}
 		}
 
 		// 若本地缓存不存在，从 redis 读取并更新本地缓存
-		originUrl, err := c.cache.Get(ctx, shortUrl)
+		cached, err := c.cache.Get(ctx, shortUrl)
 		if err == nil {
+			su := decodeCachedShortUrl(shortUrl, cached)
 			go func() {
 				newCtx, cancel := context.WithTimeout(context.Background(), time.Second)
 				defer cancel()
@@ -79,12 +245,9 @@ func (c *CachedShortUrlRepository) GetOriginUrlByShortUrl(ctx context.Context, s
 				}
 			}()
 
-			c.lru.Add(shortUrl, lruItem{
-				originUrl: originUrl,
-				expiredAt: int64(time.Now().Add(time.Duration(c.lruExpiration.Seconds()+float64(rand.IntN(7201)-3600)) * time.Second).Unix()),
-			})
+			c.addLocal(su)
 
-			return originUrl, err
+			return su, err
 		}
 		c.l.Error("cache.Get failed",
 			logger.Error(err),
@@ -123,23 +286,31 @@ func (c *CachedShortUrlRepository) GetOriginUrlByShortUrl(ctx context.Context, s
 			} else if !exists {
 				// 布隆过滤器显示短链接不存在，直接返回错误
 				// 注意：这里可能存在假阳性，但为了性能考虑，我们信任布隆过滤器的结果
-				return "", fmt.Errorf("short url not found: %s", shortUrl)
+				return domain.ShortUrl{}, fmt.Errorf("short url not found: %s: %w", shortUrl, ErrDataNotFound)
 			}
 		}
 
 		// 若 redis 读取失败，从数据库读取并更新本地 lru 缓存和 redis 缓存
-		su, err := c.dao.FindByShortUrlWithExpired(ctx, shortUrl, now)
+		entity, err := c.dao.FindByShortUrlWithExpired(ctx, shortUrl, now)
 		fmt.Println("查询数据库")
+		if errors.Is(err, ErrDataNotFound) {
+			// 尚未生效的短链接同样需要缓存，以便在生效前返回 ErrNotYetAvailable 而不是反复查库
+			entity, err = c.dao.FindByShortUrl(ctx, shortUrl)
+			if err == nil && (c.toDomain(entity).IsExpired(now) || c.toDomain(entity).IsActive(now)) {
+				err = ErrDataNotFound
+			}
+		}
 		if err != nil {
-			return "", err
+			return domain.ShortUrl{}, err
 		}
+		su := c.toDomain(entity)
 		fmt.Println(c.bloomFilter.Exist(ctx, shortUrl))
 		go func() {
 			newCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 			defer cancel()
 
 			// 异步更新 redis 缓存
-			if err = c.cache.Set(newCtx, shortUrl, su.OriginUrl); err != nil {
+			if err = c.cache.Set(newCtx, shortUrl, encodeCachedShortUrl(su)); err != nil {
 				c.l.Error("failed to set redis cache",
 					logger.Error(err),
 					logger.String("short_url", shortUrl),
@@ -148,31 +319,49 @@ func (c *CachedShortUrlRepository) GetOriginUrlByShortUrl(ctx context.Context, s
 			}
 		}()
 		// 同步更新本地 lru 缓存
-		c.lru.Add(shortUrl, lruItem{
-			originUrl: su.OriginUrl,
-			expiredAt: int64(time.Now().Add(time.Duration(c.lruExpiration.Seconds()+float64(rand.IntN(7201)-3600)) * time.Second).Unix()),
-		})
+		c.addLocal(su)
 
-		return su.OriginUrl, nil
+		return su, nil
 	})
 	if err != nil {
-		return "", err
+		return domain.ShortUrl{}, err
+	}
+
+	// 缓存中的跳转信息可能已过期或尚未生效，每次都按当前时间校验
+	su := result.(domain.ShortUrl)
+	if su.IsExpired(now) {
+		c.lru.Remove(shortUrl)
+		return domain.ShortUrl{}, fmt.Errorf("short url expired: %s: %w", shortUrl, ErrDataNotFound)
+	}
+	if !su.IsActive(now) {
+		return su, ErrNotYetAvailable
 	}
+	return su, nil
+}
 
-	return result.(string), nil
+// addLocal 写入本地 lru 缓存，本地缓存不能比短链接本身存活更久
+func (c *CachedShortUrlRepository) addLocal(su domain.ShortUrl) {
+	lruExpiredAt := int64(time.Now().Add(time.Duration(c.lruExpiration.Seconds()+float64(rand.IntN(7201)-3600)) * time.Second).Unix())
+	if su.ExpiredAt != domain.NeverExpire && su.ExpiredAt < lruExpiredAt {
+		lruExpiredAt = su.ExpiredAt
+	}
+	c.lru.Add(su.ShortUrl, lruItem{
+		su:        su,
+		expiredAt: lruExpiredAt,
+	})
 }
 
-func (c *CachedShortUrlRepository) InsertShortUrl(ctx context.Context, shortUrl, originUrl string) error {
+func (c *CachedShortUrlRepository) InsertShortUrl(ctx context.Context, su domain.ShortUrl) error {
+	shortUrl := su.ShortUrl
 
 	// 插入数据库
-	err := c.dao.Insert(ctx, dao.ShortUrl{
-		ShortUrl:  shortUrl,
-		OriginUrl: originUrl,
-		ExpiredAt: time.Now().AddDate(1, 0, 0).Unix(), // 有效期一年
-	})
+	err := c.dao.Insert(ctx, c.toEntity(su))
 	if err != nil {
 		return err
 	}
+	if err := c.resetBudget(ctx, su); err != nil {
+		return err
+	}
 	//每一条都开协程去处理太麻烦了，这里应该优化为协程池消息处理，但是暂时注释，先进性测试
 	//异步添加到布隆过滤器
 	go func() {
@@ -191,43 +380,223 @@ func (c *CachedShortUrlRepository) InsertShortUrl(ctx context.Context, shortUrl,
 	return nil
 }
 
-func (c *CachedShortUrlRepository) DeleteShortUrlByShortUrl(ctx context.Context, shortUrl string) error {
-	err := c.dao.DeleteByShortUrl(ctx, shortUrl)
-	if err == nil {
-		go func() {
-			newCtx, cancel := context.WithTimeout(ctx, 1*time.Second)
-			defer cancel()
+// BatchInsertShortUrl 批量写入短链接，整批进入 dao 的环形缓冲区，返回与 sus 一一对应的写入结果
+func (c *CachedShortUrlRepository) BatchInsertShortUrl(ctx context.Context, sus []domain.ShortUrl) ([]error, error) {
+	entities := make([]dao.ShortUrl, 0, len(sus))
+	for _, su := range sus {
+		entities = append(entities, c.toEntity(su))
+	}
+	errs, err := c.dao.BatchInsert(ctx, entities)
+	if err != nil {
+		return nil, err
+	}
+	for i, su := range sus {
+		if errs[i] == nil {
+			errs[i] = c.resetBudget(ctx, su)
+		}
+	}
 
-			// 异步删除 redis 缓存
-			if err = c.cache.Del(newCtx, shortUrl); err != nil {
-				c.l.Error("failed to delete redis cache",
+	// 整批共用一个协程异步将写入成功的短链接添加到布隆过滤器
+	go func() {
+		newCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		for i, su := range entities {
+			if errs[i] != nil {
+				continue
+			}
+			if err := c.bloomFilter.Set(newCtx, su.ShortUrl); err != nil {
+				c.l.Error("failed to add to bloom filter",
 					logger.Error(err),
-					logger.String("short_url", shortUrl),
+					logger.String("short_url", su.ShortUrl),
 				)
 			}
-		}()
-		// 同步删除本地 lru 缓存
-		c.lru.Remove(shortUrl)
+		}
+	}()
+
+	return errs, nil
+}
+
+// ReserveShortUrl 同步占用指定的短链接（自定义别名），冲突时返回 ErrPrimaryKeyConflict
+func (c *CachedShortUrlRepository) ReserveShortUrl(ctx context.Context, su domain.ShortUrl) error {
+	shortUrl := su.ShortUrl
+
+	err := c.dao.Reserve(ctx, c.toEntity(su))
+	if err != nil {
+		return err
+	}
+	if err := c.resetBudget(ctx, su); err != nil {
+		return err
+	}
+
+	go func() {
+		newCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		if err := c.bloomFilter.Set(newCtx, shortUrl); err != nil {
+			c.l.Error("failed to add to bloom filter",
+				logger.Error(err),
+				logger.String("short_url", shortUrl),
+			)
+		}
+	}()
+
+	return nil
+}
+
+func (c *CachedShortUrlRepository) DeleteShortUrlByShortUrl(ctx context.Context, shortUrl string) error {
+	err := c.dao.DeleteByShortUrl(ctx, shortUrl)
+	if err == nil {
+		// 布隆过滤器不支持删除，残留的位只会造成误判放行，由每日重建清理
+		c.invalidate(ctx, shortUrl)
+		if err := c.budget.Del(ctx, shortUrl); err != nil {
+			c.l.Error("failed to delete click budget",
+				logger.Error(err),
+				logger.String("short_url", shortUrl),
+			)
+		}
 	}
 	return err
 }
 
+func (c *CachedShortUrlRepository) ConsumeClick(ctx context.Context, su domain.ShortUrl) (int64, error) {
+	return c.budget.Consume(ctx, su.ShortUrl)
+}
+
+// resetBudget 为真正写入的短链接创建点击预算计数器，新建的短链接可能复用了已删除短链接的短码，需覆盖残留的计数器。
+// 只能在写入成功后调用，重复创建时覆盖会让已消耗的预算重新可用。
+// 计数器缺失时按预算耗尽处理，写入失败时短链接无法访问，返回错误由调用方告知创建失败
+func (c *CachedShortUrlRepository) resetBudget(ctx context.Context, su domain.ShortUrl) error {
+	if su.MaxClicks <= 0 {
+		return nil
+	}
+	if err := c.budget.Reset(ctx, su.ShortUrl, su.MaxClicks, clickBudgetTTL(su.ExpiredAt)); err != nil {
+		c.l.Error("failed to reset click budget",
+			logger.Error(err),
+			logger.String("short_url", su.ShortUrl),
+		)
+		return err
+	}
+	return nil
+}
+
+// clickBudgetTTL 计数器的过期时间为短链接剩余有效期加上 clickBudgetGrace，永不过期的短链接返回 0
+func clickBudgetTTL(expiredAt int64) time.Duration {
+	if expiredAt == domain.NeverExpire {
+		return 0
+	}
+	return max(time.Until(time.Unix(expiredAt, 0)), 0) + clickBudgetGrace
+}
+
+// FindShortUrl 查询短链接详情，包含已过期但尚未清理的短链接
+func (c *CachedShortUrlRepository) FindShortUrl(ctx context.Context, shortUrl string) (domain.ShortUrl, error) {
+	su, err := c.dao.FindByShortUrl(ctx, shortUrl)
+	if err != nil {
+		return domain.ShortUrl{}, err
+	}
+	return c.toDomain(su), nil
+}
+
+func (c *CachedShortUrlRepository) UpdateOriginUrl(ctx context.Context, shortUrl, originUrl string) error {
+	err := c.dao.UpdateOriginUrl(ctx, shortUrl, originUrl)
+	if err == nil {
+		c.invalidate(ctx, shortUrl)
+	}
+	return err
+}
+
+func (c *CachedShortUrlRepository) UpdateExpiredAt(ctx context.Context, shortUrl string, expiredAt int64) error {
+	daoExpiredAt := expiredAt
+	if expiredAt == domain.NeverExpire {
+		daoExpiredAt = dao.NeverExpire
+	}
+	err := c.dao.UpdateExpiredAt(ctx, shortUrl, daoExpiredAt)
+	if err != nil {
+		return err
+	}
+	c.invalidate(ctx, shortUrl)
+
+	// 点击预算计数器随短链接一同续期或过期
+	if err := c.budget.Expire(ctx, shortUrl, clickBudgetTTL(expiredAt)); err != nil {
+		c.l.Error("failed to update click budget expiration",
+			logger.Error(err),
+			logger.String("short_url", shortUrl),
+		)
+	}
+
+	// 已过期的短链接可能已在重建时被移出布隆过滤器，续期后需要重新加入
+	if err := c.bloomFilter.Set(ctx, shortUrl); err != nil {
+		c.l.Error("failed to add to bloom filter",
+			logger.Error(err),
+			logger.String("short_url", shortUrl),
+		)
+	}
+	return nil
+}
+
+// invalidate 使短链接的各级缓存失效：同步删除本地 lru 与 redis 缓存并广播给其他实例，
+// 随后延迟再删一次，避免并发读取在删除之后又回填旧值
+func (c *CachedShortUrlRepository) invalidate(ctx context.Context, shortUrl string) {
+	c.requestGroup.Forget("lru_redis_" + shortUrl)
+	c.lru.Remove(shortUrl)
+	c.evict(ctx, shortUrl)
+
+	go func() {
+		time.Sleep(invalidateDelay)
+		newCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		c.lru.Remove(shortUrl)
+		c.evict(newCtx, shortUrl)
+	}()
+}
+
+// evict 删除 redis 缓存并通知所有实例删除本地 lru 缓存
+func (c *CachedShortUrlRepository) evict(ctx context.Context, shortUrl string) {
+	if err := c.cache.Del(ctx, shortUrl); err != nil {
+		c.l.Error("failed to delete redis cache",
+			logger.Error(err),
+			logger.String("short_url", shortUrl),
+		)
+	}
+	if err := c.invalidator.Publish(ctx, shortUrl); err != nil {
+		c.l.Error("failed to publish cache invalidation",
+			logger.Error(err),
+			logger.String("short_url", shortUrl),
+		)
+	}
+}
+
+// watchInvalidation 订阅其他实例发出的缓存失效消息，订阅中断后自动重连
+func (c *CachedShortUrlRepository) watchInvalidation() {
+	for {
+		err := c.invalidator.Subscribe(context.Background(), func(shortUrl string) {
+			c.lru.Remove(shortUrl)
+		})
+		c.l.Error("cache invalidation subscription interrupted, retrying",
+			logger.Error(err),
+		)
+		time.Sleep(time.Second)
+	}
+}
+
+// CleanExpired 删除过期短链接，并异步清理它们的缓存与点击预算计数器。
+// 部分分表删除失败时，已删除的短链接同样会被清理
 func (c *CachedShortUrlRepository) CleanExpired(ctx context.Context, now int64) error {
 	deleteList, err := c.dao.DeleteExpiredList(ctx, now)
-	if err == nil {
-		newCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	if len(deleteList) > 0 {
 		go func() {
+			newCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 			defer cancel()
 			for _, shortUrl := range deleteList {
-				// 异步删除 redis 缓存
-				if err = c.cache.Del(newCtx, shortUrl); err != nil {
-					c.l.Error("failed to delete redis cache",
+				// 删除本地与 redis 缓存，并通知其他实例删除本地缓存
+				c.invalidate(newCtx, shortUrl)
+				if err := c.budget.Del(newCtx, shortUrl); err != nil {
+					c.l.Error("failed to delete click budget",
 						logger.Error(err),
 						logger.String("short_url", shortUrl),
 					)
 				}
-				// 异步删除本地 lru 缓存
-				c.lru.Remove(shortUrl)
 			}
 		}()
 	}
@@ -259,3 +628,45 @@ func (c *CachedShortUrlRepository) RebuildBloomFilter(ctx context.Context) error
 
 	return nil
 }
+
+func (c *CachedShortUrlRepository) toEntity(su domain.ShortUrl) dao.ShortUrl {
+	expiredAt := su.ExpiredAt
+	if expiredAt == domain.NeverExpire {
+		expiredAt = dao.NeverExpire
+	}
+	return dao.ShortUrl{
+		ShortUrl:     su.ShortUrl,
+		OriginUrl:    su.OriginUrl,
+		ExpiredAt:    expiredAt,
+		NotBefore:    su.NotBefore,
+		RedirectCode: int16(su.RedirectCode),
+		PassThrough:  su.PassThrough,
+		UtmTemplate:  su.UtmTemplate,
+		Utm:          su.Utm.Encode(),
+		PasswordHash: su.PasswordHash,
+		MaxClicks:    su.MaxClicks,
+		Rules:        encodeRoutingRules(su.Rules),
+		Variants:     encodeVariants(su.Variants),
+	}
+}
+
+func (c *CachedShortUrlRepository) toDomain(su dao.ShortUrl) domain.ShortUrl {
+	expiredAt := su.ExpiredAt
+	if expiredAt == dao.NeverExpire {
+		expiredAt = domain.NeverExpire
+	}
+	return domain.ShortUrl{
+		ShortUrl:     su.ShortUrl,
+		OriginUrl:    su.OriginUrl,
+		ExpiredAt:    expiredAt,
+		NotBefore:    su.NotBefore,
+		RedirectCode: int(su.RedirectCode),
+		PassThrough:  su.PassThrough,
+		UtmTemplate:  su.UtmTemplate,
+		Utm:          domain.ParseUtm(su.Utm),
+		PasswordHash: su.PasswordHash,
+		MaxClicks:    su.MaxClicks,
+		Rules:        decodeRoutingRules(su.Rules),
+		Variants:     decodeVariants(su.Variants),
+	}
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"short_url/rpc/domain"
+	"short_url/rpc/repository/cache"
+	"short_url/rpc/repository/dao"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/to404hanga/pkg404/cachex/lru"
+	"github.com/to404hanga/pkg404/logger"
+)
+
+// fakeShortUrlDAO 只实现写入与过期清理，所有写入都返回 err
+type fakeShortUrlDAO struct {
+	dao.ShortUrlDAO
+	err     error
+	expired []string
+}
+
+func (d *fakeShortUrlDAO) DeleteExpiredList(ctx context.Context, now int64) ([]string, error) {
+	return d.expired, d.err
+}
+
+func (d *fakeShortUrlDAO) Insert(ctx context.Context, su dao.ShortUrl) error {
+	return d.err
+}
+
+func (d *fakeShortUrlDAO) BatchInsert(ctx context.Context, sus []dao.ShortUrl) ([]error, error) {
+	errs := make([]error, len(sus))
+	for i := range errs {
+		errs[i] = d.err
+	}
+	return errs, nil
+}
+
+// fakeClickBudget 记录被重置与删除的计数器
+type fakeClickBudget struct {
+	cache.ClickBudgetCache
+	mu      sync.Mutex
+	resets  []string
+	deleted []string
+	err     error
+}
+
+func (b *fakeClickBudget) Reset(ctx context.Context, shortUrl string, budget int64, ttl time.Duration) error {
+	b.resets = append(b.resets, shortUrl)
+	return b.err
+}
+
+func (b *fakeClickBudget) Del(ctx context.Context, shortUrl string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.deleted = append(b.deleted, shortUrl)
+	return b.err
+}
+
+// recordedKeys 并发记录被操作的短链接，用于同时充当 redis 缓存与缓存失效通知
+type recordedKeys struct {
+	cache.ShortUrlCache
+	cache.CacheInvalidator
+	mu   sync.Mutex
+	keys map[string]int
+}
+
+func (r *recordedKeys) record(shortUrl string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys[shortUrl]++
+	return nil
+}
+
+func (r *recordedKeys) count(shortUrl string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.keys[shortUrl]
+}
+
+func (r *recordedKeys) Del(ctx context.Context, shortUrl string) error {
+	return r.record(shortUrl)
+}
+
+func (r *recordedKeys) Publish(ctx context.Context, shortUrl string) error {
+	return r.record(shortUrl)
+}
+
+type nopBloomFilter struct {
+	cache.BloomFilterCache
+}
+
+func (nopBloomFilter) Set(ctx context.Context, shortUrl string) error {
+	return nil
+}
+
+func TestCachedShortUrlRepository_InsertResetsBudget(t *testing.T) {
+	errBudget := errors.New("redis unavailable")
+	testCases := []struct {
+		name       string
+		su         domain.ShortUrl
+		daoErr     error
+		budgetErr  error
+		wantResets []string
+		wantErr    error
+	}{
+		{
+			name:       "写入成功时创建计数器",
+			su:         domain.ShortUrl{ShortUrl: "once", MaxClicks: 1},
+			wantResets: []string{"once"},
+		},
+		{
+			name:    "重复创建时不覆盖已有计数器",
+			su:      domain.ShortUrl{ShortUrl: "once", MaxClicks: 1},
+			daoErr:  ErrUniqueIndexConflict,
+			wantErr: ErrUniqueIndexConflict,
+		},
+		{
+			name: "不限次数时不创建计数器",
+			su:   domain.ShortUrl{ShortUrl: "open"},
+		},
+		{
+			name:       "计数器写入失败时返回错误",
+			su:         domain.ShortUrl{ShortUrl: "once", MaxClicks: 1},
+			budgetErr:  errBudget,
+			wantResets: []string{"once"},
+			wantErr:    errBudget,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// 单条与批量写入的结果应一致
+			for _, batch := range []bool{false, true} {
+				budget := &fakeClickBudget{err: tc.budgetErr}
+				repo := &CachedShortUrlRepository{
+					bloomFilter: nopBloomFilter{},
+					budget:      budget,
+					dao:         &fakeShortUrlDAO{err: tc.daoErr},
+					l:           logger.NewNopLogger(),
+				}
+				var err error
+				if batch {
+					var errs []error
+					errs, err = repo.BatchInsertShortUrl(context.Background(), []domain.ShortUrl{tc.su})
+					require.NoError(t, err)
+					err = errs[0]
+				} else {
+					err = repo.InsertShortUrl(context.Background(), tc.su)
+				}
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.Equal(t, tc.wantResets, budget.resets)
+			}
+		})
+	}
+}
+
+func TestCachedShortUrlRepository_CleanExpired(t *testing.T) {
+	l, err := lru.New(16)
+	require.NoError(t, err)
+	l.Add("expired", lruItem{su: domain.ShortUrl{ShortUrl: "expired"}})
+	l.Add("live", lruItem{su: domain.ShortUrl{ShortUrl: "live"}})
+
+	errPartial := errors.New("shard unavailable")
+	budget := &fakeClickBudget{}
+	redisCache := &recordedKeys{keys: map[string]int{}}
+	invalidator := &recordedKeys{keys: map[string]int{}}
+	repo := &CachedShortUrlRepository{
+		lru:         l,
+		cache:       redisCache,
+		invalidator: invalidator,
+		budget:      budget,
+		// 部分分表失败时已删除的短链接同样需要清理
+		dao: &fakeShortUrlDAO{expired: []string{"expired"}, err: errPartial},
+		l:   logger.NewNopLogger(),
+	}
+	assert.ErrorIs(t, repo.CleanExpired(context.Background(), time.Now().Unix()), errPartial)
+
+	require.Eventually(t, func() bool {
+		budget.mu.Lock()
+		defer budget.mu.Unlock()
+		return len(budget.deleted) == 1
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"expired"}, budget.deleted)
+	assert.Positive(t, redisCache.count("expired"))
+	assert.Positive(t, invalidator.count("expired"))
+	_, ok := l.Get("expired")
+	assert.False(t, ok)
+	_, ok = l.Get("live")
+	assert.True(t, ok)
+}
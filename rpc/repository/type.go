@@ -1,11 +1,53 @@
 package repository
 
-import "context"
+import (
+	"context"
+	"short_url/rpc/domain"
+)
 
 type ShortUrlRepository interface {
-	GetOriginUrlByShortUrl(ctx context.Context, shortUrl string) (string, error)
-	InsertShortUrl(ctx context.Context, shortUrl, originUrl string) error
+	// ResolveShortUrl 查询未过期短链接的跳转信息，依次经过本地缓存、redis 缓存与布隆过滤器。
+	// 尚未到生效时间时同时返回短链接信息与 ErrNotYetAvailable
+	ResolveShortUrl(ctx context.Context, shortUrl string) (domain.ShortUrl, error)
+	InsertShortUrl(ctx context.Context, su domain.ShortUrl) error
+	BatchInsertShortUrl(ctx context.Context, sus []domain.ShortUrl) ([]error, error)
+	ReserveShortUrl(ctx context.Context, su domain.ShortUrl) error
+	FindShortUrl(ctx context.Context, shortUrl string) (domain.ShortUrl, error)
+	UpdateOriginUrl(ctx context.Context, shortUrl, originUrl string) error
+	UpdateExpiredAt(ctx context.Context, shortUrl string, expiredAt int64) error
 	DeleteShortUrlByShortUrl(ctx context.Context, shortUrl string) error
+	// ConsumeClick 原子扣减一次点击预算，返回扣减后的剩余次数，预算已耗尽时返回 -1
+	ConsumeClick(ctx context.Context, su domain.ShortUrl) (int64, error)
 	CleanExpired(ctx context.Context, now int64) error
 	RebuildBloomFilter(ctx context.Context) error
 }
+
+// ClickStatRepository 点击统计存储
+type ClickStatRepository interface {
+	IncrClicks(ctx context.Context, counts []domain.ClickCount) error
+	// FindClickStats 查询 [start, end) 内有点击的时间桶，按时间升序
+	FindClickStats(ctx context.Context, shortUrl string, granularity domain.Granularity, start, end int64) ([]domain.ClickStatPoint, error)
+	TotalClicks(ctx context.Context, shortUrl string) (int64, error)
+	AddUniqueVisitors(ctx context.Context, visitors []domain.DailyVisitors) error
+	// UniqueVisitors 分别返回 days 中每一天的近似独立访客数
+	UniqueVisitors(ctx context.Context, shortUrl string, days []int64) ([]int64, error)
+	// RangeUniqueVisitors 返回 days 合并后的近似独立访客数
+	RangeUniqueVisitors(ctx context.Context, shortUrl string, days []int64) (int64, error)
+	// CleanUniqueVisitors 删除日期早于 before 的独立访客计数
+	CleanUniqueVisitors(ctx context.Context, before int64) (int, error)
+	IncrHotLinks(ctx context.Context, counts []domain.HotLinkCount) error
+	IncrCountryClicks(ctx context.Context, counts []domain.CountryClickCount) error
+	// FindCountryClicks 按国家汇总天落在 [start, end) 内的点击数，按点击数降序
+	FindCountryClicks(ctx context.Context, shortUrl string, start, end int64) ([]domain.CountryClicks, error)
+	IncrVariantClicks(ctx context.Context, counts []domain.VariantClickCount) error
+	// FindVariantClicks 按分流序号汇总天落在 [start, end) 内的点击数，只返回有点击的序号，按序号升序
+	FindVariantClicks(ctx context.Context, shortUrl string, start, end int64) ([]domain.VariantClicks, error)
+	// TopLinks 合并窗口内 [start, end) 的时间桶，返回点击数最多的 n 个短链接
+	TopLinks(ctx context.Context, window domain.TopWindow, start, end int64, n int) ([]domain.LinkClicks, error)
+}
+
+// ApiKeyRepository API Key 存储，查询结果（包括不存在的 Key）会缓存到 redis
+type ApiKeyRepository interface {
+	// FindByKeyID 查询 API Key，不存在时返回 ErrDataNotFound
+	FindByKeyID(ctx context.Context, keyID string) (domain.ApiKey, error)
+}
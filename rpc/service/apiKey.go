@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"errors"
+	"short_url/pkg/sign"
+	"short_url/rpc/domain"
+	"short_url/rpc/repository"
+	"strings"
+)
+
+type CachedApiKeyService struct {
+	repo   repository.ApiKeyRepository
+	signer sign.SignHandler
+}
+
+var _ ApiKeyService = (*CachedApiKeyService)(nil)
+
+var (
+	ErrApiKeyNotFound   = errors.New("api key not found")
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+func NewCachedApiKeyService(repo repository.ApiKeyRepository, signer sign.SignHandler) *CachedApiKeyService {
+	return &CachedApiKeyService{repo: repo, signer: signer}
+}
+
+func (s *CachedApiKeyService) Find(ctx context.Context, keyID string) (domain.ApiKey, error) {
+	if keyID == "" {
+		return domain.ApiKey{}, ErrApiKeyNotFound
+	}
+	k, err := s.repo.FindByKeyID(ctx, keyID)
+	if errors.Is(err, repository.ErrDataNotFound) {
+		return domain.ApiKey{}, ErrApiKeyNotFound
+	}
+	if err != nil {
+		return domain.ApiKey{}, err
+	}
+	// 已禁用的 Key 与不存在的 Key 对调用方不做区分
+	if k.Disabled {
+		return domain.ApiKey{}, ErrApiKeyNotFound
+	}
+	return k, nil
+}
+
+func (s *CachedApiKeyService) Verify(ctx context.Context, keyID string, params map[string]string, signature string) error {
+	k, err := s.Find(ctx, keyID)
+	if err != nil {
+		return err
+	}
+	data := make(map[string]any, len(params))
+	for key, val := range params {
+		data[key] = val
+	}
+	expected, err := s.signer.GenerateSign(data, k.Secret)
+	// 原文不合法视为签名错误，不暴露具体原因
+	if err != nil || !hmac.Equal([]byte(expected), []byte(strings.ToLower(signature))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"short_url/pkg/sign/hmacsha256"
+	"short_url/rpc/domain"
+	"short_url/rpc/repository"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memApiKeyRepo map[string]domain.ApiKey
+
+func (r memApiKeyRepo) FindByKeyID(ctx context.Context, keyID string) (domain.ApiKey, error) {
+	k, ok := r[keyID]
+	if !ok {
+		return domain.ApiKey{}, repository.ErrDataNotFound
+	}
+	return k, nil
+}
+
+func TestCachedApiKeyService_Verify(t *testing.T) {
+	signer := hmacsha256.NewHmacSha256SignHandler()
+	svc := NewCachedApiKeyService(memApiKeyRepo{
+		"ak_test":     {KeyID: "ak_test", Secret: "secret"},
+		"ak_disabled": {KeyID: "ak_disabled", Secret: "secret", Disabled: true},
+	}, signer)
+
+	params := map[string]string{"method": "POST", "path": "/api/create", "nonce": "nonce-0001"}
+	sig, err := signer.GenerateSign(map[string]any{"method": "POST", "path": "/api/create", "nonce": "nonce-0001"}, "secret")
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		keyID     string
+		params    map[string]string
+		signature string
+		wantErr   error
+	}{
+		{name: "签名正确", keyID: "ak_test", params: params, signature: sig},
+		{name: "签名使用大写十六进制", keyID: "ak_test", params: params, signature: strings.ToUpper(sig)},
+		{name: "Key 不存在", keyID: "ak_unknown", params: params, signature: sig, wantErr: ErrApiKeyNotFound},
+		{name: "Key 已禁用", keyID: "ak_disabled", params: params, signature: sig, wantErr: ErrApiKeyNotFound},
+		{
+			name:      "参数被篡改",
+			keyID:     "ak_test",
+			params:    map[string]string{"method": "POST", "path": "/api/batch/create", "nonce": "nonce-0001"},
+			signature: sig,
+			wantErr:   ErrInvalidSignature,
+		},
+		{
+			name:      "原文包含换行",
+			keyID:     "ak_test",
+			params:    map[string]string{"path": "/api/create\nmethod=GET"},
+			signature: sig,
+			wantErr:   ErrInvalidSignature,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := svc.Verify(context.Background(), tc.keyID, tc.params, tc.signature)
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
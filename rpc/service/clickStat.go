@@ -0,0 +1,397 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"short_url/rpc/domain"
+	"short_url/rpc/repository"
+	"time"
+
+	"github.com/to404hanga/pkg404/logger"
+)
+
+type AggregatedClickStatService struct {
+	repo      repository.ClickStatRepository
+	shortUrls repository.ShortUrlRepository
+	l         logger.Logger
+	policy    ClickStatPolicy
+}
+
+// ClickStatPolicy 点击统计的上报与查询限制
+type ClickStatPolicy struct {
+	MaxEvents    int           // 单次上报的最大事件数
+	MaxLateness  time.Duration // 允许上报的最早事件距当前的时长，更早的事件直接丢弃
+	MaxHourRange int           // 按小时查询时最多返回的时间桶数
+	MaxDayRange  int           // 按天查询时最多返回的时间桶数
+	// VisitorRetention 独立访客计数的保留时长，由定时任务清理
+	VisitorRetention time.Duration
+	MaxTopN          int // 热门链接单次查询的最大条数
+}
+
+var _ ClickStatService = (*AggregatedClickStatService)(nil)
+
+// 事件时间戳允许超前当前时间的误差，用于容忍各实例之间的时钟偏差
+const clickClockSkew = 5 * time.Minute
+
+// 未指定查询区间时默认返回的时间桶数
+const (
+	defaultHourRange = 24
+	defaultDayRange  = 30
+)
+
+// 未指定条数时热门链接默认返回的条数
+const defaultTopN = 10
+
+var ErrInvalidStatRange = errors.New("invalid stat range")
+
+func NewAggregatedClickStatService(repo repository.ClickStatRepository, shortUrls repository.ShortUrlRepository, l logger.Logger, policy ClickStatPolicy) *AggregatedClickStatService {
+	return &AggregatedClickStatService{
+		repo:      repo,
+		shortUrls: shortUrls,
+		l:         l,
+		policy:    policy,
+	}
+}
+
+func (s *AggregatedClickStatService) RecordClicks(ctx context.Context, events []domain.ClickEvent) (int, error) {
+	if len(events) > s.policy.MaxEvents {
+		return 0, ErrBatchTooLarge
+	}
+
+	type bucketKey struct {
+		shortUrl    string
+		granularity domain.Granularity
+		bucket      int64
+	}
+	now := time.Now()
+	earliest, latest := now.Add(-s.policy.MaxLateness), now.Add(clickClockSkew)
+	type hotKey struct {
+		shortUrl string
+		window   domain.TopWindow
+		bucket   int64
+	}
+	type countryKey struct {
+		shortUrl string
+		day      int64
+		country  string
+	}
+	counts := make(map[bucketKey]int64)
+	hot := make(map[hotKey]int64)
+	type variantKey struct {
+		shortUrl string
+		day      int64
+		variant  int32
+	}
+	countries := make(map[countryKey]int64)
+	variants := make(map[variantKey]int64)
+	visitors := make(map[bucketKey]map[string]struct{}) // 同批次内重复的访客只提交一次
+	accepted := 0
+	for _, ev := range events {
+		if ev.ShortUrl == "" || ev.Timestamp.Before(earliest) || ev.Timestamp.After(latest) {
+			continue
+		}
+		accepted++
+		ts := ev.Timestamp.Unix()
+		for _, g := range []domain.Granularity{domain.GranularityHour, domain.GranularityDay} {
+			counts[bucketKey{shortUrl: ev.ShortUrl, granularity: g, bucket: g.Truncate(ts)}]++
+		}
+		for _, w := range domain.TopWindows {
+			// 已滑出窗口的事件不再计入热门链接
+			if ev.Timestamp.Before(now.Add(-w.Duration() - w.BucketSize())) {
+				continue
+			}
+			hot[hotKey{shortUrl: ev.ShortUrl, window: w, bucket: w.Truncate(ts)}]++
+		}
+		// 无法解析国家的点击只计入总数，不计入国家分布
+		if domain.IsCountryCode(ev.Country) {
+			countries[countryKey{shortUrl: ev.ShortUrl, day: domain.GranularityDay.Truncate(ts), country: ev.Country}]++
+		}
+		if ev.Variant > 0 && ev.Variant <= maxVariants {
+			variants[variantKey{shortUrl: ev.ShortUrl, day: domain.GranularityDay.Truncate(ts), variant: ev.Variant}]++
+		}
+
+		fp := visitorFingerprint(ev)
+		if fp == "" {
+			continue
+		}
+		day := bucketKey{shortUrl: ev.ShortUrl, granularity: domain.GranularityDay, bucket: domain.GranularityDay.Truncate(ts)}
+		if visitors[day] == nil {
+			visitors[day] = make(map[string]struct{})
+		}
+		visitors[day][fp] = struct{}{}
+	}
+	if accepted < len(events) {
+		s.l.Warn("dropped invalid click events",
+			logger.Int("total", len(events)),
+			logger.Int("accepted", accepted),
+		)
+	}
+	if len(counts) == 0 {
+		return 0, nil
+	}
+
+	aggregated := make([]domain.ClickCount, 0, len(counts))
+	for k, clicks := range counts {
+		aggregated = append(aggregated, domain.ClickCount{
+			ShortUrl:    k.shortUrl,
+			Granularity: k.granularity,
+			Bucket:      k.bucket,
+			Clicks:      clicks,
+		})
+	}
+	if err := s.repo.IncrClicks(ctx, aggregated); err != nil {
+		return 0, err
+	}
+
+	// 点击数已经落库，热门链接与独立访客计数失败时只记录日志，避免调用方重试导致点击数重复累加
+	if len(hot) > 0 {
+		hotCounts := make([]domain.HotLinkCount, 0, len(hot))
+		for k, clicks := range hot {
+			hotCounts = append(hotCounts, domain.HotLinkCount{
+				ShortUrl: k.shortUrl,
+				Window:   k.window,
+				Bucket:   k.bucket,
+				Clicks:   clicks,
+			})
+		}
+		if err := s.repo.IncrHotLinks(ctx, hotCounts); err != nil {
+			s.l.Error("incr hot links failed",
+				logger.Error(err),
+				logger.Int("count", len(hotCounts)),
+			)
+		}
+	}
+	if len(countries) > 0 {
+		countryCounts := make([]domain.CountryClickCount, 0, len(countries))
+		for k, clicks := range countries {
+			countryCounts = append(countryCounts, domain.CountryClickCount{
+				ShortUrl: k.shortUrl,
+				Day:      k.day,
+				Country:  k.country,
+				Clicks:   clicks,
+			})
+		}
+		if err := s.repo.IncrCountryClicks(ctx, countryCounts); err != nil {
+			s.l.Error("incr country clicks failed",
+				logger.Error(err),
+				logger.Int("count", len(countryCounts)),
+			)
+		}
+	}
+	if len(variants) > 0 {
+		variantCounts := make([]domain.VariantClickCount, 0, len(variants))
+		for k, clicks := range variants {
+			variantCounts = append(variantCounts, domain.VariantClickCount{
+				ShortUrl: k.shortUrl,
+				Day:      k.day,
+				Variant:  k.variant,
+				Clicks:   clicks,
+			})
+		}
+		if err := s.repo.IncrVariantClicks(ctx, variantCounts); err != nil {
+			s.l.Error("incr variant clicks failed",
+				logger.Error(err),
+				logger.Int("count", len(variantCounts)),
+			)
+		}
+	}
+	if len(visitors) > 0 {
+		daily := make([]domain.DailyVisitors, 0, len(visitors))
+		for k, fps := range visitors {
+			dv := domain.DailyVisitors{
+				ShortUrl:     k.shortUrl,
+				Day:          k.bucket,
+				Fingerprints: make([]string, 0, len(fps)),
+			}
+			for fp := range fps {
+				dv.Fingerprints = append(dv.Fingerprints, fp)
+			}
+			daily = append(daily, dv)
+		}
+		if err := s.repo.AddUniqueVisitors(ctx, daily); err != nil {
+			s.l.Error("add unique visitors failed",
+				logger.Error(err),
+				logger.Int("count", len(daily)),
+			)
+		}
+	}
+	return accepted, nil
+}
+
+// visitorFingerprint 由 IP 哈希与 user agent 组合生成访客指纹，缺少 IP 哈希时不计入独立访客
+func visitorFingerprint(ev domain.ClickEvent) string {
+	if ev.IPHash == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(ev.IPHash + "\x00" + ev.UserAgent))
+	return hex.EncodeToString(sum[:16])
+}
+
+func (s *AggregatedClickStatService) ClickStats(ctx context.Context, shortUrl string, granularity domain.Granularity, start, end int64) (domain.ClickStats, error) {
+	start, end, err := s.resolveRange(granularity, start, end)
+	if err != nil {
+		return domain.ClickStats{}, err
+	}
+	su, err := s.shortUrls.FindShortUrl(ctx, shortUrl)
+	if err != nil {
+		if errors.Is(err, repository.ErrDataNotFound) {
+			return domain.ClickStats{}, ErrShortUrlNotFound
+		}
+		return domain.ClickStats{}, err
+	}
+
+	found, err := s.repo.FindClickStats(ctx, shortUrl, granularity, start, end)
+	if err != nil {
+		return domain.ClickStats{}, err
+	}
+	total, err := s.repo.TotalClicks(ctx, shortUrl)
+	if err != nil {
+		return domain.ClickStats{}, err
+	}
+
+	stats := domain.ClickStats{
+		ShortUrl:    shortUrl,
+		Granularity: granularity,
+		Total:       total,
+		Points:      fillClickPoints(found, granularity, start, end),
+	}
+	for _, p := range found {
+		stats.RangeTotal += p.Clicks
+	}
+
+	if granularity == domain.GranularityDay {
+		days := make([]int64, 0, len(stats.Points))
+		for _, p := range stats.Points {
+			days = append(days, p.Bucket)
+		}
+		uvs, err := s.repo.UniqueVisitors(ctx, shortUrl, days)
+		if err != nil {
+			return domain.ClickStats{}, err
+		}
+		for i := range stats.Points {
+			stats.Points[i].UniqueVisitors = uvs[i]
+		}
+		if stats.RangeUniqueVisitors, err = s.repo.RangeUniqueVisitors(ctx, shortUrl, days); err != nil {
+			return domain.ClickStats{}, err
+		}
+		if stats.Countries, err = s.repo.FindCountryClicks(ctx, shortUrl, start, end); err != nil {
+			return domain.ClickStats{}, err
+		}
+		if len(su.Variants) > 0 {
+			if stats.Variants, err = s.variantClicks(ctx, su, start, end); err != nil {
+				return domain.ClickStats{}, err
+			}
+		}
+	}
+	return stats, nil
+}
+
+// variantClicks 返回短链接每个分流目标的点击数，没有点击的目标补 0。
+// 需要密码访问的短链接不返回跳转目标，避免绕过密码校验
+func (s *AggregatedClickStatService) variantClicks(ctx context.Context, su domain.ShortUrl, start, end int64) ([]domain.VariantClicks, error) {
+	found, err := s.repo.FindVariantClicks(ctx, su.ShortUrl, start, end)
+	if err != nil {
+		return nil, err
+	}
+	variants := make([]domain.VariantClicks, 0, len(su.Variants))
+	for i, v := range su.Variants {
+		variant := domain.VariantClicks{
+			Variant: int32(i + 1),
+			Weight:  v.Weight,
+		}
+		if !su.IsProtected() {
+			variant.Target = v.Target
+		}
+		variants = append(variants, variant)
+	}
+	for _, f := range found {
+		if f.Variant > 0 && int(f.Variant) <= len(variants) {
+			variants[f.Variant-1].Clicks = f.Clicks
+		}
+	}
+	return variants, nil
+}
+
+func (s *AggregatedClickStatService) TopLinks(ctx context.Context, window domain.TopWindow, n int) ([]domain.LinkClicks, error) {
+	switch window {
+	case domain.TopWindow5m, domain.TopWindow1h, domain.TopWindow1d:
+	default:
+		return nil, ErrInvalidStatRange
+	}
+	if n == 0 {
+		n = min(defaultTopN, s.policy.MaxTopN)
+	}
+	if n < 0 || n > s.policy.MaxTopN {
+		return nil, ErrInvalidStatRange
+	}
+
+	// 窗口由包含当前时间桶在内的最近若干个时间桶近似
+	end := window.Truncate(time.Now().Unix()) + int64(window.BucketSize()/time.Second)
+	start := end - int64(window.Duration()/time.Second)
+	return s.repo.TopLinks(ctx, window, start, end, n)
+}
+
+func (s *AggregatedClickStatService) CleanUniqueVisitors(ctx context.Context) error {
+	before := domain.GranularityDay.Truncate(time.Now().Add(-s.policy.VisitorRetention).Unix())
+	deleted, err := s.repo.CleanUniqueVisitors(ctx, before)
+	if deleted > 0 {
+		s.l.Info("cleaned expired unique visitors",
+			logger.Int("deleted", deleted),
+			logger.Int64("before", before),
+		)
+	}
+	return err
+}
+
+// resolveRange 将查询区间对齐到时间桶边界，并校验时间桶数不超过上限
+func (s *AggregatedClickStatService) resolveRange(granularity domain.Granularity, start, end int64) (int64, int64, error) {
+	var maxRange, defaultRange int
+	switch granularity {
+	case domain.GranularityHour:
+		maxRange, defaultRange = s.policy.MaxHourRange, defaultHourRange
+	case domain.GranularityDay:
+		maxRange, defaultRange = s.policy.MaxDayRange, defaultDayRange
+	default:
+		return 0, 0, ErrInvalidStatRange
+	}
+	if start < 0 || end < 0 {
+		return 0, 0, ErrInvalidStatRange
+	}
+
+	size := int64(granularity.Duration() / time.Second)
+	if end == 0 {
+		// 默认包含当前所在的时间桶
+		end = granularity.Truncate(time.Now().Unix()) + size
+	} else if aligned := granularity.Truncate(end); aligned != end {
+		end = aligned + size
+	}
+	if start == 0 {
+		start = end - int64(defaultRange)*size
+	} else {
+		start = granularity.Truncate(start)
+	}
+	if start >= end || (end-start)/size > int64(maxRange) {
+		return 0, 0, ErrInvalidStatRange
+	}
+	return start, end, nil
+}
+
+// fillClickPoints 为没有点击的时间桶补 0，返回连续的时间序列
+func fillClickPoints(found []domain.ClickStatPoint, granularity domain.Granularity, start, end int64) []domain.ClickStatPoint {
+	size := int64(granularity.Duration() / time.Second)
+	points := make([]domain.ClickStatPoint, 0, (end-start)/size)
+	i := 0
+	for bucket := start; bucket < end; bucket += size {
+		p := domain.ClickStatPoint{Bucket: bucket}
+		for i < len(found) && found[i].Bucket < bucket {
+			i++
+		}
+		if i < len(found) && found[i].Bucket == bucket {
+			p.Clicks = found[i].Clicks
+		}
+		points = append(points, p)
+	}
+	return points
+}
@@ -0,0 +1,379 @@
+package service
+
+import (
+	"context"
+	"short_url/rpc/domain"
+	"short_url/rpc/repository"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/to404hanga/pkg404/logger"
+)
+
+type memClickStatRepo struct {
+	counts    map[domain.ClickCount]int64 // Clicks 字段置 0 作为键
+	visitors  map[string]map[int64]map[string]struct{}
+	hot       map[domain.HotLinkCount]int64      // Clicks 字段置 0 作为键
+	countries map[domain.CountryClickCount]int64 // Clicks 字段置 0 作为键
+	variants  map[domain.VariantClickCount]int64 // Clicks 字段置 0 作为键
+}
+
+func newMemClickStatRepo() *memClickStatRepo {
+	return &memClickStatRepo{
+		counts:    map[domain.ClickCount]int64{},
+		visitors:  map[string]map[int64]map[string]struct{}{},
+		hot:       map[domain.HotLinkCount]int64{},
+		countries: map[domain.CountryClickCount]int64{},
+		variants:  map[domain.VariantClickCount]int64{},
+	}
+}
+
+func (r *memClickStatRepo) IncrVariantClicks(ctx context.Context, counts []domain.VariantClickCount) error {
+	for _, c := range counts {
+		clicks := c.Clicks
+		c.Clicks = 0
+		r.variants[c] += clicks
+	}
+	return nil
+}
+
+func (r *memClickStatRepo) FindVariantClicks(ctx context.Context, shortUrl string, start, end int64) ([]domain.VariantClicks, error) {
+	sum := map[int32]int64{}
+	for k, clicks := range r.variants {
+		if k.ShortUrl == shortUrl && k.Day >= start && k.Day < end {
+			sum[k.Variant] += clicks
+		}
+	}
+	variants := make([]domain.VariantClicks, 0, len(sum))
+	for variant, clicks := range sum {
+		variants = append(variants, domain.VariantClicks{Variant: variant, Clicks: clicks})
+	}
+	sort.Slice(variants, func(i, j int) bool { return variants[i].Variant < variants[j].Variant })
+	return variants, nil
+}
+
+func (r *memClickStatRepo) IncrCountryClicks(ctx context.Context, counts []domain.CountryClickCount) error {
+	for _, c := range counts {
+		clicks := c.Clicks
+		c.Clicks = 0
+		r.countries[c] += clicks
+	}
+	return nil
+}
+
+func (r *memClickStatRepo) FindCountryClicks(ctx context.Context, shortUrl string, start, end int64) ([]domain.CountryClicks, error) {
+	sum := map[string]int64{}
+	for k, clicks := range r.countries {
+		if k.ShortUrl == shortUrl && k.Day >= start && k.Day < end {
+			sum[k.Country] += clicks
+		}
+	}
+	countries := make([]domain.CountryClicks, 0, len(sum))
+	for country, clicks := range sum {
+		countries = append(countries, domain.CountryClicks{Country: country, Clicks: clicks})
+	}
+	sort.Slice(countries, func(i, j int) bool { return countries[i].Clicks > countries[j].Clicks })
+	return countries, nil
+}
+
+func (r *memClickStatRepo) IncrHotLinks(ctx context.Context, counts []domain.HotLinkCount) error {
+	for _, c := range counts {
+		clicks := c.Clicks
+		c.Clicks = 0
+		r.hot[c] += clicks
+	}
+	return nil
+}
+
+func (r *memClickStatRepo) TopLinks(ctx context.Context, window domain.TopWindow, start, end int64, n int) ([]domain.LinkClicks, error) {
+	sum := map[string]int64{}
+	for k, clicks := range r.hot {
+		if k.Window == window && k.Bucket >= start && k.Bucket < end {
+			sum[k.ShortUrl] += clicks
+		}
+	}
+	links := make([]domain.LinkClicks, 0, len(sum))
+	for shortUrl, clicks := range sum {
+		links = append(links, domain.LinkClicks{ShortUrl: shortUrl, Clicks: clicks})
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].Clicks > links[j].Clicks })
+	if len(links) > n {
+		links = links[:n]
+	}
+	return links, nil
+}
+
+func (r *memClickStatRepo) IncrClicks(ctx context.Context, counts []domain.ClickCount) error {
+	for _, c := range counts {
+		clicks := c.Clicks
+		c.Clicks = 0
+		r.counts[c] += clicks
+	}
+	return nil
+}
+
+func (r *memClickStatRepo) FindClickStats(ctx context.Context, shortUrl string, granularity domain.Granularity, start, end int64) ([]domain.ClickStatPoint, error) {
+	var points []domain.ClickStatPoint
+	for k, clicks := range r.counts {
+		if k.ShortUrl == shortUrl && k.Granularity == granularity && k.Bucket >= start && k.Bucket < end {
+			points = append(points, domain.ClickStatPoint{Bucket: k.Bucket, Clicks: clicks})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Bucket < points[j].Bucket })
+	return points, nil
+}
+
+func (r *memClickStatRepo) TotalClicks(ctx context.Context, shortUrl string) (int64, error) {
+	var total int64
+	for k, clicks := range r.counts {
+		if k.ShortUrl == shortUrl && k.Granularity == domain.GranularityDay {
+			total += clicks
+		}
+	}
+	return total, nil
+}
+
+func (r *memClickStatRepo) AddUniqueVisitors(ctx context.Context, visitors []domain.DailyVisitors) error {
+	for _, v := range visitors {
+		if r.visitors[v.ShortUrl] == nil {
+			r.visitors[v.ShortUrl] = map[int64]map[string]struct{}{}
+		}
+		if r.visitors[v.ShortUrl][v.Day] == nil {
+			r.visitors[v.ShortUrl][v.Day] = map[string]struct{}{}
+		}
+		for _, fp := range v.Fingerprints {
+			r.visitors[v.ShortUrl][v.Day][fp] = struct{}{}
+		}
+	}
+	return nil
+}
+
+func (r *memClickStatRepo) UniqueVisitors(ctx context.Context, shortUrl string, days []int64) ([]int64, error) {
+	counts := make([]int64, 0, len(days))
+	for _, day := range days {
+		counts = append(counts, int64(len(r.visitors[shortUrl][day])))
+	}
+	return counts, nil
+}
+
+func (r *memClickStatRepo) RangeUniqueVisitors(ctx context.Context, shortUrl string, days []int64) (int64, error) {
+	union := map[string]struct{}{}
+	for _, day := range days {
+		for fp := range r.visitors[shortUrl][day] {
+			union[fp] = struct{}{}
+		}
+	}
+	return int64(len(union)), nil
+}
+
+func (r *memClickStatRepo) CleanUniqueVisitors(ctx context.Context, before int64) (int, error) {
+	deleted := 0
+	for _, days := range r.visitors {
+		for day := range days {
+			if day < before {
+				delete(days, day)
+				deleted++
+			}
+		}
+	}
+	return deleted, nil
+}
+
+type existingShortUrlRepo struct {
+	repository.ShortUrlRepository
+	variants []domain.Variant
+	// protected 中的短链接需要密码访问
+	protected map[string]bool
+}
+
+func (r existingShortUrlRepo) FindShortUrl(ctx context.Context, shortUrl string) (domain.ShortUrl, error) {
+	su := domain.ShortUrl{ShortUrl: shortUrl, Variants: r.variants}
+	if r.protected[shortUrl] {
+		su.PasswordHash = "hash"
+	}
+	return su, nil
+}
+
+func TestAggregatedClickStatService(t *testing.T) {
+	repo := newMemClickStatRepo()
+	variants := []domain.Variant{{Target: "https://a.example.com", Weight: 70}, {Target: "https://b.example.com", Weight: 30}}
+	svc := NewAggregatedClickStatService(repo, existingShortUrlRepo{variants: variants, protected: map[string]bool{"xyz": true}}, logger.NewNopLogger(), ClickStatPolicy{
+		MaxEvents:    10,
+		MaxLateness:  48 * time.Hour,
+		MaxHourRange: 48,
+		MaxDayRange:  7,
+
+		VisitorRetention: 24 * time.Hour,
+	})
+	ctx := context.Background()
+	hour := domain.GranularityHour.Truncate(time.Now().Unix())
+	today := domain.GranularityDay.Truncate(hour)
+	at := func(offset int64) time.Time { return time.Unix(hour+offset, 0) }
+	// 当前处于当天第一个小时时，前一小时的点击计入前一天
+	var crossDay int64
+	if hour == today {
+		crossDay = 1
+	}
+
+	// 历史数据，用于验证保留期清理
+	repo.AddUniqueVisitors(ctx, []domain.DailyVisitors{{ShortUrl: "abc", Day: today - 3*86400, Fingerprints: []string{"old"}}})
+
+	accepted, err := svc.RecordClicks(ctx, []domain.ClickEvent{
+		{ShortUrl: "abc", Timestamp: at(1), IPHash: "ip1", UserAgent: "ua", Country: "CN", Variant: 1},
+		{ShortUrl: "abc", Timestamp: at(2), IPHash: "ip1", UserAgent: "ua", Country: "CN", Variant: 1},
+		{ShortUrl: "abc", Timestamp: at(-3600), IPHash: "ip2", UserAgent: "ua", Country: "zz"}, // 不合法的国家代码不计入分布
+		{ShortUrl: "xyz", Timestamp: at(3)},
+		{ShortUrl: "abc", Timestamp: time.Now().Add(-72 * time.Hour)}, // 超过允许的延迟
+		{ShortUrl: "", Timestamp: at(4)},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, accepted)
+
+	_, err = svc.RecordClicks(ctx, make([]domain.ClickEvent, 11))
+	assert.ErrorIs(t, err, ErrBatchTooLarge)
+
+	testCases := []struct {
+		name            string
+		granularity     domain.Granularity
+		start, end      int64
+		wantErr         error
+		wantPoints      []domain.ClickStatPoint
+		wantRangeTotal  int64
+		wantRangeUnique int64
+		wantCountries   []domain.CountryClicks
+		wantVariants    []domain.VariantClicks
+	}{
+		{
+			name:        "按小时查询并为空桶补 0",
+			granularity: domain.GranularityHour,
+			start:       hour - 7200,
+			end:         hour + 1, // 未对齐的结束时间向上取整
+			wantPoints: []domain.ClickStatPoint{
+				{Bucket: hour - 7200},
+				{Bucket: hour - 3600, Clicks: 1},
+				{Bucket: hour, Clicks: 2},
+			},
+			wantRangeTotal: 3,
+		},
+		{
+			name:        "按天查询返回独立访客数",
+			granularity: domain.GranularityDay,
+			start:       today - 86400,
+			end:         today + 86400,
+			wantPoints: []domain.ClickStatPoint{
+				{Bucket: today - 86400, Clicks: crossDay, UniqueVisitors: crossDay},
+				{Bucket: today, Clicks: 3 - crossDay, UniqueVisitors: 2 - crossDay},
+			},
+			wantRangeTotal:  3,
+			wantRangeUnique: 2,
+			wantCountries:   []domain.CountryClicks{{Country: "CN", Clicks: 2}},
+			// 没有点击的分流目标补 0
+			wantVariants: []domain.VariantClicks{
+				{Variant: 1, Target: "https://a.example.com", Weight: 70, Clicks: 2},
+				{Variant: 2, Target: "https://b.example.com", Weight: 30},
+			},
+		},
+		{
+			name:        "区间超过上限",
+			granularity: domain.GranularityDay,
+			start:       hour - 30*86400,
+			end:         hour,
+			wantErr:     ErrInvalidStatRange,
+		},
+		{
+			name:        "开始时间晚于结束时间",
+			granularity: domain.GranularityHour,
+			start:       hour + 7200,
+			end:         hour,
+			wantErr:     ErrInvalidStatRange,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stats, err := svc.ClickStats(ctx, "abc", tc.granularity, tc.start, tc.end)
+			assert.ErrorIs(t, err, tc.wantErr)
+			if tc.wantErr != nil {
+				return
+			}
+			assert.Equal(t, tc.wantPoints, stats.Points)
+			assert.Equal(t, tc.wantRangeTotal, stats.RangeTotal)
+			assert.Equal(t, int64(3), stats.Total)
+			assert.Equal(t, tc.wantRangeUnique, stats.RangeUniqueVisitors)
+			assert.Equal(t, tc.wantCountries, stats.Countries)
+			assert.Equal(t, tc.wantVariants, stats.Variants)
+		})
+	}
+
+	// 需要密码访问的短链接不返回分流的跳转目标
+	stats, err := svc.ClickStats(ctx, "xyz", domain.GranularityDay, today, today+86400)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.VariantClicks{{Variant: 1, Weight: 70}, {Variant: 2, Weight: 30}}, stats.Variants)
+
+	assert.NoError(t, svc.CleanUniqueVisitors(ctx))
+	_, ok := repo.visitors["abc"][today-3*86400]
+	assert.False(t, ok)
+	assert.Len(t, repo.visitors["abc"][today], int(2-crossDay))
+}
+
+func TestAggregatedClickStatService_TopLinks(t *testing.T) {
+	svc := NewAggregatedClickStatService(newMemClickStatRepo(), existingShortUrlRepo{}, logger.NewNopLogger(), ClickStatPolicy{
+		MaxEvents:   10,
+		MaxLateness: 48 * time.Hour,
+		MaxTopN:     2,
+	})
+	ctx := context.Background()
+	now := time.Now()
+	_, err := svc.RecordClicks(ctx, []domain.ClickEvent{
+		{ShortUrl: "abc", Timestamp: now},
+		{ShortUrl: "abc", Timestamp: now},
+		{ShortUrl: "xyz", Timestamp: now},
+		{ShortUrl: "old", Timestamp: now.Add(-30 * time.Minute)},
+		{ShortUrl: "old", Timestamp: now.Add(-30 * time.Minute)},
+		{ShortUrl: "old", Timestamp: now.Add(-30 * time.Minute)},
+	})
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		window  domain.TopWindow
+		n       int
+		want    []domain.LinkClicks
+		wantErr error
+	}{
+		{
+			name:   "最近 5 分钟",
+			window: domain.TopWindow5m,
+			want:   []domain.LinkClicks{{ShortUrl: "abc", Clicks: 2}, {ShortUrl: "xyz", Clicks: 1}},
+		},
+		{
+			name:   "最近 1 小时只取前 n 条",
+			window: domain.TopWindow1h,
+			n:      1,
+			want:   []domain.LinkClicks{{ShortUrl: "old", Clicks: 3}},
+		},
+		{
+			name:    "条数超过上限",
+			window:  domain.TopWindow1d,
+			n:       3,
+			wantErr: ErrInvalidStatRange,
+		},
+		{
+			name:    "未知窗口",
+			window:  domain.TopWindow(9),
+			wantErr: ErrInvalidStatRange,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			links, err := svc.TopLinks(ctx, tc.window, tc.n)
+			assert.ErrorIs(t, err, tc.wantErr)
+			if tc.wantErr == nil {
+				assert.Equal(t, tc.want, links)
+			}
+		})
+	}
+}
@@ -2,49 +2,613 @@ package service
 
 import (
 	"context"
+	"errors"
 	"short_url/pkg/generator"
+	"short_url/rpc/domain"
 	"short_url/rpc/repository"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/to404hanga/pkg404/logger"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/sync/errgroup"
 )
 
 type CachedShortUrlService struct {
-	repo    repository.ShortUrlRepository
-	l       logger.Logger
-	suffix  string
-	Weights []int
+	repo         repository.ShortUrlRepository
+	l            logger.Logger
+	suffix       string
+	Weights      []int
+	expiration   ExpirationPolicy
+	batchMaxSize int
+	utmTemplates map[string]domain.Utm // 模板名（小写） -> UTM 参数
+}
+
+// 批量解析时并发查询的协程数
+const batchRedirectConcurrency = 16
+
+// 访问密码的长度限制，bcrypt 只使用前 72 个字节
+const (
+	minPasswordLength = 4
+	maxPasswordLength = 72
+)
+
+// 跳转规则的数量与目标地址长度限制，编码后不超过 dao.ShortUrl.Rules 的列宽
+const (
+	maxRoutingRules        = 16
+	maxRoutingTargetLength = 200
+)
+
+// A/B 分流目标的数量与地址长度限制，编码后不超过 dao.ShortUrl.Variants 的列宽
+const (
+	maxVariants            = 8
+	maxVariantTargetLength = 200
+)
+
+// 合并后 UTM 查询字符串的最大长度，与 dao.ShortUrl.Utm 的列宽一致
+const maxUtmLength = 512
+
+// ExpirationPolicy 短链接有效期策略
+type ExpirationPolicy struct {
+	Default    time.Duration // 未指定有效期时使用的默认值
+	Min        time.Duration // 允许的最短有效期
+	Max        time.Duration // 允许的最长有效期
+	AllowNever bool          // 是否允许永不过期
 }
 
 var _ ShortUrlService = (*CachedShortUrlService)(nil)
 
-func NewCachedShortUrlService(repo repository.ShortUrlRepository, l logger.Logger, suffix string, weights []int) *CachedShortUrlService {
+var (
+	ErrInvalidAlias       = errors.New("invalid custom alias")
+	ErrAliasConflict      = errors.New("custom alias already in use")
+	ErrInvalidExpiration  = errors.New("invalid expiration")
+	ErrShortUrlNotFound   = errors.New("short url not found")
+	ErrInvalidOriginUrl   = errors.New("invalid origin url")
+	ErrBatchTooLarge      = errors.New("batch size exceeds limit")
+	ErrInvalidRedirect    = errors.New("invalid redirect code")
+	ErrUnknownUtmTemplate = errors.New("unknown utm template")
+	ErrInvalidUtm         = errors.New("invalid utm parameters")
+	ErrInvalidPassword    = errors.New("invalid password")
+	ErrInvalidMaxClicks   = errors.New("invalid max clicks")
+	ErrInvalidRoutingRule = errors.New("invalid routing rule")
+	ErrInvalidVariants    = errors.New("invalid variants")
+	// ErrBufferFull 写入缓冲区已满，调用方应稍后重试
+	ErrBufferFull = repository.ErrBufferFull
+	// ErrNotYetAvailable 短链接尚未到生效时间
+	ErrNotYetAvailable = repository.ErrNotYetAvailable
+	// ErrPasswordRequired 需要密码访问的短链接不能通过批量解析绕过密码校验
+	ErrPasswordRequired = errors.New("password required")
+	// ErrClickLimited 限制点击次数的短链接只能逐次跳转消耗预算，不能批量解析
+	ErrClickLimited = errors.New("short url has a click limit")
+)
+
+func NewCachedShortUrlService(repo repository.ShortUrlRepository, l logger.Logger, suffix string, weights []int, expiration ExpirationPolicy, batchMaxSize int, utmTemplates map[string]domain.Utm) *CachedShortUrlService {
 	return &CachedShortUrlService{
-		repo:    repo,
-		l:       l,
-		suffix:  suffix,
-		Weights: weights,
+		repo:         repo,
+		l:            l,
+		suffix:       suffix,
+		Weights:      weights,
+		expiration:   expiration,
+		batchMaxSize: batchMaxSize,
+		utmTemplates: utmTemplates,
 	}
 }
 
-func (s *CachedShortUrlService) Create(ctx context.Context, originUrl string) (string, error) {
-	baseSuffix := ""
+func (s *CachedShortUrlService) Create(ctx context.Context, su domain.ShortUrl, exp domain.Expiration) (domain.ShortUrl, error) {
+	if !domain.IsValidRedirectCode(su.RedirectCode) {
+		return domain.ShortUrl{}, ErrInvalidRedirect
+	}
+	if su.MaxClicks < 0 {
+		return domain.ShortUrl{}, ErrInvalidMaxClicks
+	}
+	if !checkRoutingRules(su.Rules) {
+		return domain.ShortUrl{}, ErrInvalidRoutingRule
+	}
+	if !checkVariants(su.Variants) {
+		return domain.ShortUrl{}, ErrInvalidVariants
+	}
+	su, err := s.resolveUtm(su)
+	if err != nil {
+		return domain.ShortUrl{}, err
+	}
+	// 明文密码只用于判断同一原链接已有的短链接能否复用，不随 su 传递
+	password := su.Password
+	if su, err = s.hashPassword(su); err != nil {
+		return domain.ShortUrl{}, err
+	}
+	expiredAt, err := s.resolveExpiration(exp)
+	if err != nil {
+		return domain.ShortUrl{}, err
+	}
+	su.ExpiredAt = expiredAt
+	su.NotBefore = exp.NotBefore
+
+	if su.ShortUrl != "" {
+		return s.createWithAlias(ctx, su, password)
+	}
+
+	return s.createGenerated(ctx, su, password, "")
+}
+
+// createGenerated 按原链接生成短码并写入，短码已被其他原链接占用或已有的短链接不可复用时追加后缀重新生成
+func (s *CachedShortUrlService) createGenerated(ctx context.Context, su domain.ShortUrl, password, baseSuffix string) (domain.ShortUrl, error) {
 	for {
-		shortUrl := generator.GenerateShortUrl(originUrl, baseSuffix, s.Weights)
-		err := s.repo.InsertShortUrl(ctx, shortUrl, originUrl)
-		switch err {
-		case nil, repository.ErrUniqueIndexConflict:
-			return shortUrl, nil
-		case repository.ErrPrimaryKeyConflict:
+		su.ShortUrl = generator.GenerateShortUrl(su.OriginUrl, baseSuffix, s.Weights)
+		res, retry, err := s.settleGenerated(ctx, su, password, s.repo.InsertShortUrl(ctx, su))
+		if !retry {
+			return res, err
+		}
+		baseSuffix += s.suffix
+	}
+}
+
+// settleGenerated 根据写入结果决定生成的短码能否使用。同一原链接已生成过该短码时返回库中保存的短链接，
+// 使响应中的有效期等属性与实际生效的一致；已有的短链接不可复用或短码被其他原链接占用时 retry 为 true
+func (s *CachedShortUrlService) settleGenerated(ctx context.Context, su domain.ShortUrl, password string, err error) (res domain.ShortUrl, retry bool, _ error) {
+	switch err {
+	case nil:
+		return su, false, nil
+	case repository.ErrUniqueIndexConflict:
+		stored, err := s.repo.FindShortUrl(ctx, su.ShortUrl)
+		if err != nil {
+			return domain.ShortUrl{}, false, err
+		}
+		if !s.reusable(stored, su, password) {
+			return domain.ShortUrl{}, true, nil
+		}
+		return stored, false, nil
+	case repository.ErrPrimaryKeyConflict:
+		return domain.ShortUrl{}, true, nil
+	default:
+		return domain.ShortUrl{}, false, err
+	}
+}
+
+// reusable 判断同一原链接已有的短链接能否直接返回：已过期但尚未清理的不再复用；
+// 限制点击次数的不复用，否则两次创建会共享同一份点击预算；
+// 跳转属性或密码与请求不一致时也不复用，否则请求中的设置会被静默丢弃。有效期不参与比较，复用时以库中保存的为准
+func (s *CachedShortUrlService) reusable(stored, su domain.ShortUrl, password string) bool {
+	if stored.IsExpired(time.Now().Unix()) || stored.MaxClicks > 0 || !sameSettings(stored, su) {
+		return false
+	}
+	if stored.IsProtected() != (password != "") {
+		return false
+	}
+	return !stored.IsProtected() || bcrypt.CompareHashAndPassword([]byte(stored.PasswordHash), []byte(password)) == nil
+}
+
+// sameSettings 比较两个短链接除有效期与密码外的跳转属性
+func sameSettings(a, b domain.ShortUrl) bool {
+	return a.NotBefore == b.NotBefore &&
+		a.RedirectCode == b.RedirectCode &&
+		a.PassThrough == b.PassThrough &&
+		a.Utm == b.Utm &&
+		a.MaxClicks == b.MaxClicks &&
+		slices.Equal(a.Rules, b.Rules) &&
+		slices.Equal(a.Variants, b.Variants)
+}
+
+func (s *CachedShortUrlService) BatchCreate(ctx context.Context, items []BatchCreateItem) ([]BatchCreateResult, error) {
+	if len(items) > s.batchMaxSize {
+		return nil, ErrBatchTooLarge
+	}
+
+	results := make([]BatchCreateResult, len(items))
+	var (
+		pending    []domain.ShortUrl
+		pendingIdx []int
+		generated  = make(map[string]int, len(items)) // 短码 -> 首次生成该短码的条目下标
+		duplicated = make(map[int]int)                // 同批次内重复的原链接，直接复用首条结果
+	)
+	for i, item := range items {
+		su := item.ShortUrl
+		if su.OriginUrl == "" {
+			results[i].Err = ErrInvalidOriginUrl
+			continue
+		}
+		if !domain.IsValidRedirectCode(su.RedirectCode) {
+			results[i].Err = ErrInvalidRedirect
+			continue
+		}
+		if su.MaxClicks < 0 {
+			results[i].Err = ErrInvalidMaxClicks
+			continue
+		}
+		if !checkRoutingRules(su.Rules) {
+			results[i].Err = ErrInvalidRoutingRule
+			continue
+		}
+		if !checkVariants(su.Variants) {
+			results[i].Err = ErrInvalidVariants
+			continue
+		}
+		su, err := s.resolveUtm(su)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		if su, err = s.hashPassword(su); err != nil {
+			results[i].Err = err
+			continue
+		}
+		expiredAt, err := s.resolveExpiration(item.Expiration)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		su.ExpiredAt = expiredAt
+		su.NotBefore = item.Expiration.NotBefore
+
+		// 自定义别名需要立即得知冲突结果，逐条同步占用
+		if su.ShortUrl != "" {
+			results[i].ShortUrl, results[i].Err = s.createWithAlias(ctx, su, item.ShortUrl.Password)
+			continue
+		}
+
+		// 同批次内不同原链接、同一原链接但属性不同或限制了点击次数的条目生成了相同短码时追加后缀重新生成
+		baseSuffix := ""
+		for {
+			su.ShortUrl = generator.GenerateShortUrl(su.OriginUrl, baseSuffix, s.Weights)
+			first, ok := generated[su.ShortUrl]
+			if !ok {
+				break
+			}
+			if items[first].ShortUrl.OriginUrl == su.OriginUrl && su.MaxClicks == 0 && sameSettings(results[first].ShortUrl, su) &&
+				items[first].ShortUrl.Password == item.ShortUrl.Password {
+				duplicated[i] = first
+				break
+			}
 			baseSuffix += s.suffix
-		default:
-			return "", err
 		}
+		if _, ok := duplicated[i]; ok {
+			continue
+		}
+		generated[su.ShortUrl] = i
+		results[i].ShortUrl = su
+		pending = append(pending, su)
+		pendingIdx = append(pendingIdx, i)
+	}
+
+	if len(pending) > 0 {
+		errs, err := s.repo.BatchInsertShortUrl(ctx, pending)
+		if err != nil {
+			s.l.Error("batch insert short url failed",
+				logger.Error(err),
+				logger.Int("count", len(pending)),
+			)
+			for _, i := range pendingIdx {
+				results[i] = BatchCreateResult{Err: err}
+			}
+		}
+		for j, insertErr := range errs {
+			i := pendingIdx[j]
+			password := items[i].ShortUrl.Password
+			res, retry, err := s.settleGenerated(ctx, pending[j], password, insertErr)
+			if retry {
+				// 与库中已有短码冲突的条目追加后缀后逐条重试
+				res, err = s.createGenerated(ctx, pending[j], password, s.suffix)
+			}
+			results[i] = BatchCreateResult{ShortUrl: res, Err: err}
+		}
+	}
+	for i, first := range duplicated {
+		results[i] = results[first]
+	}
+	return results, nil
+}
+
+// createWithAlias 占用自定义别名，别名已被其他链接占用时直接返回错误，不做后缀重试。
+// 同一原链接已占用该别名时返回库中保存的短链接，不可复用时同样视为别名冲突
+func (s *CachedShortUrlService) createWithAlias(ctx context.Context, su domain.ShortUrl, password string) (domain.ShortUrl, error) {
+	if !generator.CheckCustomAlias(su.ShortUrl) {
+		return domain.ShortUrl{}, ErrInvalidAlias
+	}
+	err := s.repo.ReserveShortUrl(ctx, su)
+	switch err {
+	case nil:
+		return su, nil
+	case repository.ErrUniqueIndexConflict:
+		stored, err := s.repo.FindShortUrl(ctx, su.ShortUrl)
+		if err != nil {
+			return domain.ShortUrl{}, err
+		}
+		if !s.reusable(stored, su, password) {
+			return domain.ShortUrl{}, ErrAliasConflict
+		}
+		return stored, nil
+	case repository.ErrPrimaryKeyConflict:
+		return domain.ShortUrl{}, ErrAliasConflict
+	default:
+		return domain.ShortUrl{}, err
 	}
 }
 
-func (s *CachedShortUrlService) Redirect(ctx context.Context, shortUrl string) (string, error) {
-	return s.repo.GetOriginUrlByShortUrl(ctx, shortUrl)
+// resolveUtm 以模板中的 UTM 参数为基础，使用请求中非空的字段覆盖，结果写回 su.Utm
+func (s *CachedShortUrlService) resolveUtm(su domain.ShortUrl) (domain.ShortUrl, error) {
+	if su.UtmTemplate != "" {
+		// 配置由 viper 读取，键名均为小写
+		su.UtmTemplate = strings.ToLower(su.UtmTemplate)
+		tpl, ok := s.utmTemplates[su.UtmTemplate]
+		if !ok {
+			return domain.ShortUrl{}, ErrUnknownUtmTemplate
+		}
+		su.Utm = tpl.Override(su.Utm)
+	}
+	if len(su.Utm.Encode()) > maxUtmLength {
+		return domain.ShortUrl{}, ErrInvalidUtm
+	}
+	return su, nil
+}
+
+// checkRoutingRules 校验跳转规则，平台与国家的组合只能出现一次，否则后面的规则永远不会命中
+func checkRoutingRules(rules []domain.RoutingRule) bool {
+	if len(rules) > maxRoutingRules {
+		return false
+	}
+	type condition struct {
+		platform domain.Platform
+		country  string
+	}
+	seen := make(map[condition]struct{}, len(rules))
+	for _, r := range rules {
+		if !r.IsValid() || len(r.Target) > maxRoutingTargetLength {
+			return false
+		}
+		c := condition{platform: r.Platform, country: r.Country}
+		if _, ok := seen[c]; ok {
+			return false
+		}
+		seen[c] = struct{}{}
+	}
+	return true
+}
+
+// checkVariants 校验 A/B 分流目标，配置分流时至少需要两个目标
+func checkVariants(variants []domain.Variant) bool {
+	if len(variants) == 0 {
+		return true
+	}
+	if len(variants) < 2 || len(variants) > maxVariants {
+		return false
+	}
+	for _, v := range variants {
+		if !v.IsValid() || len(v.Target) > maxVariantTargetLength {
+			return false
+		}
+	}
+	return true
+}
+
+// hashPassword 将明文密码替换为 bcrypt 哈希，未设置密码时原样返回
+func (s *CachedShortUrlService) hashPassword(su domain.ShortUrl) (domain.ShortUrl, error) {
+	if su.Password == "" {
+		return su, nil
+	}
+	if len(su.Password) < minPasswordLength || len(su.Password) > maxPasswordLength {
+		return domain.ShortUrl{}, ErrInvalidPassword
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(su.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return domain.ShortUrl{}, err
+	}
+	su.Password = ""
+	su.PasswordHash = string(hash)
+	return su, nil
+}
+
+// resolveExpiration 根据请求与有效期策略计算过期时间戳，指定了生效时间时有效期从生效时间起算
+func (s *CachedShortUrlService) resolveExpiration(exp domain.Expiration) (int64, error) {
+	if exp.NotBefore < 0 {
+		return 0, ErrInvalidExpiration
+	}
+	now := time.Now()
+	if exp.NotBefore > now.Unix() {
+		now = time.Unix(exp.NotBefore, 0)
+	}
+	switch {
+	case exp.Never:
+		if !s.expiration.AllowNever || exp.ExpiredAt != 0 || exp.TTL != 0 {
+			return 0, ErrInvalidExpiration
+		}
+		return domain.NeverExpire, nil
+	case exp.ExpiredAt != 0 && exp.TTL != 0:
+		return 0, ErrInvalidExpiration
+	case exp.ExpiredAt != 0:
+		ttl := time.Unix(exp.ExpiredAt, 0).Sub(now)
+		if !s.checkTTL(ttl) {
+			return 0, ErrInvalidExpiration
+		}
+		return exp.ExpiredAt, nil
+	case exp.TTL != 0:
+		if !s.checkTTL(exp.TTL) {
+			return 0, ErrInvalidExpiration
+		}
+		return now.Add(exp.TTL).Unix(), nil
+	default:
+		return now.Add(s.expiration.Default).Unix(), nil
+	}
+}
+
+func (s *CachedShortUrlService) checkTTL(ttl time.Duration) bool {
+	// 允许一秒的误差，避免秒级时间戳截断导致边界值被拒绝
+	return ttl >= s.expiration.Min-time.Second && ttl <= s.expiration.Max
+}
+
+func (s *CachedShortUrlService) Redirect(ctx context.Context, shortUrl string) (domain.ShortUrl, error) {
+	return s.repo.ResolveShortUrl(ctx, shortUrl)
+}
+
+func (s *CachedShortUrlService) VerifyPassword(ctx context.Context, shortUrl, password string) (bool, error) {
+	su, err := s.repo.ResolveShortUrl(ctx, shortUrl)
+	if errors.Is(err, repository.ErrDataNotFound) {
+		return false, ErrShortUrlNotFound
+	}
+	if err != nil {
+		return false, err
+	}
+	if !su.IsProtected() {
+		return true, nil
+	}
+	return bcrypt.CompareHashAndPassword([]byte(su.PasswordHash), []byte(password)) == nil, nil
+}
+
+func (s *CachedShortUrlService) ConsumeClick(ctx context.Context, shortUrl string) (int64, bool, error) {
+	su, err := s.repo.ResolveShortUrl(ctx, shortUrl)
+	if errors.Is(err, repository.ErrDataNotFound) {
+		return 0, false, ErrShortUrlNotFound
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	if su.MaxClicks <= 0 {
+		return -1, true, nil
+	}
+
+	remaining, err := s.repo.ConsumeClick(ctx, su)
+	if err != nil {
+		return 0, false, err
+	}
+	// 最后一次点击后立即使短链接过期；已耗尽时再次过期用于兜底上次失败或仍未失效的缓存
+	if remaining <= 0 {
+		go s.disable(shortUrl)
+	}
+	if remaining < 0 {
+		return 0, false, nil
+	}
+	return remaining, true, nil
+}
+
+// disable 将点击预算耗尽的短链接设置为立即过期，并使各级缓存失效
+func (s *CachedShortUrlService) disable(shortUrl string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err := s.repo.UpdateExpiredAt(ctx, shortUrl, time.Now().Unix()); err != nil {
+		s.l.Error("failed to disable short url with exhausted click budget",
+			logger.Error(err),
+			logger.String("short_url", shortUrl),
+		)
+	}
+}
+
+func (s *CachedShortUrlService) BatchRedirect(ctx context.Context, shortUrls []string) ([]BatchRedirectResult, error) {
+	if len(shortUrls) > s.batchMaxSize {
+		return nil, ErrBatchTooLarge
+	}
+
+	results := make([]BatchRedirectResult, len(shortUrls))
+	var group errgroup.Group
+	group.SetLimit(batchRedirectConcurrency)
+	for i, shortUrl := range shortUrls {
+		group.Go(func() error {
+			su, err := s.repo.ResolveShortUrl(ctx, shortUrl)
+			// 批量解析既不校验密码也不扣减点击预算，这两类短链接不返回原链接
+			switch {
+			case errors.Is(err, repository.ErrDataNotFound):
+				err = ErrShortUrlNotFound
+			case err == nil && su.IsProtected():
+				err = ErrPasswordRequired
+			case err == nil && su.MaxClicks > 0:
+				err = ErrClickLimited
+			}
+			if err != nil {
+				results[i] = BatchRedirectResult{Err: err}
+				return nil
+			}
+			results[i] = BatchRedirectResult{OriginUrl: su.OriginUrl}
+			return nil
+		})
+	}
+	_ = group.Wait()
+	return results, nil
+}
+
+func (s *CachedShortUrlService) Info(ctx context.Context, shortUrl string) (domain.ShortUrl, error) {
+	su, err := s.repo.FindShortUrl(ctx, shortUrl)
+	if err == repository.ErrDataNotFound {
+		return domain.ShortUrl{}, ErrShortUrlNotFound
+	}
+	return su, err
+}
+
+func (s *CachedShortUrlService) UpdateOriginUrl(ctx context.Context, shortUrl, originUrl string) (domain.ShortUrl, error) {
+	su, err := s.Info(ctx, shortUrl)
+	if err != nil {
+		return domain.ShortUrl{}, err
+	}
+	if su.IsExpired(time.Now().Unix()) {
+		return domain.ShortUrl{}, ErrShortUrlNotFound
+	}
+	err = s.repo.UpdateOriginUrl(ctx, shortUrl, originUrl)
+	// 查询与修改之间短链接可能已被删除
+	if errors.Is(err, repository.ErrDataNotFound) {
+		return domain.ShortUrl{}, ErrShortUrlNotFound
+	}
+	if err != nil {
+		return domain.ShortUrl{}, err
+	}
+	su.OriginUrl = originUrl
+	return su, nil
+}
+
+func (s *CachedShortUrlService) Delete(ctx context.Context, shortUrl string) error {
+	return s.repo.DeleteShortUrlByShortUrl(ctx, shortUrl)
+}
+
+func (s *CachedShortUrlService) ExtendExpiration(ctx context.Context, shortUrl string, exp domain.Expiration) (domain.ShortUrl, error) {
+	su, err := s.Info(ctx, shortUrl)
+	if err != nil {
+		return domain.ShortUrl{}, err
+	}
+	expiredAt, err := s.resolveExtension(su, exp)
+	if err != nil {
+		return domain.ShortUrl{}, err
+	}
+	err = s.repo.UpdateExpiredAt(ctx, shortUrl, expiredAt)
+	// 查询与修改之间短链接可能已被删除
+	if errors.Is(err, repository.ErrDataNotFound) {
+		return domain.ShortUrl{}, ErrShortUrlNotFound
+	}
+	if err != nil {
+		return domain.ShortUrl{}, err
+	}
+	su.ExpiredAt = expiredAt
+	return su, nil
+}
+
+// resolveExtension 计算续期后的过期时间戳，续期结果只能晚于当前过期时间
+func (s *CachedShortUrlService) resolveExtension(su domain.ShortUrl, exp domain.Expiration) (int64, error) {
+	if su.ExpiredAt == domain.NeverExpire {
+		return 0, ErrInvalidExpiration
+	}
+	now := time.Now()
+	switch {
+	case exp.Never:
+		if !s.expiration.AllowNever || exp.ExpiredAt != 0 || exp.TTL != 0 {
+			return 0, ErrInvalidExpiration
+		}
+		return domain.NeverExpire, nil
+	case exp.ExpiredAt != 0 && exp.TTL != 0:
+		return 0, ErrInvalidExpiration
+	case exp.ExpiredAt != 0:
+		if exp.ExpiredAt <= su.ExpiredAt || !s.checkTTL(time.Unix(exp.ExpiredAt, 0).Sub(now)) {
+			return 0, ErrInvalidExpiration
+		}
+		return exp.ExpiredAt, nil
+	case exp.TTL > 0:
+		base := time.Unix(su.ExpiredAt, 0)
+		if base.Before(now) {
+			base = now
+		}
+		expiredAt := base.Add(exp.TTL)
+		if expiredAt.Sub(now) > s.expiration.Max {
+			return 0, ErrInvalidExpiration
+		}
+		return expiredAt.Unix(), nil
+	default:
+		return 0, ErrInvalidExpiration
+	}
 }
 
 func (s *CachedShortUrlService) CleanExpired(ctx context.Context) error {
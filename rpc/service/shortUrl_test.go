@@ -0,0 +1,538 @@
+package service
+
+import (
+	"context"
+	"short_url/pkg/generator"
+	"short_url/rpc/domain"
+	"short_url/rpc/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/to404hanga/pkg404/logger"
+)
+
+// memShortUrlRepo 仅实现创建、解析与点击预算
+type memShortUrlRepo struct {
+	repository.ShortUrlRepository
+	data     map[string]domain.ShortUrl
+	used     map[string]int64
+	disabled chan string
+}
+
+func (r *memShortUrlRepo) ConsumeClick(ctx context.Context, su domain.ShortUrl) (int64, error) {
+	if r.used[su.ShortUrl] >= su.MaxClicks {
+		return -1, nil
+	}
+	r.used[su.ShortUrl]++
+	return su.MaxClicks - r.used[su.ShortUrl], nil
+}
+
+func (r *memShortUrlRepo) UpdateExpiredAt(ctx context.Context, shortUrl string, expiredAt int64) error {
+	r.disabled <- shortUrl
+	return nil
+}
+
+// ReserveShortUrl 与 dao 一致：短码被其他原链接占用时为主键冲突，同一原链接已占用时为唯一索引冲突
+func (r *memShortUrlRepo) ReserveShortUrl(ctx context.Context, su domain.ShortUrl) error {
+	if stored, ok := r.data[su.ShortUrl]; ok {
+		if stored.OriginUrl == su.OriginUrl {
+			return repository.ErrUniqueIndexConflict
+		}
+		return repository.ErrPrimaryKeyConflict
+	}
+	r.data[su.ShortUrl] = su
+	return nil
+}
+
+func (r *memShortUrlRepo) InsertShortUrl(ctx context.Context, su domain.ShortUrl) error {
+	return r.ReserveShortUrl(ctx, su)
+}
+
+func (r *memShortUrlRepo) BatchInsertShortUrl(ctx context.Context, sus []domain.ShortUrl) ([]error, error) {
+	errs := make([]error, len(sus))
+	for i, su := range sus {
+		errs[i] = r.InsertShortUrl(ctx, su)
+	}
+	return errs, nil
+}
+
+func (r *memShortUrlRepo) FindShortUrl(ctx context.Context, shortUrl string) (domain.ShortUrl, error) {
+	su, ok := r.data[shortUrl]
+	if !ok {
+		return domain.ShortUrl{}, repository.ErrDataNotFound
+	}
+	return su, nil
+}
+
+func (r *memShortUrlRepo) ResolveShortUrl(ctx context.Context, shortUrl string) (domain.ShortUrl, error) {
+	su, ok := r.data[shortUrl]
+	if !ok {
+		return domain.ShortUrl{}, repository.ErrDataNotFound
+	}
+	return su, nil
+}
+
+func TestCachedShortUrlService_Password(t *testing.T) {
+	repo := &memShortUrlRepo{data: map[string]domain.ShortUrl{}}
+	svc := NewCachedShortUrlService(repo, logger.NewNopLogger(), "_", nil, ExpirationPolicy{
+		Default: time.Hour,
+		Min:     time.Minute,
+		Max:     24 * time.Hour,
+	}, 10, nil)
+
+	_, err := svc.Create(context.Background(), domain.ShortUrl{
+		ShortUrl:  "docs",
+		OriginUrl: "https://example.com/internal",
+		Password:  "s3cret",
+	}, domain.Expiration{})
+	assert.NoError(t, err)
+	_, err = svc.Create(context.Background(), domain.ShortUrl{
+		ShortUrl:  "open",
+		OriginUrl: "https://example.com",
+	}, domain.Expiration{})
+	assert.NoError(t, err)
+
+	stored := repo.data["docs"]
+	assert.Empty(t, stored.Password)
+	assert.True(t, stored.IsProtected())
+	assert.NotContains(t, stored.PasswordHash, "s3cret")
+
+	_, err = svc.Create(context.Background(), domain.ShortUrl{
+		ShortUrl:  "short",
+		OriginUrl: "https://example.com",
+		Password:  "abc",
+	}, domain.Expiration{})
+	assert.ErrorIs(t, err, ErrInvalidPassword)
+
+	testCases := []struct {
+		name     string
+		shortUrl string
+		password string
+		wantOk   bool
+		wantErr  error
+	}{
+		{name: "密码正确", shortUrl: "docs", password: "s3cret", wantOk: true},
+		{name: "密码错误", shortUrl: "docs", password: "wrong"},
+		{name: "无需密码的短链接", shortUrl: "open", password: "", wantOk: true},
+		{name: "短链接不存在", shortUrl: "missing", password: "s3cret", wantErr: ErrShortUrlNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ok, err := svc.VerifyPassword(context.Background(), tc.shortUrl, tc.password)
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.Equal(t, tc.wantOk, ok)
+		})
+	}
+}
+
+func TestCachedShortUrlService_ConsumeClick(t *testing.T) {
+	repo := &memShortUrlRepo{
+		data: map[string]domain.ShortUrl{
+			"secret": {ShortUrl: "secret", OriginUrl: "https://example.com/a", MaxClicks: 2},
+			"open":   {ShortUrl: "open", OriginUrl: "https://example.com/b"},
+		},
+		used:     map[string]int64{},
+		disabled: make(chan string, 10),
+	}
+	svc := NewCachedShortUrlService(repo, logger.NewNopLogger(), "_", nil, ExpirationPolicy{}, 10, nil)
+
+	testCases := []struct {
+		name          string
+		shortUrl      string
+		wantRemaining int64
+		wantOk        bool
+		wantDisabled  bool
+		wantErr       error
+	}{
+		{name: "不限次数", shortUrl: "open", wantRemaining: -1, wantOk: true},
+		{name: "第一次点击", shortUrl: "secret", wantRemaining: 1, wantOk: true},
+		{name: "最后一次点击后失效", shortUrl: "secret", wantRemaining: 0, wantOk: true, wantDisabled: true},
+		{name: "预算已耗尽", shortUrl: "secret", wantOk: false, wantDisabled: true},
+		{name: "短链接不存在", shortUrl: "missing", wantErr: ErrShortUrlNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			remaining, ok, err := svc.ConsumeClick(context.Background(), tc.shortUrl)
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.Equal(t, tc.wantOk, ok)
+			assert.Equal(t, tc.wantRemaining, remaining)
+			if tc.wantDisabled {
+				select {
+				case shortUrl := <-repo.disabled:
+					assert.Equal(t, tc.shortUrl, shortUrl)
+				case <-time.After(time.Second):
+					t.Fatal("short url not disabled")
+				}
+			}
+		})
+	}
+	assert.Empty(t, repo.disabled)
+}
+
+func TestCachedShortUrlService_NotBefore(t *testing.T) {
+	svc := NewCachedShortUrlService(&memShortUrlRepo{data: map[string]domain.ShortUrl{}}, logger.NewNopLogger(), "_", nil, ExpirationPolicy{
+		Default: 24 * time.Hour,
+		Min:     time.Minute,
+		Max:     7 * 24 * time.Hour,
+	}, 10, nil)
+	now := time.Now().Unix()
+	launch := now + 3*86400
+
+	testCases := []struct {
+		name          string
+		exp           domain.Expiration
+		wantExpiredAt int64
+		wantErr       error
+	}{
+		{
+			name:          "默认有效期从生效时间起算",
+			exp:           domain.Expiration{NotBefore: launch},
+			wantExpiredAt: launch + 86400,
+		},
+		{
+			name:          "ttl 从生效时间起算",
+			exp:           domain.Expiration{NotBefore: launch, TTL: time.Hour},
+			wantExpiredAt: launch + 3600,
+		},
+		{
+			name:    "过期时间不能早于生效时间",
+			exp:     domain.Expiration{NotBefore: launch, ExpiredAt: launch - 3600},
+			wantErr: ErrInvalidExpiration,
+		},
+		{
+			name:          "生效时间早于当前时间时立即生效",
+			exp:           domain.Expiration{NotBefore: now - 3600, TTL: time.Hour},
+			wantExpiredAt: now + 3600,
+		},
+		{
+			name:    "生效时间不能为负数",
+			exp:     domain.Expiration{NotBefore: -1},
+			wantErr: ErrInvalidExpiration,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			su, err := svc.Create(context.Background(), domain.ShortUrl{
+				ShortUrl:  "launch-" + string(rune('a'+i)),
+				OriginUrl: "https://example.com",
+			}, tc.exp)
+			assert.ErrorIs(t, err, tc.wantErr)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.exp.NotBefore, su.NotBefore)
+			assert.InDelta(t, tc.wantExpiredAt, su.ExpiredAt, 1)
+		})
+	}
+}
+
+func TestCachedShortUrlService_ResolveExpiration(t *testing.T) {
+	policy := ExpirationPolicy{
+		Default:    24 * time.Hour,
+		Min:        time.Minute,
+		Max:        7 * 24 * time.Hour,
+		AllowNever: true,
+	}
+	now := time.Now().Unix()
+
+	testCases := []struct {
+		name          string
+		policy        ExpirationPolicy
+		exp           domain.Expiration
+		wantExpiredAt int64
+		wantErr       error
+	}{
+		{name: "未指定时使用默认有效期", policy: policy, wantExpiredAt: now + 86400},
+		{name: "指定 ttl", policy: policy, exp: domain.Expiration{TTL: time.Hour}, wantExpiredAt: now + 3600},
+		{name: "ttl 等于最短有效期", policy: policy, exp: domain.Expiration{TTL: time.Minute}, wantExpiredAt: now + 60},
+		{name: "ttl 短于最短有效期", policy: policy, exp: domain.Expiration{TTL: time.Second}, wantErr: ErrInvalidExpiration},
+		{name: "ttl 超过最长有效期", policy: policy, exp: domain.Expiration{TTL: 8 * 24 * time.Hour}, wantErr: ErrInvalidExpiration},
+		{name: "指定过期时间", policy: policy, exp: domain.Expiration{ExpiredAt: now + 7200}, wantExpiredAt: now + 7200},
+		{name: "过期时间早于当前时间", policy: policy, exp: domain.Expiration{ExpiredAt: now - 60}, wantErr: ErrInvalidExpiration},
+		{name: "同时指定过期时间与 ttl", policy: policy, exp: domain.Expiration{ExpiredAt: now + 7200, TTL: time.Hour}, wantErr: ErrInvalidExpiration},
+		{name: "永不过期", policy: policy, exp: domain.Expiration{Never: true}, wantExpiredAt: domain.NeverExpire},
+		{name: "永不过期时不能再指定 ttl", policy: policy, exp: domain.Expiration{Never: true, TTL: time.Hour}, wantErr: ErrInvalidExpiration},
+		{
+			name:    "策略不允许永不过期",
+			policy:  ExpirationPolicy{Default: time.Hour, Min: time.Minute, Max: time.Hour},
+			exp:     domain.Expiration{Never: true},
+			wantErr: ErrInvalidExpiration,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := NewCachedShortUrlService(&memShortUrlRepo{}, logger.NewNopLogger(), "_", nil, tc.policy, 10, nil)
+			expiredAt, err := svc.resolveExpiration(tc.exp)
+			assert.ErrorIs(t, err, tc.wantErr)
+			if err != nil {
+				return
+			}
+			if tc.wantExpiredAt == domain.NeverExpire {
+				assert.Equal(t, domain.NeverExpire, expiredAt)
+				return
+			}
+			assert.InDelta(t, tc.wantExpiredAt, expiredAt, 1)
+		})
+	}
+}
+
+func TestCachedShortUrlService_CreateExisting(t *testing.T) {
+	weights := []int{1, 2, 3, 4, 5, 6}
+	now := time.Now().Unix()
+	origin := "https://example.com/existing"
+	code := generator.GenerateShortUrl(origin, "", weights)
+
+	testCases := []struct {
+		name   string
+		stored domain.ShortUrl
+		// wantStored 为 true 时应原样返回已有的短链接，否则应追加后缀生成新的短码
+		wantStored bool
+	}{
+		{
+			name:       "返回库中已有短链接的有效期",
+			stored:     domain.ShortUrl{ShortUrl: code, OriginUrl: origin, ExpiredAt: now + 600},
+			wantStored: true,
+		},
+		{
+			name:   "已过期的短链接不再复用",
+			stored: domain.ShortUrl{ShortUrl: code, OriginUrl: origin, ExpiredAt: now - 600},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &memShortUrlRepo{data: map[string]domain.ShortUrl{code: tc.stored}}
+			svc := NewCachedShortUrlService(repo, logger.NewNopLogger(), "_", weights, ExpirationPolicy{
+				Default: time.Hour,
+				Min:     time.Minute,
+				Max:     24 * time.Hour,
+			}, 10, nil)
+
+			su, err := svc.Create(context.Background(), domain.ShortUrl{OriginUrl: origin}, domain.Expiration{TTL: 2 * time.Hour})
+			assert.NoError(t, err)
+			if tc.wantStored {
+				assert.Equal(t, tc.stored, su)
+				return
+			}
+			assert.Equal(t, generator.GenerateShortUrl(origin, "_", weights), su.ShortUrl)
+			assert.InDelta(t, now+7200, su.ExpiredAt, 1)
+			assert.Equal(t, su, repo.data[su.ShortUrl])
+		})
+	}
+}
+
+func TestCachedShortUrlService_CreateDifferentAttributes(t *testing.T) {
+	repo := &memShortUrlRepo{data: map[string]domain.ShortUrl{}}
+	svc := NewCachedShortUrlService(repo, logger.NewNopLogger(), "_", []int{1, 2, 3, 4, 5, 6}, ExpirationPolicy{
+		Default: time.Hour,
+		Min:     time.Minute,
+		Max:     24 * time.Hour,
+	}, 10, nil)
+	origin := "https://example.com/doc"
+
+	// 用例按顺序执行，同一原链接先后以不同属性创建
+	testCases := []struct {
+		name string
+		su   domain.ShortUrl
+		// sameAs 为应返回相同短码的前序用例下标，-1 表示应生成新的短码
+		sameAs  int
+		wantErr error
+	}{
+		{name: "首次创建", su: domain.ShortUrl{OriginUrl: origin}, sameAs: -1},
+		{name: "加上密码后生成新短码", su: domain.ShortUrl{OriginUrl: origin, Password: "s3cret"}, sameAs: -1},
+		{name: "相同密码复用带密码的短码", su: domain.ShortUrl{OriginUrl: origin, Password: "s3cret"}, sameAs: 1},
+		{name: "不同密码生成新短码", su: domain.ShortUrl{OriginUrl: origin, Password: "other"}, sameAs: -1},
+		{name: "不同跳转状态码生成新短码", su: domain.ShortUrl{OriginUrl: origin, RedirectCode: 301}, sameAs: -1},
+		{name: "属性相同复用首个短码", su: domain.ShortUrl{OriginUrl: origin}, sameAs: 0},
+		{name: "一次性链接", su: domain.ShortUrl{OriginUrl: origin, MaxClicks: 1}, sameAs: -1},
+		{name: "一次性链接不复用，避免共享点击预算", su: domain.ShortUrl{OriginUrl: origin, MaxClicks: 1}, sameAs: -1},
+		{name: "自定义别名", su: domain.ShortUrl{ShortUrl: "doc", OriginUrl: origin}, sameAs: -1},
+		{name: "别名已被同一原链接以不同属性占用", su: domain.ShortUrl{ShortUrl: "doc", OriginUrl: origin, Password: "s3cret"}, wantErr: ErrAliasConflict},
+	}
+	codes := make([]string, len(testCases))
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			su, err := svc.Create(context.Background(), tc.su, domain.Expiration{})
+			assert.ErrorIs(t, err, tc.wantErr)
+			if err != nil {
+				return
+			}
+			codes[i] = su.ShortUrl
+			assert.Equal(t, tc.su.Password != "", su.IsProtected())
+			if tc.sameAs >= 0 {
+				assert.Equal(t, codes[tc.sameAs], su.ShortUrl)
+				return
+			}
+			assert.NotContains(t, codes[:i], su.ShortUrl)
+		})
+	}
+}
+
+func TestCachedShortUrlService_ResolveExtension(t *testing.T) {
+	policy := ExpirationPolicy{
+		Default:    24 * time.Hour,
+		Min:        time.Minute,
+		Max:        7 * 24 * time.Hour,
+		AllowNever: true,
+	}
+	now := time.Now().Unix()
+	active := domain.ShortUrl{ShortUrl: "active", ExpiredAt: now + 3600}
+	expired := domain.ShortUrl{ShortUrl: "expired", ExpiredAt: now - 3600}
+
+	testCases := []struct {
+		name          string
+		policy        ExpirationPolicy
+		su            domain.ShortUrl
+		exp           domain.Expiration
+		wantExpiredAt int64
+		wantErr       error
+	}{
+		{name: "ttl 从当前过期时间起算", policy: policy, su: active, exp: domain.Expiration{TTL: time.Hour}, wantExpiredAt: now + 7200},
+		{name: "已过期的短链接 ttl 从当前时间起算", policy: policy, su: expired, exp: domain.Expiration{TTL: time.Hour}, wantExpiredAt: now + 3600},
+		{name: "续期后超过最长有效期", policy: policy, su: active, exp: domain.Expiration{TTL: 7 * 24 * time.Hour}, wantErr: ErrInvalidExpiration},
+		{name: "指定更晚的过期时间", policy: policy, su: active, exp: domain.Expiration{ExpiredAt: now + 7200}, wantExpiredAt: now + 7200},
+		{name: "已过期的短链接指定过期时间", policy: policy, su: expired, exp: domain.Expiration{ExpiredAt: now + 600}, wantExpiredAt: now + 600},
+		{name: "过期时间不能早于当前过期时间", policy: policy, su: active, exp: domain.Expiration{ExpiredAt: now + 1800}, wantErr: ErrInvalidExpiration},
+		{name: "指定的过期时间超过最长有效期", policy: policy, su: active, exp: domain.Expiration{ExpiredAt: now + 8*86400}, wantErr: ErrInvalidExpiration},
+		{name: "同时指定过期时间与 ttl", policy: policy, su: active, exp: domain.Expiration{ExpiredAt: now + 7200, TTL: time.Hour}, wantErr: ErrInvalidExpiration},
+		{name: "改为永不过期", policy: policy, su: active, exp: domain.Expiration{Never: true}, wantExpiredAt: domain.NeverExpire},
+		{
+			name:    "策略不允许永不过期",
+			policy:  ExpirationPolicy{Default: time.Hour, Min: time.Minute, Max: 24 * time.Hour},
+			su:      active,
+			exp:     domain.Expiration{Never: true},
+			wantErr: ErrInvalidExpiration,
+		},
+		{
+			name:    "永不过期的短链接无需续期",
+			policy:  policy,
+			su:      domain.ShortUrl{ShortUrl: "never", ExpiredAt: domain.NeverExpire},
+			exp:     domain.Expiration{TTL: time.Hour},
+			wantErr: ErrInvalidExpiration,
+		},
+		{name: "未指定续期方式", policy: policy, su: active, wantErr: ErrInvalidExpiration},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := NewCachedShortUrlService(&memShortUrlRepo{}, logger.NewNopLogger(), "_", nil, tc.policy, 10, nil)
+			expiredAt, err := svc.resolveExtension(tc.su, tc.exp)
+			assert.ErrorIs(t, err, tc.wantErr)
+			if err != nil {
+				return
+			}
+			if tc.wantExpiredAt == domain.NeverExpire {
+				assert.Equal(t, domain.NeverExpire, expiredAt)
+				return
+			}
+			assert.InDelta(t, tc.wantExpiredAt, expiredAt, 1)
+		})
+	}
+}
+
+func TestCachedShortUrlService_BatchCreate(t *testing.T) {
+	weights := []int{1, 2, 3, 4, 5, 6}
+	repo := &memShortUrlRepo{data: map[string]domain.ShortUrl{
+		"taken": {ShortUrl: "taken", OriginUrl: "https://example.com/other"},
+	}}
+	svc := NewCachedShortUrlService(repo, logger.NewNopLogger(), "_", weights, ExpirationPolicy{
+		Default: time.Hour,
+		Min:     time.Minute,
+		Max:     24 * time.Hour,
+	}, 8, nil)
+	origin := "https://example.com/campaign"
+
+	items := []BatchCreateItem{
+		{ShortUrl: domain.ShortUrl{OriginUrl: origin}},
+		{ShortUrl: domain.ShortUrl{OriginUrl: origin}},
+		{ShortUrl: domain.ShortUrl{OriginUrl: origin, Password: "s3cret"}},
+		{ShortUrl: domain.ShortUrl{OriginUrl: origin, MaxClicks: 1}},
+		{ShortUrl: domain.ShortUrl{OriginUrl: origin, MaxClicks: 1}},
+		{ShortUrl: domain.ShortUrl{}},
+		{ShortUrl: domain.ShortUrl{ShortUrl: "taken", OriginUrl: origin}},
+		{ShortUrl: domain.ShortUrl{OriginUrl: origin}, Expiration: domain.Expiration{TTL: time.Second}},
+	}
+	results, err := svc.BatchCreate(context.Background(), items)
+	assert.NoError(t, err)
+	assert.Len(t, results, len(items))
+
+	testCases := []struct {
+		name string
+		i    int
+		// sameAs 为应返回相同短码的条目下标，-1 表示应生成不同于其他条目的短码
+		sameAs  int
+		wantErr error
+	}{
+		{name: "首次出现的原链接", i: 0, sameAs: -1},
+		{name: "同批次重复的原链接复用首条结果", i: 1, sameAs: 0},
+		{name: "同一原链接但带密码", i: 2, sameAs: -1},
+		{name: "一次性链接", i: 3, sameAs: -1},
+		{name: "同批次重复的一次性链接不复用", i: 4, sameAs: -1},
+		{name: "原链接为空", i: 5, wantErr: ErrInvalidOriginUrl},
+		{name: "别名已被占用", i: 6, wantErr: ErrAliasConflict},
+		{name: "有效期不合法", i: 7, wantErr: ErrInvalidExpiration},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := results[tc.i]
+			assert.ErrorIs(t, res.Err, tc.wantErr)
+			if res.Err != nil {
+				return
+			}
+			assert.Equal(t, res.ShortUrl, repo.data[res.ShortUrl.ShortUrl])
+			if tc.sameAs >= 0 {
+				assert.Equal(t, results[tc.sameAs].ShortUrl, res.ShortUrl)
+				return
+			}
+			// 除声明复用本条结果的条目外，短码不与其他条目重复
+			for _, other := range testCases {
+				if other.i != tc.i && other.sameAs != tc.i && results[other.i].Err == nil {
+					assert.NotEqual(t, res.ShortUrl.ShortUrl, results[other.i].ShortUrl.ShortUrl, other.name)
+				}
+			}
+		})
+	}
+
+	_, err = svc.BatchCreate(context.Background(), make([]BatchCreateItem, 9))
+	assert.ErrorIs(t, err, ErrBatchTooLarge)
+}
+
+func TestCachedShortUrlService_BatchRedirect(t *testing.T) {
+	repo := &memShortUrlRepo{data: map[string]domain.ShortUrl{
+		"open":   {ShortUrl: "open", OriginUrl: "https://example.com/open"},
+		"docs":   {ShortUrl: "docs", OriginUrl: "https://example.com/internal", PasswordHash: "hash"},
+		"once":   {ShortUrl: "once", OriginUrl: "https://example.com/once", MaxClicks: 1},
+		"secret": {ShortUrl: "secret", OriginUrl: "https://example.com/secret", PasswordHash: "hash", MaxClicks: 3},
+	}}
+	svc := NewCachedShortUrlService(repo, logger.NewNopLogger(), "_", nil, ExpirationPolicy{}, 5, nil)
+
+	testCases := []struct {
+		name       string
+		shortUrl   string
+		wantOrigin string
+		wantErr    error
+	}{
+		{name: "普通短链接", shortUrl: "open", wantOrigin: "https://example.com/open"},
+		{name: "需要密码", shortUrl: "docs", wantErr: ErrPasswordRequired},
+		{name: "限制点击次数", shortUrl: "once", wantErr: ErrClickLimited},
+		{name: "需要密码且限制点击次数", shortUrl: "secret", wantErr: ErrPasswordRequired},
+		{name: "短链接不存在", shortUrl: "missing", wantErr: ErrShortUrlNotFound},
+	}
+	shortUrls := make([]string, 0, len(testCases))
+	for _, tc := range testCases {
+		shortUrls = append(shortUrls, tc.shortUrl)
+	}
+	results, err := svc.BatchRedirect(context.Background(), shortUrls)
+	assert.NoError(t, err)
+	assert.Len(t, results, len(testCases))
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ErrorIs(t, results[i].Err, tc.wantErr)
+			assert.Equal(t, tc.wantOrigin, results[i].OriginUrl)
+		})
+	}
+
+	_, err = svc.BatchRedirect(context.Background(), make([]string, 6))
+	assert.ErrorIs(t, err, ErrBatchTooLarge)
+	// 不消耗点击预算
+	assert.Empty(t, repo.used)
+}
@@ -1,10 +1,66 @@
 package service
 
-import "context"
+import (
+	"context"
+	"short_url/rpc/domain"
+)
 
 type ShortUrlService interface {
-	Create(ctx context.Context, originUrl string) (string, error)
-	Redirect(ctx context.Context, shortUrl string) (string, error)
+	// Create 创建短链接，su.ShortUrl 不为空时作为自定义别名，返回最终的短链接与过期时间
+	Create(ctx context.Context, su domain.ShortUrl, exp domain.Expiration) (domain.ShortUrl, error)
+	// BatchCreate 批量创建短链接，返回结果与 items 一一对应，单条失败不影响其他条目
+	BatchCreate(ctx context.Context, items []BatchCreateItem) ([]BatchCreateResult, error)
+	// Redirect 返回未过期短链接的跳转信息
+	Redirect(ctx context.Context, shortUrl string) (domain.ShortUrl, error)
+	// VerifyPassword 校验短链接的访问密码，短链接无需密码时返回 true
+	VerifyPassword(ctx context.Context, shortUrl, password string) (bool, error)
+	// ConsumeClick 扣减一次点击预算，返回扣减后的剩余次数与是否允许跳转，不限次数时剩余次数为 -1。
+	// 预算耗尽后短链接立即过期
+	ConsumeClick(ctx context.Context, shortUrl string) (int64, bool, error)
+	// BatchRedirect 批量解析短链接，返回结果与 shortUrls 一一对应。
+	// 需要密码或限制点击次数的短链接返回 ErrPasswordRequired 或 ErrClickLimited
+	BatchRedirect(ctx context.Context, shortUrls []string) ([]BatchRedirectResult, error)
+	Info(ctx context.Context, shortUrl string) (domain.ShortUrl, error)
+	UpdateOriginUrl(ctx context.Context, shortUrl, originUrl string) (domain.ShortUrl, error)
+	Delete(ctx context.Context, shortUrl string) error
+	// ExtendExpiration 延长短链接有效期，exp.TTL 在当前过期时间的基础上累加
+	ExtendExpiration(ctx context.Context, shortUrl string, exp domain.Expiration) (domain.ShortUrl, error)
 	CleanExpired(ctx context.Context) error
 	RebuildBloomFilter(ctx context.Context) error
 }
+
+// ClickStatService 点击事件聚合与查询
+type ClickStatService interface {
+	// RecordClicks 将点击事件按小时与天聚合后累加，返回实际计入统计的事件数
+	RecordClicks(ctx context.Context, events []domain.ClickEvent) (int, error)
+	// ClickStats 查询 [start, end) 内的点击时间序列，start、end 为 0 时使用默认区间
+	ClickStats(ctx context.Context, shortUrl string, granularity domain.Granularity, start, end int64) (domain.ClickStats, error)
+	// CleanUniqueVisitors 删除超过保留期的独立访客计数
+	CleanUniqueVisitors(ctx context.Context) error
+	// TopLinks 返回最近 window 内点击数最多的 n 个短链接，n 为 0 时使用默认值
+	TopLinks(ctx context.Context, window domain.TopWindow, n int) ([]domain.LinkClicks, error)
+}
+
+// ApiKeyService 校验 /api 接口调用方的请求签名，签名密钥不离开 rpc 层
+type ApiKeyService interface {
+	// Find 返回可用的 API Key，不存在或已禁用时返回 ErrApiKeyNotFound
+	Find(ctx context.Context, keyID string) (domain.ApiKey, error)
+	// Verify 使用 keyID 对应的密钥对 params 签名并与 signature 比较，
+	// Key 不可用时返回 ErrApiKeyNotFound，签名不一致时返回 ErrInvalidSignature
+	Verify(ctx context.Context, keyID string, params map[string]string, signature string) error
+}
+
+type BatchCreateItem struct {
+	ShortUrl   domain.ShortUrl
+	Expiration domain.Expiration
+}
+
+type BatchCreateResult struct {
+	ShortUrl domain.ShortUrl
+	Err      error
+}
+
+type BatchRedirectResult struct {
+	OriginUrl string
+	Err       error
+}
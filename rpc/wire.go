@@ -5,6 +5,7 @@ package main
 import (
 	"short_url/rpc/grpc"
 	"short_url/rpc/ioc"
+	"short_url/rpc/repository"
 	"short_url/rpc/repository/dao"
 
 	"github.com/google/wire"
@@ -17,18 +18,32 @@ func Init() *App {
 		ioc.InitRedis,
 		ioc.InitEtcdClient,
 
-		dao.NewGormShortUrlDAO,
+		ioc.InitShortUrlDAO,
 
 		ioc.InitBloomFilter,
 		ioc.InitBloomFilterCache,
 		ioc.InitRedisCache,
+		ioc.InitCacheInvalidator,
+		ioc.InitClickBudgetCache,
 		ioc.InitCachedRepository,
 		ioc.InitService,
+		dao.NewGormClickStatDAO,
+		ioc.InitUniqueVisitorCache,
+		ioc.InitHotLinkCache,
+		repository.NewClickStatRepository,
+		ioc.InitClickStatService,
+		dao.NewGormApiKeyDAO,
+		ioc.InitApiKeyCache,
+		ioc.InitApiKeyRepository,
+		ioc.InitApiKeyService,
 		grpc.NewShortUrlServiceServer,
 
-		ioc.InitCleanerJob,
+		ioc.InitJobList,
+		ioc.InitElector,
 		ioc.InitJobs,
 		ioc.InitGrpcxServer,
+		ioc.InitMetricsServer,
+		ioc.InitLifecycle,
 
 		wire.Struct(new(App), "*"),
 	)
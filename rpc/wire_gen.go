@@ -9,6 +9,7 @@ package main
 import (
 	"short_url/rpc/grpc"
 	"short_url/rpc/ioc"
+	"short_url/rpc/repository"
 	"short_url/rpc/repository/dao"
 )
 
@@ -20,18 +21,36 @@ func Init() *App {
 	shortUrlCache := ioc.InitRedisCache(cmdable)
 	bloomService := ioc.InitBloomFilter(cmdable)
 	bloomFilterCache := ioc.InitBloomFilterCache(bloomService)
+	cacheInvalidator := ioc.InitCacheInvalidator(cmdable)
+	clickBudgetCache := ioc.InitClickBudgetCache(cmdable)
 	logger := ioc.InitLogger()
 	db := ioc.InitDB(logger, cmdable)
-	shortUrlDAO := dao.NewGormShortUrlDAO(db, logger)
-	shortUrlRepository := ioc.InitCachedRepository(shortUrlCache, bloomFilterCache, shortUrlDAO, logger)
+	shortUrlDAO := ioc.InitShortUrlDAO(db, logger)
+	shortUrlRepository := ioc.InitCachedRepository(shortUrlCache, bloomFilterCache, cacheInvalidator, clickBudgetCache, shortUrlDAO, logger)
 	shortUrlService := ioc.InitService(client, shortUrlRepository, logger)
-	shortUrlServiceServer := grpc.NewShortUrlServiceServer(shortUrlService)
+	clickStatDAO := dao.NewGormClickStatDAO(db)
+	uniqueVisitorCache := ioc.InitUniqueVisitorCache(cmdable)
+	hotLinkCache := ioc.InitHotLinkCache(cmdable)
+	clickStatRepository := repository.NewClickStatRepository(clickStatDAO, uniqueVisitorCache, hotLinkCache)
+	clickStatService := ioc.InitClickStatService(clickStatRepository, shortUrlRepository, logger)
+	apiKeyDAO := dao.NewGormApiKeyDAO(db)
+	apiKeyCache := ioc.InitApiKeyCache(cmdable)
+	apiKeyRepository := ioc.InitApiKeyRepository(apiKeyDAO, apiKeyCache, logger)
+	apiKeyService := ioc.InitApiKeyService(apiKeyRepository)
+	shortUrlServiceServer := grpc.NewShortUrlServiceServer(shortUrlService, clickStatService, apiKeyService)
 	server := ioc.InitGrpcxServer(shortUrlServiceServer, client, logger)
-	job := ioc.InitCleanerJob(shortUrlService)
-	cron := ioc.InitJobs(logger, job)
+	elector := ioc.InitElector(client, logger)
+	v := ioc.InitJobList(shortUrlService, clickStatService)
+	cron := ioc.InitJobs(logger, elector, v)
+	httpServer := ioc.InitMetricsServer()
+	manager := ioc.InitLifecycle(logger)
 	app := &App{
-		GrpcServer: server,
-		Cron:       cron,
+		GrpcServer:    server,
+		Cron:          cron,
+		Elector:       elector,
+		MetricsServer: httpServer,
+		DAO:           shortUrlDAO,
+		Lifecycle:     manager,
 	}
 	return app
 }
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// 创建或禁用 /api 接口的 API Key，创建时密钥只输出一次，需妥善保存
+//
+//	go run ./scripts/apikey -name partner
+//	go run ./scripts/apikey -disable ak_0123456789abcdef
+func main() {
+	dsn := flag.String("dsn", "root:123456@tcp(127.0.0.1:3306)/short_url", "MySQL 连接串")
+	name := flag.String("name", "", "调用方备注")
+	disable := flag.String("disable", "", "要禁用的 Key")
+	flag.Parse()
+
+	// 数据库连接
+	db, err := sql.Open("mysql", *dsn)
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	now := time.Now().Unix()
+	if *disable != "" {
+		res, err := db.Exec("UPDATE api_keys SET disabled = 1, updated_at = ? WHERE key_id = ?", now, *disable)
+		if err != nil {
+			panic(err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			fmt.Println("api key not found:", *disable)
+			return
+		}
+		// rpc 层会缓存密钥，禁用在 api_key.expiration 之后生效
+		fmt.Println("api key disabled:", *disable)
+		return
+	}
+
+	keyID := "ak_" + randomHex(8)
+	secret := randomHex(32)
+	_, err = db.Exec("INSERT INTO api_keys (key_id, secret, name, disabled, created_at, updated_at) VALUES (?, ?, ?, 0, ?, ?)",
+		keyID, secret, *name, now, now)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("key_id:", keyID)
+	fmt.Println("secret:", secret)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
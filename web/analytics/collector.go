@@ -0,0 +1,246 @@
+package analytics
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	short_url_v1 "short_url/proto/short_url/v1"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/to404hanga/pkg404/logger"
+)
+
+// referrer 与 user agent 的最大保留长度，避免异常请求放大上报数据
+const maxHeaderLength = 512
+
+// ClickEvent 一次成功跳转产生的点击事件
+type ClickEvent struct {
+	ShortUrl  string
+	Timestamp time.Time
+	Referrer  string
+	UserAgent string
+	IPHash    string // 加盐哈希后的客户端 IP
+	Country   string // 由客户端 IP 解析的国家代码，无法解析时为空
+	Variant   int32  // 命中的 A/B 分流目标序号，从 1 开始，0 表示未分流
+}
+
+// Collector 点击事件收集器
+type Collector interface {
+	// Collect 提交点击事件，不阻塞调用方，队列已满时直接丢弃
+	Collect(ev ClickEvent)
+	// Close 停止接收事件，并在 ctx 结束前上报队列中剩余的事件
+	Close(ctx context.Context) error
+}
+
+// Options 异步收集器配置，零值字段使用默认值
+type Options struct {
+	QueueSize     int           // 事件队列长度，默认 10000
+	BatchSize     int           // 单次上报的最大事件数，默认 500
+	FlushInterval time.Duration // 未攒满一批时的最长上报间隔，默认 1s
+	ReportTimeout time.Duration // 单次上报的超时时间，默认 3s
+	Workers       int           // 上报协程数，默认 2
+}
+
+// Stats 收集器运行指标快照
+type Stats struct {
+	Queued    int   `json:"queued"`    // 队列中等待上报的事件数
+	Collected int64 `json:"collected"` // 成功进入队列的事件数
+	Dropped   int64 `json:"dropped"`   // 因队列已满或已关闭被丢弃的事件数
+	Reported  int64 `json:"reported"`  // 上报成功的事件数
+	Failed    int64 `json:"failed"`    // 上报失败被丢弃的事件数
+}
+
+// AsyncCollector 将点击事件放入有界队列，由后台协程攒批后通过 ReportClicks 上报 rpc 层。
+// 统计数据允许少量丢失，上报失败时不重试，以免拖慢跳转请求
+type AsyncCollector struct {
+	svc           short_url_v1.ShortUrlServiceClient
+	l             logger.Logger
+	events        chan ClickEvent
+	batchSize     int
+	flushInterval time.Duration
+	reportTimeout time.Duration
+	stopChan      chan struct{}
+	closed        atomic.Bool
+	closeOnce     sync.Once
+	wg            sync.WaitGroup
+	collected     atomic.Int64
+	dropped       atomic.Int64
+	reported      atomic.Int64
+	failed        atomic.Int64
+}
+
+var _ Collector = (*AsyncCollector)(nil)
+
+func NewAsyncCollector(svc short_url_v1.ShortUrlServiceClient, l logger.Logger, opts Options) *AsyncCollector {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 10000
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+	if opts.ReportTimeout <= 0 {
+		opts.ReportTimeout = 3 * time.Second
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = 2
+	}
+
+	c := &AsyncCollector{
+		svc:           svc,
+		l:             l,
+		events:        make(chan ClickEvent, opts.QueueSize),
+		batchSize:     opts.BatchSize,
+		flushInterval: opts.FlushInterval,
+		reportTimeout: opts.ReportTimeout,
+		stopChan:      make(chan struct{}),
+	}
+	for i := 0; i < opts.Workers; i++ {
+		c.wg.Add(1)
+		go c.worker()
+	}
+	return c
+}
+
+func (c *AsyncCollector) Collect(ev ClickEvent) {
+	if c.closed.Load() {
+		c.dropped.Add(1)
+		return
+	}
+	ev.Referrer = truncate(ev.Referrer, maxHeaderLength)
+	ev.UserAgent = truncate(ev.UserAgent, maxHeaderLength)
+	select {
+	case c.events <- ev:
+		c.collected.Add(1)
+	default:
+		c.dropped.Add(1)
+	}
+}
+
+func (c *AsyncCollector) worker() {
+	defer c.wg.Done()
+
+	batch := make([]ClickEvent, 0, c.batchSize)
+	ticker := time.NewTicker(c.flushInterval)
+	defer ticker.Stop()
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		c.report(batch)
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case ev := <-c.events:
+			batch = append(batch, ev)
+			if len(batch) >= c.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-c.stopChan:
+			// 退出前清空队列，剩余事件按批上报
+			for {
+				select {
+				case ev := <-c.events:
+					batch = append(batch, ev)
+					if len(batch) >= c.batchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (c *AsyncCollector) report(batch []ClickEvent) {
+	events := make([]*short_url_v1.ClickEvent, 0, len(batch))
+	for _, ev := range batch {
+		events = append(events, &short_url_v1.ClickEvent{
+			ShortUrl:  ev.ShortUrl,
+			Timestamp: ev.Timestamp.UnixMilli(),
+			Referrer:  ev.Referrer,
+			UserAgent: ev.UserAgent,
+			IpHash:    ev.IPHash,
+			Country:   ev.Country,
+			Variant:   ev.Variant,
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.reportTimeout)
+	defer cancel()
+	if _, err := c.svc.ReportClicks(ctx, &short_url_v1.ReportClicksRequest{Events: events}); err != nil {
+		c.failed.Add(int64(len(batch)))
+		c.l.Error("report click events failed",
+			logger.Error(err),
+			logger.Int("count", len(batch)),
+		)
+		return
+	}
+	c.reported.Add(int64(len(batch)))
+}
+
+func (c *AsyncCollector) Close(ctx context.Context) error {
+	c.closeOnce.Do(func() {
+		c.closed.Store(true)
+		close(c.stopChan)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *AsyncCollector) Stats() Stats {
+	return Stats{
+		Queued:    len(c.events),
+		Collected: c.collected.Load(),
+		Dropped:   c.dropped.Load(),
+		Reported:  c.reported.Load(),
+		Failed:    c.failed.Load(),
+	}
+}
+
+// NopCollector 关闭点击统计时使用，丢弃所有事件
+type NopCollector struct{}
+
+var _ Collector = NopCollector{}
+
+func (NopCollector) Collect(ClickEvent) {}
+
+func (NopCollector) Close(context.Context) error { return nil }
+
+// HashIP 使用 HMAC-SHA256 对客户端 IP 加盐哈希，各实例使用相同的盐才能得到一致的访客标识
+func HashIP(salt, ip string) string {
+	if ip == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(ip))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,125 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	short_url_v1 "short_url/proto/short_url/v1"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/to404hanga/pkg404/logger"
+	"google.golang.org/grpc"
+)
+
+type reportClient struct {
+	short_url_v1.ShortUrlServiceClient
+	mu      sync.Mutex
+	batches [][]*short_url_v1.ClickEvent
+	err     error
+}
+
+func (c *reportClient) ReportClicks(ctx context.Context, in *short_url_v1.ReportClicksRequest, opts ...grpc.CallOption) (*short_url_v1.ReportClicksResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return nil, c.err
+	}
+	c.batches = append(c.batches, in.GetEvents())
+	return &short_url_v1.ReportClicksResponse{Accepted: int32(len(in.GetEvents()))}, nil
+}
+
+func TestAsyncCollector(t *testing.T) {
+	testCases := []struct {
+		name         string
+		events       int
+		err          error
+		wantBatches  []int // 各批次的事件数
+		wantReported int64
+		wantFailed   int64
+	}{
+		{
+			name:         "按批次大小切分，关闭时上报剩余事件",
+			events:       5,
+			wantBatches:  []int{2, 2, 1},
+			wantReported: 5,
+		},
+		{
+			name:       "上报失败时丢弃事件",
+			events:     3,
+			err:        errors.New("rpc unavailable"),
+			wantFailed: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &reportClient{err: tc.err}
+			c := NewAsyncCollector(client, logger.NewNopLogger(), Options{
+				QueueSize:     10,
+				BatchSize:     2,
+				FlushInterval: time.Hour,
+				Workers:       1,
+			})
+			for i := 0; i < tc.events; i++ {
+				c.Collect(ClickEvent{ShortUrl: "abc", Timestamp: time.Now()})
+			}
+			assert.NoError(t, c.Close(context.Background()))
+
+			sizes := make([]int, 0, len(client.batches))
+			for _, b := range client.batches {
+				sizes = append(sizes, len(b))
+			}
+			if tc.wantBatches == nil {
+				tc.wantBatches = []int{}
+			}
+			assert.Equal(t, tc.wantBatches, sizes)
+			stats := c.Stats()
+			assert.Equal(t, tc.wantReported, stats.Reported)
+			assert.Equal(t, tc.wantFailed, stats.Failed)
+		})
+	}
+}
+
+func TestAsyncCollector_DropWhenFull(t *testing.T) {
+	block := make(chan struct{})
+	client := &blockingClient{block: block}
+	c := NewAsyncCollector(client, logger.NewNopLogger(), Options{
+		QueueSize:     2,
+		BatchSize:     1,
+		FlushInterval: time.Hour,
+		Workers:       1,
+	})
+	// 第一条事件被协程取走后阻塞在上报中，随后队列只能再容纳两条
+	c.Collect(ClickEvent{ShortUrl: "abc"})
+	assert.Eventually(t, func() bool { return len(c.events) == 0 }, time.Second, time.Millisecond)
+	for i := 0; i < 3; i++ {
+		c.Collect(ClickEvent{ShortUrl: "abc"})
+	}
+	assert.Equal(t, int64(1), c.Stats().Dropped)
+
+	close(block)
+	assert.NoError(t, c.Close(context.Background()))
+	assert.Equal(t, int64(3), c.Stats().Reported)
+	// 关闭后提交的事件直接丢弃
+	c.Collect(ClickEvent{ShortUrl: "abc"})
+	assert.Equal(t, int64(2), c.Stats().Dropped)
+}
+
+type blockingClient struct {
+	short_url_v1.ShortUrlServiceClient
+	block chan struct{}
+}
+
+func (c *blockingClient) ReportClicks(ctx context.Context, in *short_url_v1.ReportClicksRequest, opts ...grpc.CallOption) (*short_url_v1.ReportClicksResponse, error) {
+	<-c.block
+	return &short_url_v1.ReportClicksResponse{}, nil
+}
+
+func TestHashIP(t *testing.T) {
+	assert.Equal(t, HashIP("salt", "1.2.3.4"), HashIP("salt", "1.2.3.4"))
+	assert.NotEqual(t, HashIP("salt", "1.2.3.4"), HashIP("other", "1.2.3.4"))
+	assert.Len(t, HashIP("salt", "1.2.3.4"), 32)
+	assert.Empty(t, HashIP("salt", ""))
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"short_url/pkg/lifecycle"
+	"short_url/web/analytics"
+
+	"github.com/gin-gonic/gin"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+type App struct {
+	Engine     *gin.Engine
+	EtcdClient *clientv3.Client
+	Collector  analytics.Collector
+	Lifecycle  *lifecycle.Manager
+}
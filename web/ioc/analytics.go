@@ -0,0 +1,46 @@
+package ioc
+
+import (
+	short_url_v1 "short_url/proto/short_url/v1"
+	"short_url/web/analytics"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/to404hanga/pkg404/logger"
+)
+
+func InitClickCollector(svc short_url_v1.ShortUrlServiceClient, l logger.Logger) analytics.Collector {
+	type Config struct {
+		Enabled       bool  `yaml:"enabled"`
+		QueueSize     int   `yaml:"queueSize"`
+		BatchSize     int   `yaml:"batchSize"`
+		FlushInterval int64 `yaml:"flushInterval"`
+		ReportTimeout int64 `yaml:"reportTimeout"`
+		Workers       int   `yaml:"workers"`
+	}
+	cfg := Config{
+		Enabled:       true,
+		QueueSize:     10000,
+		BatchSize:     500,
+		FlushInterval: 1000, // 单位 ms
+		ReportTimeout: 3000, // 单位 ms
+		Workers:       2,
+	}
+	if err := viper.UnmarshalKey("analytics", &cfg); err != nil {
+		panic(err)
+	}
+	if !cfg.Enabled {
+		return analytics.NopCollector{}
+	}
+	if cfg.QueueSize <= 0 || cfg.BatchSize <= 0 || cfg.BatchSize > cfg.QueueSize {
+		panic("analytics must satisfy 0 < batchSize <= queueSize")
+	}
+
+	return analytics.NewAsyncCollector(svc, l, analytics.Options{
+		QueueSize:     cfg.QueueSize,
+		BatchSize:     cfg.BatchSize,
+		FlushInterval: time.Duration(cfg.FlushInterval) * time.Millisecond,
+		ReportTimeout: time.Duration(cfg.ReportTimeout) * time.Millisecond,
+		Workers:       cfg.Workers,
+	})
+}
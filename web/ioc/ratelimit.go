@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	go_redis_tokenbucket "short_url/pkg/go-redis-tokenbuket"
+	"short_url/web/middlewares"
 	"short_url/web/pkg"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/redis/go-redis/v9"
 	"github.com/spf13/viper"
+	"github.com/to404hanga/pkg404/logger"
 )
 
 // 使用接口包中的RateLimiter接口
@@ -49,6 +52,11 @@ func InitRateLimiter(cmd redis.Cmdable) (RateLimiter, error) {
 		config.Prefix = "rate_limit" // 默认前缀
 	}
 
+	return newTokenBucketLimiter(cmd, config)
+}
+
+// newTokenBucketLimiter 按配置创建令牌桶限流器，config 需已填充默认值
+func newTokenBucketLimiter(cmd redis.Cmdable, config RateLimitConfig) (RateLimiter, error) {
 	// 创建限流器
 	// 确保使用 *redis.Client 类型
 	client, ok := cmd.(*redis.Client)
@@ -71,9 +79,13 @@ func InitRateLimiter(cmd redis.Cmdable) (RateLimiter, error) {
 }
 
 // Allow 检查是否允许请求
-func (l *tokenBucketLimiter) Allow(ctx context.Context, key string, n int64) (bool, error) {
+func (l *tokenBucketLimiter) Allow(ctx context.Context, key string, n int64) (pkg.RateLimitResult, error) {
 	fullKey := fmt.Sprintf("%s:%s", l.config.Prefix, key)
-	return l.limiter.Allow(ctx, fullKey, n)
+	res, err := l.limiter.Allow(ctx, fullKey, n)
+	if err != nil {
+		return pkg.RateLimitResult{}, err
+	}
+	return pkg.RateLimitResult(res), nil
 }
 
 // GetStats 获取限流器统计信息
@@ -82,3 +94,81 @@ func (l *tokenBucketLimiter) GetStats() map[string]interface{} {
 	stats["prefix"] = l.config.Prefix
 	return stats
 }
+
+// RateLimitRuleConfig 一条按 API Key、客户端 IP、路由与请求方法匹配的限流规则
+type RateLimitRuleConfig struct {
+	Name     string        `yaml:"name"`
+	Methods  []string      `yaml:"methods"`
+	Routes   []string      `yaml:"routes"`
+	ApiKeys  []string      `yaml:"apiKeys"`
+	IPs      []string      `yaml:"ips"`
+	By       []string      `yaml:"by"`
+	Rate     time.Duration `yaml:"rate"`
+	Capacity int64         `yaml:"capacity"`
+	Expire   time.Duration `yaml:"expire"`
+}
+
+// InitPolicyRateLimiter 初始化按规则限流的中间件，配置文件变更时重新加载规则，
+// 新规则无效时保留旧规则
+func InitPolicyRateLimiter(cmd redis.Cmdable, l logger.Logger) *middlewares.PolicyRateLimiter {
+	policy, err := loadRateLimitPolicy(cmd)
+	if err != nil {
+		panic(err)
+	}
+	limiter := middlewares.NewPolicyRateLimiter(policy)
+
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		policy, err := loadRateLimitPolicy(cmd)
+		if err != nil {
+			l.Error("failed to reload rate limit rules, keep previous rules",
+				logger.Error(err),
+				logger.String("file", e.Name),
+			)
+			return
+		}
+		limiter.Update(policy)
+		l.Info("rate limit rules reloaded", logger.String("file", e.Name))
+	})
+	return limiter
+}
+
+func loadRateLimitPolicy(cmd redis.Cmdable) (*middlewares.RateLimitPolicy, error) {
+	var cfgs []RateLimitRuleConfig
+	if err := viper.UnmarshalKey("rate_limit.rules", &cfgs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal rate limit rules: %w", err)
+	}
+	prefix := viper.GetString("rate_limit.prefix")
+	if prefix == "" {
+		prefix = "rate_limit"
+	}
+
+	rules := make([]middlewares.RateLimitRule, 0, len(cfgs))
+	for _, c := range cfgs {
+		if c.Rate <= 0 || c.Capacity <= 0 {
+			return nil, fmt.Errorf("rate limit rule %q: rate and capacity must be positive", c.Name)
+		}
+		if c.Expire <= 0 {
+			// 不短于令牌桶从空到满所需的时间，否则桶会提前过期重置为满
+			c.Expire = max(2*time.Duration(c.Capacity)*c.Rate, time.Minute)
+		}
+		limiter, err := newTokenBucketLimiter(cmd, RateLimitConfig{
+			Rate:     c.Rate,
+			Capacity: c.Capacity,
+			Expire:   c.Expire,
+			Prefix:   prefix,
+		})
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, middlewares.RateLimitRule{
+			Name:    c.Name,
+			Methods: c.Methods,
+			Routes:  c.Routes,
+			ApiKeys: c.ApiKeys,
+			IPs:     c.IPs,
+			By:      c.By,
+			Limiter: limiter,
+		})
+	}
+	return middlewares.NewRateLimitPolicy(rules)
+}
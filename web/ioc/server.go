@@ -1,14 +1,104 @@
 package ioc
 
 import (
+	"fmt"
+	"net/http"
+	"short_url/pkg/geoip"
 	short_url_v1 "short_url/proto/short_url/v1"
+	"short_url/web/analytics"
 	"short_url/web/routes"
+	"time"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/spf13/viper"
 )
 
-func InitServerHandler(svc short_url_v1.ShortUrlServiceClient) *routes.ServerHandler {
-	weights := viper.GetIntSlice("short_url.weights")
+func InitServerHandler(svc short_url_v1.ShortUrlServiceClient, collector analytics.Collector, cmd redis.Cmdable) *routes.ServerHandler {
+	type RedirectConfig struct {
+		DefaultCode         int    `yaml:"defaultCode"`
+		NotYetAvailablePage string `yaml:"notYetAvailablePage"`
+	}
+	cfg := RedirectConfig{
+		DefaultCode:         http.StatusFound,
+		NotYetAvailablePage: "./static/not_yet_available.html",
+	}
+	if err := viper.UnmarshalKey("redirect", &cfg); err != nil {
+		panic(err)
+	}
+	switch cfg.DefaultCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		panic(fmt.Sprintf("unsupported redirect.defaultCode: %d", cfg.DefaultCode))
+	}
 
-	return routes.NewServerHandler(svc, weights)
+	ipSalt := viper.GetString("analytics.ipSalt")
+	if ipSalt == "" {
+		panic("analytics.ipSalt is required")
+	}
+
+	type PasswordConfig struct {
+		CookieSecret string          `yaml:"cookieSecret"`
+		CookieTTL    int64           `yaml:"cookieTTL"`
+		Page         string          `yaml:"page"`
+		RateLimit    RateLimitConfig `yaml:"rateLimit"`
+	}
+	pwdCfg := PasswordConfig{
+		CookieTTL: 1800,
+		Page:      "./static/password.html",
+		RateLimit: RateLimitConfig{
+			Rate:     12 * time.Second, // 每分钟恢复 5 次尝试机会
+			Capacity: 5,
+			Expire:   10 * time.Minute,
+			Prefix:   "password_limit",
+		},
+	}
+	if err := viper.UnmarshalKey("password", &pwdCfg); err != nil {
+		panic(err)
+	}
+	if pwdCfg.CookieSecret == "" {
+		panic("password.cookieSecret is required")
+	}
+	if pwdCfg.CookieTTL <= 0 {
+		panic("password.cookieTTL must be positive")
+	}
+	if pwdCfg.RateLimit.Rate <= 0 || pwdCfg.RateLimit.Capacity <= 0 || pwdCfg.RateLimit.Expire <= 0 || pwdCfg.RateLimit.Prefix == "" {
+		panic("password.rateLimit is invalid")
+	}
+	limiter, err := newTokenBucketLimiter(cmd, pwdCfg.RateLimit)
+	if err != nil {
+		panic(err)
+	}
+
+	type VariantConfig struct {
+		CookieTTL int64 `yaml:"cookieTTL"`
+	}
+	variantCfg := VariantConfig{CookieTTL: 30 * 24 * 3600}
+	if err := viper.UnmarshalKey("variant", &variantCfg); err != nil {
+		panic(err)
+	}
+	if variantCfg.CookieTTL <= 0 {
+		panic("variant.cookieTTL must be positive")
+	}
+
+	// 未配置 GeoIP 数据库时不解析国家，按国家的跳转规则不会命中
+	var geo *geoip.Reader
+	if path := viper.GetString("geoip.path"); path != "" {
+		if geo, err = geoip.Open(path); err != nil {
+			panic(err)
+		}
+	}
+
+	return routes.NewServerHandler(svc, collector, routes.ServerOptions{
+		Weights:             viper.GetIntSlice("short_url.weights"),
+		IPSalt:              ipSalt,
+		DefaultRedirectCode: cfg.DefaultCode,
+		NotYetAvailablePage: cfg.NotYetAvailablePage,
+		GeoIP:               geo,
+		VariantTTL:          time.Duration(variantCfg.CookieTTL) * time.Second,
+
+		PasswordLimiter: limiter,
+		CookieSecret:    pwdCfg.CookieSecret,
+		PasswordTTL:     time.Duration(pwdCfg.CookieTTL) * time.Second,
+		PasswordPage:    pwdCfg.Page,
+	})
 }
@@ -0,0 +1,90 @@
+package ioc
+
+import (
+	"context"
+	"fmt"
+	short_url_v1 "short_url/proto/short_url/v1"
+	"short_url/web/middlewares"
+	"short_url/web/pkg"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"github.com/to404hanga/pkg404/logger"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// InitSignatureMiddleware 初始化 /api 接口的签名认证中间件，配置中显式关闭时返回 nil
+func InitSignatureMiddleware(client short_url_v1.ShortUrlServiceClient, cmd redis.Cmdable, l logger.Logger) *middlewares.SignatureMiddlewareBuilder {
+	type Config struct {
+		Enabled     bool     `yaml:"enabled"`
+		Skew        int      `yaml:"skew"`
+		MaxBodySize int64    `yaml:"maxBodySize"`
+		NoncePrefix string   `yaml:"noncePrefix"`
+		SkipPaths   []string `yaml:"skipPaths"`
+	}
+	cfg := Config{
+		// 默认开启，修改、删除等接口不能无认证暴露；自带的 index.html 不签名，需在配置中通过 skipPaths 放行
+		Enabled:     true,
+		Skew:        300, // 单位 秒
+		MaxBodySize: 1 << 20,
+		NoncePrefix: "api_nonce",
+		SkipPaths:   []string{"/api/health"},
+	}
+	if err := viper.UnmarshalKey("signature", &cfg); err != nil {
+		panic(err)
+	}
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Skew <= 0 || cfg.MaxBodySize <= 0 {
+		panic("signature.skew and maxBodySize must be positive")
+	}
+
+	return middlewares.NewSignatureMiddlewareBuilder(
+		&grpcSignatureVerifier{client: client},
+		&redisNonceStore{cmd: cmd, prefix: cfg.NoncePrefix},
+		l,
+	).
+		SkipPaths(cfg.SkipPaths...).
+		Skew(time.Duration(cfg.Skew) * time.Second).
+		MaxBodySize(cfg.MaxBodySize)
+}
+
+// grpcSignatureVerifier 由 rpc 层计算并比较签名，密钥不经过 web 层，rpc 层负责缓存
+type grpcSignatureVerifier struct {
+	client short_url_v1.ShortUrlServiceClient
+}
+
+var _ middlewares.SignatureVerifier = (*grpcSignatureVerifier)(nil)
+
+func (v *grpcSignatureVerifier) Verify(ctx context.Context, keyID string, params map[string]string, signature string) error {
+	_, err := v.client.VerifySignature(ctx, &short_url_v1.VerifySignatureRequest{
+		KeyId:     keyID,
+		Params:    params,
+		Signature: signature,
+	})
+	switch status.Code(err) {
+	case codes.OK:
+		return nil
+	case codes.NotFound:
+		return middlewares.ErrApiKeyNotFound
+	case codes.Unauthenticated:
+		return middlewares.ErrInvalidSignature
+	default:
+		return err
+	}
+}
+
+// redisNonceStore 使用 SET NX 记录 nonce，多个 web 实例共享
+type redisNonceStore struct {
+	cmd    redis.Cmdable
+	prefix string
+}
+
+var _ pkg.NonceStore = (*redisNonceStore)(nil)
+
+func (s *redisNonceStore) Use(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	return s.cmd.SetNX(ctx, fmt.Sprintf("%s:%s", s.prefix, nonce), 1, ttl).Result()
+}
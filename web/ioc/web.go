@@ -18,6 +18,16 @@ func InitWebServer(mdls []gin.HandlerFunc, api *routes.ApiHandler, server *route
 	router := gin.Default()
 	router.MaxMultipartMemory = 1024 * 1024 * 1024 * 2
 
+	// 只信任来自可信代理的 X-Real-IP / X-Forwarded-For，避免客户端伪造 IP 绕过限流与按国家跳转
+	router.RemoteIPHeaders = []string{"X-Real-IP", "X-Forwarded-For"}
+	trustedProxies := viper.GetStringSlice("app.trustedProxies")
+	if len(trustedProxies) == 0 {
+		trustedProxies = []string{"127.0.0.1", "::1", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"}
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		panic(err)
+	}
+
 	router.Use(mdls...)
 
 	// 获取项目根目录
@@ -51,11 +61,13 @@ func timeout() gin.HandlerFunc {
 	}
 }
 
-func InitGinMiddleware(l logger.Logger, limiter pkg.RateLimiter) []gin.HandlerFunc {
+func InitGinMiddleware(l logger.Logger, limiter pkg.RateLimiter, signature *middlewares.SignatureMiddlewareBuilder, policy *middlewares.PolicyRateLimiter) []gin.HandlerFunc {
 	hf := []gin.HandlerFunc{
 		cors.New(cors.Config{
 			AllowCredentials: true,
-			AllowHeaders:     []string{"Content-Type"},
+			AllowHeaders:     []string{"Content-Type", middlewares.HeaderApiKey, middlewares.HeaderTimestamp, middlewares.HeaderNonce, middlewares.HeaderSignature},
+			// 允许前端读取限流响应头，据此退避重试
+			ExposeHeaders: []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After"},
 			AllowOriginFunc: func(origin string) bool {
 				if strings.HasPrefix(origin, "http://localhost") || strings.HasPrefix(origin, "127.0.0.1") {
 					return true
@@ -74,6 +86,12 @@ func InitGinMiddleware(l logger.Logger, limiter pkg.RateLimiter) []gin.HandlerFu
 		}),
 		// middlewares.ZapLogger(l),
 	}
+	// 限流之后再校验签名，被限流的请求不会查询密钥与占用 nonce
+	if signature != nil {
+		hf = append(hf, signature.Build())
+	}
+	// 按规则限流放在签名校验之后，按 API Key 计数时只使用校验通过的 Key，避免伪造他人的 Key 耗尽其配额
+	hf = append(hf, policy.Build())
 
 	if viper.GetString("log.mode") == "dev" {
 		hf = append(hf, middlewares.ZapLogger(l))
@@ -1,16 +1,38 @@
 package main
 
 import (
+	"context"
+	"log"
+	"net/http"
+	"path/filepath"
+
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
-	"path/filepath"
 )
 
 func main() {
 	initViperWatch()
 	app := Init()
-	if err := app.Run(viper.GetString("app.addr")); err != nil {
-		panic(err)
+
+	server := &http.Server{
+		Addr:    viper.GetString("app.addr"),
+		Handler: app.Engine,
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errCh <- err
+		}
+	}()
+
+	// 先停止接收新请求并等待进行中的请求完成，再上报剩余的点击事件，最后关闭 etcd 客户端
+	app.Lifecycle.Register("http", server.Shutdown)
+	app.Lifecycle.Register("analytics", app.Collector.Close)
+	app.Lifecycle.Register("etcd", func(ctx context.Context) error {
+		return app.EtcdClient.Close()
+	})
+	if err := app.Lifecycle.Wait(errCh); err != nil {
+		log.Printf("shutdown finished with error: %v", err)
 	}
 }
 
@@ -0,0 +1,221 @@
+package middlewares
+
+import (
+	"fmt"
+	"net"
+	"short_url/web/pkg"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 限流规则的计数维度
+const (
+	LimitByGlobal = "global"  // 命中规则的请求共用一个令牌桶
+	LimitByIP     = "ip"      // 按客户端 IP
+	LimitByApiKey = "api_key" // 按签名校验通过的 API Key，没有 API Key 时按客户端 IP
+	LimitByRoute  = "route"   // 按路由模板与请求方法
+)
+
+// RateLimitRule 一条限流规则，匹配条件中为空的字段表示不限制该条件
+type RateLimitRule struct {
+	Name string
+	// Methods 请求方法，如 POST
+	Methods []string
+	// Routes 路由模板，如 /api/links/:short_url，以 * 结尾时按前缀匹配
+	Routes []string
+	// ApiKeys 签名校验通过的 API Key
+	ApiKeys []string
+	// IPs 客户端 IP 或 CIDR
+	IPs []string
+	// By 计数维度，多个维度组合成一个令牌桶的 key，为空时按客户端 IP
+	By []string
+	// Limiter 规则独立的令牌桶，决定该规则的速率与容量
+	Limiter pkg.RateLimiter
+
+	nets    []*net.IPNet
+	handler gin.HandlerFunc
+}
+
+// RateLimitPolicy 按顺序匹配的限流规则，第一条命中的规则生效，没有命中时不限流
+type RateLimitPolicy struct {
+	rules []RateLimitRule
+}
+
+// NewRateLimitPolicy 校验规则并为每条规则创建限流中间件
+func NewRateLimitPolicy(rules []RateLimitRule) (*RateLimitPolicy, error) {
+	names := make(map[string]struct{}, len(rules))
+	compiled := make([]RateLimitRule, 0, len(rules))
+	for _, r := range rules {
+		if r.Name == "" {
+			return nil, fmt.Errorf("rate limit rule name is required")
+		}
+		if _, ok := names[r.Name]; ok {
+			return nil, fmt.Errorf("duplicate rate limit rule %q", r.Name)
+		}
+		names[r.Name] = struct{}{}
+		if r.Limiter == nil {
+			return nil, fmt.Errorf("rate limit rule %q has no limiter", r.Name)
+		}
+		if len(r.By) == 0 {
+			r.By = []string{LimitByIP}
+		}
+		for _, by := range r.By {
+			switch by {
+			case LimitByGlobal, LimitByIP, LimitByApiKey, LimitByRoute:
+			default:
+				return nil, fmt.Errorf("rate limit rule %q: unknown dimension %q", r.Name, by)
+			}
+		}
+		methods := make([]string, 0, len(r.Methods))
+		for _, m := range r.Methods {
+			methods = append(methods, strings.ToUpper(m))
+		}
+		r.Methods = methods
+		for _, ip := range r.IPs {
+			n, err := parseIPOrCIDR(ip)
+			if err != nil {
+				return nil, fmt.Errorf("rate limit rule %q: %w", r.Name, err)
+			}
+			r.nets = append(r.nets, n)
+		}
+
+		rule := r
+		rule.handler = NewRateLimiter(r.Limiter, RateLimitConfig{
+			KeyGenerator: rule.key,
+			// 是否限流已由规则的匹配条件决定
+			Skipper: func(c *gin.Context) bool { return false },
+		})
+		compiled = append(compiled, rule)
+	}
+	return &RateLimitPolicy{rules: compiled}, nil
+}
+
+// match 返回第一条命中的规则
+func (p *RateLimitPolicy) match(c *gin.Context) *RateLimitRule {
+	for i := range p.rules {
+		if p.rules[i].matches(c) {
+			return &p.rules[i]
+		}
+	}
+	return nil
+}
+
+func (r *RateLimitRule) matches(c *gin.Context) bool {
+	if len(r.Methods) > 0 && !contains(r.Methods, c.Request.Method) {
+		return false
+	}
+	if len(r.Routes) > 0 && !matchRoute(r.Routes, c.FullPath()) {
+		return false
+	}
+	if len(r.ApiKeys) > 0 && !contains(r.ApiKeys, c.GetString(ApiKeyContextKey)) {
+		return false
+	}
+	if len(r.nets) > 0 {
+		ip := net.ParseIP(c.ClientIP())
+		if ip == nil {
+			return false
+		}
+		for _, n := range r.nets {
+			if n.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// key 按规则的计数维度生成令牌桶的 key，规则名作为前缀，不同规则互不影响
+func (r *RateLimitRule) key(c *gin.Context) string {
+	parts := make([]string, 0, len(r.By)+1)
+	parts = append(parts, "policy", r.Name)
+	for _, by := range r.By {
+		switch by {
+		case LimitByGlobal:
+			parts = append(parts, "global")
+		case LimitByIP:
+			parts = append(parts, "ip", c.ClientIP())
+		case LimitByApiKey:
+			if k := c.GetString(ApiKeyContextKey); k != "" {
+				parts = append(parts, "key", k)
+			} else {
+				parts = append(parts, "ip", c.ClientIP())
+			}
+		case LimitByRoute:
+			// 未匹配到路由时 FullPath 为空，统一按请求方法计数，避免随机路径产生大量 key
+			parts = append(parts, "route", c.Request.Method, c.FullPath())
+		}
+	}
+	return strings.Join(parts, ":")
+}
+
+// PolicyRateLimiter 按 RateLimitPolicy 限流，规则可在运行时整体替换
+type PolicyRateLimiter struct {
+	policy atomic.Pointer[RateLimitPolicy]
+}
+
+func NewPolicyRateLimiter(policy *RateLimitPolicy) *PolicyRateLimiter {
+	l := &PolicyRateLimiter{}
+	l.policy.Store(policy)
+	return l
+}
+
+// Update 替换限流规则，正在处理的请求仍使用旧规则
+func (l *PolicyRateLimiter) Update(policy *RateLimitPolicy) {
+	l.policy.Store(policy)
+}
+
+func (l *PolicyRateLimiter) Build() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == "OPTIONS" {
+			c.Next()
+			return
+		}
+		rule := l.policy.Load().match(c)
+		if rule == nil {
+			c.Next()
+			return
+		}
+		rule.handler(c)
+	}
+}
+
+func parseIPOrCIDR(s string) (*net.IPNet, error) {
+	if strings.Contains(s, "/") {
+		_, n, err := net.ParseCIDR(s)
+		return n, err
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid ip %q", s)
+	}
+	bits := 128
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits = ip4, 32
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+func matchRoute(routes []string, fullPath string) bool {
+	for _, r := range routes {
+		if prefix, ok := strings.CutSuffix(r, "*"); ok {
+			if fullPath != "" && strings.HasPrefix(fullPath, prefix) {
+				return true
+			}
+		} else if r == fullPath {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"short_url/web/pkg"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRateLimiter 记录请求的 key，每个 key 最多允许 capacity 次
+type fakeRateLimiter struct {
+	capacity int
+	counts   map[string]int
+	keys     []string
+}
+
+func (f *fakeRateLimiter) Allow(_ context.Context, key string, _ int64) (pkg.RateLimitResult, error) {
+	f.keys = append(f.keys, key)
+	f.counts[key]++
+	return pkg.RateLimitResult{
+		Allowed:   f.counts[key] <= f.capacity,
+		Limit:     int64(f.capacity),
+		Remaining: int64(max(f.capacity-f.counts[key], 0)),
+	}, nil
+}
+
+func (f *fakeRateLimiter) GetStats() map[string]interface{} {
+	return nil
+}
+
+func newFakeRateLimiter(capacity int) *fakeRateLimiter {
+	return &fakeRateLimiter{capacity: capacity, counts: map[string]int{}}
+}
+
+func TestPolicyRateLimiter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	internal, create, api := newFakeRateLimiter(100), newFakeRateLimiter(1), newFakeRateLimiter(100)
+	policy, err := NewRateLimitPolicy([]RateLimitRule{
+		{Name: "internal", IPs: []string{"10.0.0.0/8"}, By: []string{LimitByGlobal}, Limiter: internal},
+		{Name: "create", Methods: []string{"post"}, Routes: []string{"/api/create"}, By: []string{LimitByApiKey}, Limiter: create},
+		{Name: "api", Routes: []string{"/api/*"}, By: []string{LimitByApiKey, LimitByRoute}, Limiter: api},
+	})
+	require.NoError(t, err)
+	limiter := NewPolicyRateLimiter(policy)
+
+	server := gin.New()
+	server.Use(func(c *gin.Context) {
+		// 模拟签名中间件写入校验通过的 API Key
+		if k := c.GetHeader(HeaderApiKey); k != "" {
+			c.Set(ApiKeyContextKey, k)
+		}
+	}, limiter.Build())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	server.POST("/api/create", ok)
+	server.GET("/api/links/:short_url", ok)
+	server.GET("/:short_url", ok)
+
+	testCases := []struct {
+		name     string
+		method   string
+		path     string
+		ip       string
+		apiKey   string
+		wantCode int
+		wantKey  string
+		limiter  *fakeRateLimiter
+	}{
+		{name: "内网 IP 优先命中第一条规则", method: http.MethodPost, path: "/api/create", ip: "10.1.2.3", apiKey: "ak_a", wantCode: http.StatusOK, wantKey: "policy:internal:global", limiter: internal},
+		{name: "按 API Key 计数", method: http.MethodPost, path: "/api/create", ip: "1.1.1.1", apiKey: "ak_a", wantCode: http.StatusOK, wantKey: "policy:create:key:ak_a", limiter: create},
+		{name: "同一 API Key 超出容量", method: http.MethodPost, path: "/api/create", ip: "2.2.2.2", apiKey: "ak_a", wantCode: http.StatusTooManyRequests, wantKey: "policy:create:key:ak_a", limiter: create},
+		{name: "不同 API Key 互不影响", method: http.MethodPost, path: "/api/create", ip: "2.2.2.2", apiKey: "ak_b", wantCode: http.StatusOK, wantKey: "policy:create:key:ak_b", limiter: create},
+		{name: "没有 API Key 时按 IP 计数", method: http.MethodPost, path: "/api/create", ip: "3.3.3.3", wantCode: http.StatusOK, wantKey: "policy:create:ip:3.3.3.3", limiter: create},
+		{name: "按前缀匹配路由", method: http.MethodGet, path: "/api/links/abc", ip: "1.1.1.1", apiKey: "ak_a", wantCode: http.StatusOK, wantKey: "policy:api:key:ak_a:route:GET:/api/links/:short_url", limiter: api},
+		{name: "没有命中规则", method: http.MethodGet, path: "/abc", ip: "1.1.1.1", wantCode: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			req.RemoteAddr = tc.ip + ":12345"
+			if tc.apiKey != "" {
+				req.Header.Set(HeaderApiKey, tc.apiKey)
+			}
+			w := httptest.NewRecorder()
+			total := len(internal.keys) + len(create.keys) + len(api.keys)
+			server.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantCode, w.Code)
+			if tc.limiter == nil {
+				assert.Equal(t, total, len(internal.keys)+len(create.keys)+len(api.keys))
+				return
+			}
+			assert.Equal(t, tc.wantKey, tc.limiter.keys[len(tc.limiter.keys)-1])
+		})
+	}
+
+	// 替换规则后立即生效
+	policy, err = NewRateLimitPolicy(nil)
+	require.NoError(t, err)
+	limiter.Update(policy)
+	req := httptest.NewRequest(http.MethodPost, "/api/create", nil)
+	req.Header.Set(HeaderApiKey, "ak_a")
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestNewRateLimitPolicy_Invalid(t *testing.T) {
+	limiter := newFakeRateLimiter(1)
+	testCases := []struct {
+		name  string
+		rules []RateLimitRule
+	}{
+		{name: "缺少规则名", rules: []RateLimitRule{{Limiter: limiter}}},
+		{name: "规则名重复", rules: []RateLimitRule{{Name: "a", Limiter: limiter}, {Name: "a", Limiter: limiter}}},
+		{name: "缺少令牌桶", rules: []RateLimitRule{{Name: "a"}}},
+		{name: "未知的计数维度", rules: []RateLimitRule{{Name: "a", By: []string{"user"}, Limiter: limiter}}},
+		{name: "IP 格式错误", rules: []RateLimitRule{{Name: "a", IPs: []string{"1.2.3"}, Limiter: limiter}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewRateLimitPolicy(tc.rules)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -1,9 +1,12 @@
 package middlewares
 
 import (
-	"github.com/gin-gonic/gin"
 	"net/http"
 	"short_url/web/pkg"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
 )
 
 // RateLimitConfig 限流中间件配置
@@ -49,14 +52,19 @@ func NewRateLimiter(limiter pkg.RateLimiter, config RateLimitConfig) gin.Handler
 		}
 
 		// 检查限流
-		allowed, err := limiter.Allow(c.Request.Context(), key, config.Limit)
+		res, err := limiter.Allow(c.Request.Context(), key, config.Limit)
 		if err != nil {
 			config.ErrorHandler(c, err)
 			return
 		}
 
-		if !allowed {
-			c.Header("X-RateLimit-Remaining", "0")
+		setRateLimitHeaders(c, res)
+		if !res.Allowed {
+			c.Header("X-RateLimit-Remaining", "0") // 兼容旧客户端
+			// 无法给出等待时间时不设置 Retry-After，避免客户端立即重试
+			if res.RetryAfter > 0 {
+				c.Header("Retry-After", strconv.FormatInt(ceilSeconds(res.RetryAfter), 10))
+			}
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error": "请求过于频繁，请稍后再试",
 				"code":  429,
@@ -69,6 +77,27 @@ func NewRateLimiter(limiter pkg.RateLimiter, config RateLimitConfig) gin.Handler
 	}
 }
 
+// setRateLimitHeaders 设置 RateLimit-Limit / RateLimit-Remaining / RateLimit-Reset 响应头，
+// Reset 为距下一个令牌生成的秒数。一个请求经过多个限流器时，保留剩余令牌最少的那一个
+func setRateLimitHeaders(c *gin.Context, res pkg.RateLimitResult) {
+	if prev := c.Writer.Header().Get("RateLimit-Remaining"); prev != "" {
+		if n, err := strconv.ParseInt(prev, 10, 64); err == nil && n <= res.Remaining {
+			return
+		}
+	}
+	c.Header("RateLimit-Limit", strconv.FormatInt(res.Limit, 10))
+	c.Header("RateLimit-Remaining", strconv.FormatInt(res.Remaining, 10))
+	c.Header("RateLimit-Reset", strconv.FormatInt(ceilSeconds(res.ResetAfter), 10))
+}
+
+// ceilSeconds 向上取整到秒，不足一秒的等待也至少返回 1
+func ceilSeconds(d time.Duration) int64 {
+	if d <= 0 {
+		return 0
+	}
+	return int64((d + time.Second - 1) / time.Second)
+}
+
 // defaultKeyGenerator 默认key生成器（全局限流）
 func defaultKeyGenerator(c *gin.Context) string {
 	// 返回固定key，实现全局限流
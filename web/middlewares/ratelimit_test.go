@@ -0,0 +1,87 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"short_url/web/pkg"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubRateLimiter 始终返回固定的限流结果
+type stubRateLimiter pkg.RateLimitResult
+
+func (s stubRateLimiter) Allow(context.Context, string, int64) (pkg.RateLimitResult, error) {
+	return pkg.RateLimitResult(s), nil
+}
+
+func (s stubRateLimiter) GetStats() map[string]interface{} {
+	return nil
+}
+
+func TestNewRateLimiter_Headers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testCases := []struct {
+		name     string
+		limiters []stubRateLimiter
+		wantCode int
+		want     map[string]string
+	}{
+		{
+			name:     "允许请求",
+			limiters: []stubRateLimiter{{Allowed: true, Limit: 100, Remaining: 99, ResetAfter: 100 * time.Millisecond}},
+			wantCode: http.StatusOK,
+			want:     map[string]string{"RateLimit-Limit": "100", "RateLimit-Remaining": "99", "RateLimit-Reset": "1", "Retry-After": ""},
+		},
+		{
+			name:     "桶已满",
+			limiters: []stubRateLimiter{{Allowed: true, Limit: 100, Remaining: 100}},
+			wantCode: http.StatusOK,
+			want:     map[string]string{"RateLimit-Limit": "100", "RateLimit-Remaining": "100", "RateLimit-Reset": "0"},
+		},
+		{
+			name:     "拒绝请求",
+			limiters: []stubRateLimiter{{Limit: 5, ResetAfter: 1500 * time.Millisecond, RetryAfter: 2500 * time.Millisecond}},
+			wantCode: http.StatusTooManyRequests,
+			want:     map[string]string{"RateLimit-Limit": "5", "RateLimit-Remaining": "0", "RateLimit-Reset": "2", "Retry-After": "3"},
+		},
+		{
+			name:     "拒绝请求但无法给出等待时间",
+			limiters: []stubRateLimiter{{Limit: 5}},
+			wantCode: http.StatusTooManyRequests,
+			want:     map[string]string{"RateLimit-Remaining": "0", "Retry-After": ""},
+		},
+		{
+			name: "多个限流器时保留剩余最少的",
+			limiters: []stubRateLimiter{
+				{Allowed: true, Limit: 10000, Remaining: 9000, ResetAfter: time.Millisecond},
+				{Allowed: true, Limit: 50, Remaining: 3, ResetAfter: 10 * time.Second},
+				{Allowed: true, Limit: 200, Remaining: 150, ResetAfter: time.Second},
+			},
+			wantCode: http.StatusOK,
+			want:     map[string]string{"RateLimit-Limit": "50", "RateLimit-Remaining": "3", "RateLimit-Reset": "10"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := gin.New()
+			for _, l := range tc.limiters {
+				server.Use(NewRateLimiter(l, RateLimitConfig{Skipper: func(*gin.Context) bool { return false }}))
+			}
+			server.GET("/api/links/:short_url", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/links/abc", nil))
+
+			assert.Equal(t, tc.wantCode, w.Code)
+			for k, v := range tc.want {
+				assert.Equal(t, v, w.Header().Get(k), k)
+			}
+		})
+	}
+}
@@ -1,5 +1,213 @@
 package middlewares
 
-// TODO api 接口签名认证中间件
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"short_url/web/pkg"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/to404hanga/pkg404/logger"
+)
+
+// 签名相关的请求头
+const (
+	HeaderApiKey    = "X-Api-Key"
+	HeaderTimestamp = "X-Timestamp"
+	HeaderNonce     = "X-Nonce"
+	HeaderSignature = "X-Signature"
+)
+
+// ApiKeyContextKey 签名校验通过后，调用方的 API Key 保存在 gin.Context 中的键
+const ApiKeyContextKey = "api_key"
+
+var (
+	ErrApiKeyNotFound   = errors.New("api key not found")
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// SignatureVerifier 使用 API Key 对应的密钥校验签名，密钥不经过 web 层。
+// Key 不存在或已禁用时返回 ErrApiKeyNotFound，签名不一致时返回 ErrInvalidSignature
+type SignatureVerifier interface {
+	Verify(ctx context.Context, keyID string, params map[string]string, signature string) error
+}
+
+// SignatureMiddlewareBuilder api 接口签名认证中间件。
+// 调用方对以下字段按 key 排序后计算 HMAC-SHA256 签名，放在 X-Signature 请求头中：
+//
+//	api_key      X-Api-Key 请求头
+//	body_sha256  请求体 SHA-256 的小写十六进制，没有请求体时为空串的哈希
+//	method       大写的请求方法
+//	nonce        X-Nonce 请求头，8~64 位字母、数字、'-' 或 '_'，在有效期内不可重复
+//	path         请求路径，不含查询参数
+//	query        按 key 排序并 URL 编码的查询参数，没有时为空
+//	timestamp    X-Timestamp 请求头，unix 时间戳（秒），与服务器时间相差不超过 skew
 type SignatureMiddlewareBuilder struct {
+	verifier    SignatureVerifier
+	nonces      pkg.NonceStore
+	l           logger.Logger
+	prefix      string
+	skipPaths   map[string]struct{}
+	skew        time.Duration
+	maxBodySize int64
+	now         func() time.Time
+}
+
+func NewSignatureMiddlewareBuilder(verifier SignatureVerifier, nonces pkg.NonceStore, l logger.Logger) *SignatureMiddlewareBuilder {
+	return &SignatureMiddlewareBuilder{
+		verifier:    verifier,
+		nonces:      nonces,
+		l:           l,
+		prefix:      "/api/",
+		skipPaths:   map[string]struct{}{},
+		skew:        5 * time.Minute,
+		maxBodySize: 1 << 20,
+		now:         time.Now,
+	}
+}
+
+// SkipPaths 不校验签名的路径，如健康检查
+func (b *SignatureMiddlewareBuilder) SkipPaths(paths ...string) *SignatureMiddlewareBuilder {
+	for _, p := range paths {
+		b.skipPaths[p] = struct{}{}
+	}
+	return b
+}
+
+// Skew 允许的客户端与服务器时间偏差，nonce 的保留时长为其两倍
+func (b *SignatureMiddlewareBuilder) Skew(skew time.Duration) *SignatureMiddlewareBuilder {
+	b.skew = skew
+	return b
+}
+
+// MaxBodySize 参与签名的请求体的最大字节数
+func (b *SignatureMiddlewareBuilder) MaxBodySize(n int64) *SignatureMiddlewareBuilder {
+	b.maxBodySize = n
+	return b
+}
+
+func (b *SignatureMiddlewareBuilder) Build() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		path := ctx.Request.URL.Path
+		if !strings.HasPrefix(path, b.prefix) || ctx.Request.Method == http.MethodOptions {
+			ctx.Next()
+			return
+		}
+		if _, ok := b.skipPaths[path]; ok {
+			ctx.Next()
+			return
+		}
+
+		keyID := ctx.GetHeader(HeaderApiKey)
+		ts := ctx.GetHeader(HeaderTimestamp)
+		nonce := ctx.GetHeader(HeaderNonce)
+		signature := ctx.GetHeader(HeaderSignature)
+		if keyID == "" || ts == "" || nonce == "" || signature == "" {
+			abortUnauthorized(ctx, "缺少签名请求头", "MISSING_SIGNATURE")
+			return
+		}
+
+		sec, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			abortUnauthorized(ctx, "时间戳格式错误", "INVALID_TIMESTAMP")
+			return
+		}
+		if d := b.now().Sub(time.Unix(sec, 0)); d > b.skew || d < -b.skew {
+			abortUnauthorized(ctx, "请求已过期，请校准客户端时间", "TIMESTAMP_EXPIRED")
+			return
+		}
+		if !isValidNonce(nonce) {
+			abortUnauthorized(ctx, "随机串格式错误", "INVALID_NONCE")
+			return
+		}
+
+		body, err := b.readBody(ctx)
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "请求体过大", "code": "BODY_TOO_LARGE"})
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "读取请求体失败", "code": "INVALID_BODY"})
+			return
+		}
+
+		bodyHash := sha256.Sum256(body)
+		err = b.verifier.Verify(ctx.Request.Context(), keyID, map[string]string{
+			"api_key":     keyID,
+			"body_sha256": hex.EncodeToString(bodyHash[:]),
+			"method":      ctx.Request.Method,
+			"nonce":       nonce,
+			"path":        path,
+			"query":       ctx.Request.URL.Query().Encode(),
+			"timestamp":   ts,
+		}, strings.ToLower(signature))
+		switch {
+		case errors.Is(err, ErrApiKeyNotFound):
+			abortUnauthorized(ctx, "API Key 不存在或已禁用", "INVALID_API_KEY")
+			return
+		case errors.Is(err, ErrInvalidSignature):
+			abortUnauthorized(ctx, "签名错误", "INVALID_SIGNATURE")
+			return
+		case err != nil:
+			b.l.Error("failed to verify signature", logger.Error(err), logger.String("key_id", keyID))
+			abortUnavailable(ctx)
+			return
+		}
+
+		// 签名通过后才记录 nonce，避免伪造请求占用调用方的 nonce
+		ok, err := b.nonces.Use(ctx.Request.Context(), keyID+":"+nonce, 2*b.skew)
+		if err != nil {
+			b.l.Error("failed to record nonce", logger.Error(err), logger.String("key_id", keyID))
+			abortUnavailable(ctx)
+			return
+		}
+		if !ok {
+			abortUnauthorized(ctx, "请求重复提交", "NONCE_REUSED")
+			return
+		}
+
+		ctx.Set(ApiKeyContextKey, keyID)
+		ctx.Next()
+	}
+}
+
+// readBody 读取请求体用于计算哈希，并重新放回请求中供后续处理函数使用
+func (b *SignatureMiddlewareBuilder) readBody(ctx *gin.Context) ([]byte, error) {
+	if ctx.Request.Body == nil || ctx.Request.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, b.maxBodySize))
+	if err != nil {
+		return nil, err
+	}
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func isValidNonce(nonce string) bool {
+	if len(nonce) < 8 || len(nonce) > 64 {
+		return false
+	}
+	for _, c := range nonce {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+func abortUnauthorized(ctx *gin.Context, msg, code string) {
+	ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": msg, "code": code})
+}
+
+func abortUnavailable(ctx *gin.Context) {
+	ctx.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "签名校验服务暂不可用", "code": "SIGNATURE_UNAVAILABLE"})
 }
@@ -0,0 +1,246 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"short_url/pkg/sign/hmacsha256"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/to404hanga/pkg404/logger"
+)
+
+// fakeVerifier 模拟 rpc 层，按 keyID 对应的密钥计算签名
+type fakeVerifier map[string]string
+
+func (f fakeVerifier) Verify(_ context.Context, keyID string, params map[string]string, signature string) error {
+	if keyID == "broken" {
+		return errors.New("rpc unavailable")
+	}
+	secret, ok := f[keyID]
+	if !ok {
+		return ErrApiKeyNotFound
+	}
+	data := make(map[string]any, len(params))
+	for k, v := range params {
+		data[k] = v
+	}
+	expected, err := hmacsha256.NewHmacSha256SignHandler().GenerateSign(data, secret)
+	if err != nil || expected != signature {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+type fakeNonceStore map[string]struct{}
+
+func (f fakeNonceStore) Use(_ context.Context, nonce string, _ time.Duration) (bool, error) {
+	if _, ok := f[nonce]; ok {
+		return false, nil
+	}
+	f[nonce] = struct{}{}
+	return true, nil
+}
+
+func TestSignatureMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Unix(1700000000, 0)
+	signer := hmacsha256.NewHmacSha256SignHandler()
+
+	type request struct {
+		method, target, body        string
+		keyID, ts, nonce, signature string
+	}
+	// signed 返回以 secret 正确签名的请求
+	signed := func(method, target, body, nonce string, ts time.Time) request {
+		req := httptest.NewRequest(method, target, nil)
+		hash := sha256.Sum256([]byte(body))
+		tsStr := strconv.FormatInt(ts.Unix(), 10)
+		sig, err := signer.GenerateSign(map[string]any{
+			"api_key":     "ak_test",
+			"body_sha256": hex.EncodeToString(hash[:]),
+			"method":      method,
+			"nonce":       nonce,
+			"path":        req.URL.Path,
+			"query":       req.URL.Query().Encode(),
+			"timestamp":   tsStr,
+		}, "secret")
+		assert.NoError(t, err)
+		return request{method: method, target: target, body: body, keyID: "ak_test", ts: tsStr, nonce: nonce, signature: sig}
+	}
+
+	testCases := []struct {
+		name     string
+		req      func() request
+		wantCode int
+		wantErr  string
+	}{
+		{
+			name: "签名正确",
+			req: func() request {
+				return signed(http.MethodPost, "/api/create?b=2&a=1", `{"origin_url":"https://example.com"}`, "nonce-0001", now)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "签名使用大写十六进制",
+			req: func() request {
+				r := signed(http.MethodGet, "/api/links/abc", "", "nonce-0002", now)
+				r.signature = strings.ToUpper(r.signature)
+				return r
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "跳过的路径",
+			req:      func() request { return request{method: http.MethodGet, target: "/api/health"} },
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "非 api 路径",
+			req:      func() request { return request{method: http.MethodGet, target: "/abc"} },
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "缺少签名请求头",
+			req:      func() request { return request{method: http.MethodGet, target: "/api/links/abc"} },
+			wantCode: http.StatusUnauthorized,
+			wantErr:  "MISSING_SIGNATURE",
+		},
+		{
+			name: "时间戳格式错误",
+			req: func() request {
+				r := signed(http.MethodGet, "/api/links/abc", "", "nonce-0003", now)
+				r.ts = "abc"
+				return r
+			},
+			wantCode: http.StatusUnauthorized,
+			wantErr:  "INVALID_TIMESTAMP",
+		},
+		{
+			name: "时间戳超出允许偏差",
+			req: func() request {
+				return signed(http.MethodGet, "/api/links/abc", "", "nonce-0004", now.Add(-6*time.Minute))
+			},
+			wantCode: http.StatusUnauthorized,
+			wantErr:  "TIMESTAMP_EXPIRED",
+		},
+		{
+			name: "时间戳在允许偏差内",
+			req: func() request {
+				return signed(http.MethodGet, "/api/links/abc", "", "nonce-0005", now.Add(4*time.Minute))
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "随机串过短",
+			req:      func() request { return signed(http.MethodGet, "/api/links/abc", "", "short", now) },
+			wantCode: http.StatusUnauthorized,
+			wantErr:  "INVALID_NONCE",
+		},
+		{
+			name: "API Key 不存在",
+			req: func() request {
+				r := signed(http.MethodGet, "/api/links/abc", "", "nonce-0006", now)
+				r.keyID = "ak_unknown"
+				return r
+			},
+			wantCode: http.StatusUnauthorized,
+			wantErr:  "INVALID_API_KEY",
+		},
+		{
+			name: "校验签名失败",
+			req: func() request {
+				r := signed(http.MethodGet, "/api/links/abc", "", "nonce-0007", now)
+				r.keyID = "broken"
+				return r
+			},
+			wantCode: http.StatusServiceUnavailable,
+			wantErr:  "SIGNATURE_UNAVAILABLE",
+		},
+		{
+			name: "请求体被篡改",
+			req: func() request {
+				r := signed(http.MethodPost, "/api/create", `{"origin_url":"https://example.com"}`, "nonce-0008", now)
+				r.body = `{"origin_url":"https://evil.com"}`
+				return r
+			},
+			wantCode: http.StatusUnauthorized,
+			wantErr:  "INVALID_SIGNATURE",
+		},
+		{
+			name: "查询参数被篡改",
+			req: func() request {
+				r := signed(http.MethodGet, "/api/links/abc?a=1", "", "nonce-0009", now)
+				r.target = "/api/links/abc?a=2"
+				return r
+			},
+			wantCode: http.StatusUnauthorized,
+			wantErr:  "INVALID_SIGNATURE",
+		},
+		{
+			name: "重放请求",
+			req: func() request {
+				return signed(http.MethodPost, "/api/create?b=2&a=1", `{"origin_url":"https://example.com"}`, "nonce-0001", now)
+			},
+			wantCode: http.StatusUnauthorized,
+			wantErr:  "NONCE_REUSED",
+		},
+		{
+			name: "请求体过大",
+			req: func() request {
+				return signed(http.MethodPost, "/api/create", strings.Repeat("a", 2048), "nonce-0010", now)
+			},
+			wantCode: http.StatusRequestEntityTooLarge,
+			wantErr:  "BODY_TOO_LARGE",
+		},
+	}
+
+	// 各用例共用同一个 nonce 存储，用于验证重放
+	b := NewSignatureMiddlewareBuilder(fakeVerifier{"ak_test": "secret"}, fakeNonceStore{}, logger.NewNopLogger()).
+		SkipPaths("/api/health").
+		MaxBodySize(1024)
+	b.now = func() time.Time { return now }
+
+	server := gin.New()
+	server.Use(b.Build())
+	// 处理函数回显请求体，验证中间件读取后已放回
+	echo := func(ctx *gin.Context) {
+		body, _ := io.ReadAll(ctx.Request.Body)
+		ctx.String(http.StatusOK, string(body))
+	}
+	server.POST("/api/create", echo)
+	server.GET("/api/links/:short_url", echo)
+	server.GET("/api/health", echo)
+	server.GET("/:short_url", echo)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := tc.req()
+			req := httptest.NewRequest(r.method, r.target, strings.NewReader(r.body))
+			for k, v := range map[string]string{HeaderApiKey: r.keyID, HeaderTimestamp: r.ts, HeaderNonce: r.nonce, HeaderSignature: r.signature} {
+				if v != "" {
+					req.Header.Set(k, v)
+				}
+			}
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantCode, w.Code)
+			if tc.wantErr != "" {
+				assert.Contains(t, w.Body.String(), `"code":"`+tc.wantErr+`"`)
+			} else {
+				assert.Equal(t, r.body, w.Body.String())
+			}
+		})
+	}
+}
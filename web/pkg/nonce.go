@@ -0,0 +1,12 @@
+package pkg
+
+import (
+	"context"
+	"time"
+)
+
+// NonceStore 记录已使用的签名随机串，用于防止请求重放
+type NonceStore interface {
+	// Use 记录 nonce 并保留 ttl 时长，nonce 在此期间已被使用过时返回 false
+	Use(ctx context.Context, nonce string, ttl time.Duration) (bool, error)
+}
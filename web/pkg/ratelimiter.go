@@ -2,10 +2,22 @@ package pkg
 
 import (
 	"context"
+	"time"
 )
 
 // RateLimiter 限流器接口
 type RateLimiter interface {
-	Allow(ctx context.Context, key string, n int64) (bool, error)
+	Allow(ctx context.Context, key string, n int64) (RateLimitResult, error)
 	GetStats() map[string]interface{}
 }
+
+// RateLimitResult 一次限流判断的结果
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int64 // 令牌桶容量
+	Remaining int64 // 本次请求之后剩余的令牌数
+	// ResetAfter 距下一个令牌生成的时间，桶已满时为 0
+	ResetAfter time.Duration
+	// RetryAfter 被拒绝时建议的重试等待时间
+	RetryAfter time.Duration
+}
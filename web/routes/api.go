@@ -1,15 +1,24 @@
 package routes
 
 import (
+	"fmt"
 	"github.com/afex/hystrix-go/hystrix"
 	"log"
 	"net/http"
+	"short_url/pkg/generator"
 	short_url_v1 "short_url/proto/short_url/v1"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// rpc 写入缓冲区已满时建议客户端重试的间隔，单位 秒
+const resourceExhaustedRetryAfter = 1
+
 type ApiHandler struct {
 	svc short_url_v1.ShortUrlServiceClient
 }
@@ -24,39 +33,448 @@ func (ah *ApiHandler) RegisterRoutes(srv *gin.Engine) {
 	api := srv.Group("/api")
 	{
 		api.POST("/create", ah.Create)
+
+		// 短链接管理
+		api.GET("/links/:short_url", ah.GetInfo)
+		api.PUT("/links/:short_url", ah.UpdateOriginUrl)
+		api.DELETE("/links/:short_url", ah.Delete)
+		api.POST("/links/:short_url/extend", ah.ExtendExpiration)
+
+		// 批量操作
+		api.POST("/batch/create", ah.BatchCreate)
+
+		// 点击统计，静态路由 top 先于参数路由注册，避免被当作短码
+		api.GET("/stats/top", ah.ListTopLinks)
+		api.GET("/stats/:short_url", ah.GetClickStats)
 	}
 }
 
 func (ah *ApiHandler) Create(ctx *gin.Context) {
 	type CreateRequest struct {
-		OriginUrl string `json:"origin_url"`
+		OriginUrl   string `json:"origin_url"`
+		CustomAlias string `json:"custom_alias"`
+		ExpiredAt   int64  `json:"expired_at"`   // 过期时间戳（秒），与 ttl 互斥
+		Ttl         int64  `json:"ttl"`          // 有效期（秒），与 expired_at 互斥
+		NeverExpire bool   `json:"never_expire"` // 永不过期
+		NotBefore   int64  `json:"not_before"`   // 生效时间戳（秒），此前访问展示尚未生效页面
+		// 跳转状态码，支持 301/302/307/308，不指定时使用默认值
+		RedirectCode int32 `json:"redirect_code"`
+		// 跳转时将请求中的查询参数与短码之后的路径追加到原链接
+		PassThrough bool `json:"pass_through"`
+		// UTM 参数，可与 utm_template 同时指定，非空字段覆盖模板中的同名字段
+		Utm         *UtmParams `json:"utm"`
+		UtmTemplate string     `json:"utm_template"`
+		// 访问密码，为空表示无需密码
+		Password string `json:"password"`
+		// 最大点击次数，耗尽后短链接失效，1 表示阅后即焚，0 表示不限
+		MaxClicks int64 `json:"max_clicks"`
+		// 按客户端平台与国家跳转的规则，按顺序匹配，均未命中时跳转到 origin_url
+		Rules []RoutingRuleParams `json:"rules"`
+		// A/B 分流目标，至少两个，按权重选择其一代替 origin_url，跳转规则优先于分流
+		Variants []VariantParams `json:"variants"`
 	}
 	var req CreateRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if req.CustomAlias != "" && !generator.CheckCustomAlias(req.CustomAlias) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "自定义别名不合法",
+			"code":  "INVALID_ALIAS",
+		})
+		return
+	}
+	if req.ExpiredAt < 0 || req.Ttl < 0 || (req.ExpiredAt > 0 && req.Ttl > 0) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "expired_at 与 ttl 不能为负数且不能同时指定",
+			"code":  "INVALID_EXPIRATION",
+		})
+		return
+	}
+	rules, ok := rulesToProto(req.Rules)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "路由规则不合法，platform 支持 ios/android/windows/macos/linux，country 为两位国家代码",
+			"code":  "INVALID_ROUTING_RULE",
+		})
+		return
+	}
 
-	// 使用带降级的熔断器保护RPC调用
-	err := hystrix.Do("short_url",
-		// 主要业务逻辑
-		func() error {
-			resp, err := ah.svc.GenerateShortUrl(ctx, &short_url_v1.GenerateShortUrlRequest{
-				OriginUrl: req.OriginUrl,
+	ah.callWithBreaker(ctx, func() error {
+		resp, err := ah.svc.GenerateShortUrl(ctx, &short_url_v1.GenerateShortUrlRequest{
+			OriginUrl:   req.OriginUrl,
+			CustomAlias: req.CustomAlias,
+			ExpiredAt:   req.ExpiredAt,
+			Ttl:         req.Ttl,
+			NeverExpire: req.NeverExpire,
+			NotBefore:   req.NotBefore,
+
+			RedirectCode: req.RedirectCode,
+			PassThrough:  req.PassThrough,
+			Utm:          req.Utm.toProto(),
+			UtmTemplate:  req.UtmTemplate,
+			Password:     req.Password,
+			MaxClicks:    req.MaxClicks,
+			Rules:        rules,
+			Variants:     variantsToProto(req.Variants),
+		})
+		if err != nil {
+			return err
+		}
+
+		ctx.JSON(200, gin.H{
+			"short_url":     resp.GetShortUrl(),
+			"expired_at":    resp.GetExpiredAt(),
+			"never_expire":  resp.GetNeverExpire(),
+			"not_before":    resp.GetNotBefore(),
+			"redirect_code": resp.GetRedirectCode(),
+			"pass_through":  resp.GetPassThrough(),
+			"utm":           utmToJSON(resp.GetUtm()),
+
+			"password_protected": resp.GetPasswordProtected(),
+			"max_clicks":         resp.GetMaxClicks(),
+			"rules":              rulesToJSON(resp.GetRules(), resp.GetPasswordProtected()),
+			"variants":           variantsToJSON(resp.GetVariants(), resp.GetPasswordProtected()),
+		})
+		return nil
+	})
+}
+
+// BatchCreate 批量创建短链接，单条失败不影响其他条目，结果与请求顺序一一对应
+func (ah *ApiHandler) BatchCreate(ctx *gin.Context) {
+	type CreateItem struct {
+		OriginUrl   string `json:"origin_url"`
+		CustomAlias string `json:"custom_alias"`
+		ExpiredAt   int64  `json:"expired_at"`
+		Ttl         int64  `json:"ttl"`
+		NeverExpire bool   `json:"never_expire"`
+		NotBefore   int64  `json:"not_before"`
+
+		RedirectCode int32      `json:"redirect_code"`
+		PassThrough  bool       `json:"pass_through"`
+		Utm          *UtmParams `json:"utm"`
+		UtmTemplate  string     `json:"utm_template"`
+		Password     string     `json:"password"`
+		MaxClicks    int64      `json:"max_clicks"`
+
+		Rules    []RoutingRuleParams `json:"rules"`
+		Variants []VariantParams     `json:"variants"`
+	}
+	type BatchCreateRequest struct {
+		Items []CreateItem `json:"items" binding:"required"`
+	}
+	var req BatchCreateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	items := make([]*short_url_v1.GenerateShortUrlRequest, 0, len(req.Items))
+	for i, item := range req.Items {
+		rules, ok := rulesToProto(item.Rules)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("第 %d 条的路由规则不合法，platform 支持 ios/android/windows/macos/linux，country 为两位国家代码", i+1),
+				"code":  "INVALID_ROUTING_RULE",
 			})
-			if err != nil {
-				return err
+			return
+		}
+		items = append(items, &short_url_v1.GenerateShortUrlRequest{
+			OriginUrl:   item.OriginUrl,
+			CustomAlias: item.CustomAlias,
+			ExpiredAt:   item.ExpiredAt,
+			Ttl:         item.Ttl,
+			NeverExpire: item.NeverExpire,
+			NotBefore:   item.NotBefore,
+
+			RedirectCode: item.RedirectCode,
+			PassThrough:  item.PassThrough,
+			Utm:          item.Utm.toProto(),
+			UtmTemplate:  item.UtmTemplate,
+			Password:     item.Password,
+			MaxClicks:    item.MaxClicks,
+			Rules:        rules,
+			Variants:     variantsToProto(item.Variants),
+		})
+	}
+
+	ah.callWithBreaker(ctx, func() error {
+		resp, err := ah.svc.BatchGenerateShortUrl(ctx, &short_url_v1.BatchGenerateShortUrlRequest{
+			Items: items,
+		})
+		if err != nil {
+			return err
+		}
+
+		results := make([]gin.H, 0, len(resp.GetResults()))
+		for _, r := range resp.GetResults() {
+			if codes.Code(r.GetCode()) != codes.OK {
+				_, code, ok := bizErrorCode(codes.Code(r.GetCode()))
+				if !ok {
+					code = "INTERNAL_ERROR"
+				}
+				results = append(results, gin.H{
+					"error": r.GetMessage(),
+					"code":  code,
+				})
+				continue
 			}
+			results = append(results, gin.H{
+				"short_url":    r.GetShortUrl(),
+				"expired_at":   r.GetExpiredAt(),
+				"never_expire": r.GetNeverExpire(),
+			})
+		}
+		ctx.JSON(http.StatusOK, gin.H{"results": results})
+		return nil
+	})
+}
+
+// GetInfo 查询短链接详情
+func (ah *ApiHandler) GetInfo(ctx *gin.Context) {
+	shortUrl := ctx.Param("short_url")
+	ah.callWithBreaker(ctx, func() error {
+		resp, err := ah.svc.GetShortUrlInfo(ctx, &short_url_v1.GetShortUrlInfoRequest{
+			ShortUrl: shortUrl,
+		})
+		if err != nil {
+			return err
+		}
+		ctx.JSON(http.StatusOK, infoToJSON(resp.GetInfo()))
+		return nil
+	})
+}
+
+// UpdateOriginUrl 修改短链接指向的原始链接
+func (ah *ApiHandler) UpdateOriginUrl(ctx *gin.Context) {
+	type UpdateRequest struct {
+		OriginUrl string `json:"origin_url" binding:"required"`
+	}
+	var req UpdateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	shortUrl := ctx.Param("short_url")
+	ah.callWithBreaker(ctx, func() error {
+		resp, err := ah.svc.UpdateOriginUrl(ctx, &short_url_v1.UpdateOriginUrlRequest{
+			ShortUrl:  shortUrl,
+			OriginUrl: req.OriginUrl,
+		})
+		if err != nil {
+			return err
+		}
+		ctx.JSON(http.StatusOK, infoToJSON(resp.GetInfo()))
+		return nil
+	})
+}
+
+// Delete 删除短链接
+func (ah *ApiHandler) Delete(ctx *gin.Context) {
+	shortUrl := ctx.Param("short_url")
+	ah.callWithBreaker(ctx, func() error {
+		_, err := ah.svc.DeleteShortUrl(ctx, &short_url_v1.DeleteShortUrlRequest{
+			ShortUrl: shortUrl,
+		})
+		if err != nil {
+			return err
+		}
+		ctx.Status(http.StatusNoContent)
+		return nil
+	})
+}
+
+// ExtendExpiration 延长短链接有效期
+func (ah *ApiHandler) ExtendExpiration(ctx *gin.Context) {
+	type ExtendRequest struct {
+		ExpiredAt   int64 `json:"expired_at"`   // 新的过期时间戳（秒），与 ttl 互斥
+		Ttl         int64 `json:"ttl"`          // 在当前过期时间基础上延长的秒数，与 expired_at 互斥
+		NeverExpire bool  `json:"never_expire"` // 设置为永不过期
+	}
+	var req ExtendRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	shortUrl := ctx.Param("short_url")
+	ah.callWithBreaker(ctx, func() error {
+		resp, err := ah.svc.ExtendExpiration(ctx, &short_url_v1.ExtendExpirationRequest{
+			ShortUrl:    shortUrl,
+			ExpiredAt:   req.ExpiredAt,
+			Ttl:         req.Ttl,
+			NeverExpire: req.NeverExpire,
+		})
+		if err != nil {
+			return err
+		}
+		ctx.JSON(http.StatusOK, infoToJSON(resp.GetInfo()))
+		return nil
+	})
+}
+
+// GetClickStats 查询短链接的点击总数与时间序列，
+// 支持 granularity=hour|day，start、end 为时间戳（秒），未指定时返回最近的默认区间
+func (ah *ApiHandler) GetClickStats(ctx *gin.Context) {
+	type StatsQuery struct {
+		Granularity string `form:"granularity"`
+		Start       int64  `form:"start"`
+		End         int64  `form:"end"`
+	}
+	var query StatsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var granularity short_url_v1.StatGranularity
+	switch query.Granularity {
+	case "", "hour":
+		granularity = short_url_v1.StatGranularity_STAT_GRANULARITY_HOUR
+	case "day":
+		granularity = short_url_v1.StatGranularity_STAT_GRANULARITY_DAY
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "granularity 只能为 hour 或 day",
+			"code":  "INVALID_GRANULARITY",
+		})
+		return
+	}
 
-			ctx.JSON(200, gin.H{
-				"short_url": resp.GetShortUrl(),
+	shortUrl := ctx.Param("short_url")
+	ah.callWithBreaker(ctx, func() error {
+		resp, err := ah.svc.GetClickStats(ctx, &short_url_v1.GetClickStatsRequest{
+			ShortUrl:    shortUrl,
+			Granularity: granularity,
+			Start:       query.Start,
+			End:         query.End,
+		})
+		if err != nil {
+			return err
+		}
+
+		// 独立访客数、国家分布与分流点击数只按天统计，按小时查询时不返回
+		daily := resp.GetGranularity() == short_url_v1.StatGranularity_STAT_GRANULARITY_DAY
+		points := make([]gin.H, 0, len(resp.GetPoints()))
+		for _, p := range resp.GetPoints() {
+			point := gin.H{
+				"timestamp": p.GetTimestamp(),
+				"clicks":    p.GetClicks(),
+			}
+			if daily {
+				point["unique_visitors"] = p.GetUniqueVisitors()
+			}
+			points = append(points, point)
+		}
+		result := gin.H{
+			"short_url":   resp.GetShortUrl(),
+			"granularity": "hour",
+			"total":       resp.GetTotal(),
+			"range_total": resp.GetRangeTotal(),
+			"points":      points,
+		}
+		if daily {
+			countries := make([]gin.H, 0, len(resp.GetCountries()))
+			for _, c := range resp.GetCountries() {
+				countries = append(countries, gin.H{
+					"country": c.GetCountry(),
+					"clicks":  c.GetClicks(),
+				})
+			}
+			result["granularity"] = "day"
+			result["range_unique_visitors"] = resp.GetRangeUniqueVisitors()
+			result["countries"] = countries
+			if len(resp.GetVariants()) > 0 {
+				variants := make([]gin.H, 0, len(resp.GetVariants()))
+				for _, v := range resp.GetVariants() {
+					variant := gin.H{
+						"variant": v.GetVariant(),
+						"weight":  v.GetWeight(),
+						"clicks":  v.GetClicks(),
+					}
+					// 需要密码访问的短链接 rpc 不返回跳转目标
+					if v.GetTarget() != "" {
+						variant["target"] = v.GetTarget()
+					}
+					variants = append(variants, variant)
+				}
+				result["variants"] = variants
+			}
+		}
+		ctx.JSON(http.StatusOK, result)
+		return nil
+	})
+}
+
+// ListTopLinks 查询最近窗口内的热门链接，支持 window=5m|1h|1d 与 limit
+func (ah *ApiHandler) ListTopLinks(ctx *gin.Context) {
+	type TopQuery struct {
+		Window string `form:"window"`
+		Limit  int32  `form:"limit"`
+	}
+	var query TopQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	windows := map[string]short_url_v1.TopWindow{
+		"":   short_url_v1.TopWindow_TOP_WINDOW_1H,
+		"5m": short_url_v1.TopWindow_TOP_WINDOW_5M,
+		"1h": short_url_v1.TopWindow_TOP_WINDOW_1H,
+		"1d": short_url_v1.TopWindow_TOP_WINDOW_1D,
+	}
+	window, ok := windows[query.Window]
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "window 只能为 5m、1h 或 1d",
+			"code":  "INVALID_WINDOW",
+		})
+		return
+	}
+	if query.Window == "" {
+		query.Window = "1h"
+	}
+
+	ah.callWithBreaker(ctx, func() error {
+		resp, err := ah.svc.ListTopLinks(ctx, &short_url_v1.ListTopLinksRequest{
+			Window: window,
+			Limit:  query.Limit,
+		})
+		if err != nil {
+			return err
+		}
+
+		links := make([]gin.H, 0, len(resp.GetLinks()))
+		for _, link := range resp.GetLinks() {
+			links = append(links, gin.H{
+				"short_url": link.GetShortUrl(),
+				"clicks":    link.GetClicks(),
 			})
+		}
+		ctx.JSON(http.StatusOK, gin.H{
+			"window": query.Window,
+			"links":  links,
+		})
+		return nil
+	})
+}
+
+// callWithBreaker 使用带降级的熔断器保护RPC调用，业务错误直接写入响应且不计入熔断统计
+func (ah *ApiHandler) callWithBreaker(ctx *gin.Context, run func() error) {
+	err := hystrix.Do("short_url",
+		// 主要业务逻辑
+		func() error {
+			err := run()
+			if handleBizError(ctx, err) {
+				return nil
+			}
 			return err
 		},
 		// 降级处理逻辑
 		func(err error) error {
 			// 记录降级日志
-			log.Printf("[ServerHandler] Fallback msg: %s", err.Error())
+			log.Printf("[ApiHandler] Fallback msg: %s", err.Error())
 
 			// 返回降级响应
 			ctx.JSON(503, gin.H{
@@ -80,3 +498,197 @@ func (ah *ApiHandler) Create(ctx *gin.Context) {
 		})
 	}
 }
+
+// infoToJSON 需要密码访问的短链接不返回原链接与规则、分流的跳转目标，避免绕过密码校验
+func infoToJSON(info *short_url_v1.ShortUrlInfo) gin.H {
+	originUrl := info.GetOriginUrl()
+	if info.GetPasswordProtected() {
+		originUrl = ""
+	}
+	return gin.H{
+		"short_url":     info.GetShortUrl(),
+		"origin_url":    originUrl,
+		"expired_at":    info.GetExpiredAt(),
+		"never_expire":  info.GetNeverExpire(),
+		"not_before":    info.GetNotBefore(),
+		"redirect_code": info.GetRedirectCode(),
+		"pass_through":  info.GetPassThrough(),
+		"utm":           utmToJSON(info.GetUtm()),
+		"utm_template":  info.GetUtmTemplate(),
+
+		"password_protected": info.GetPasswordProtected(),
+		"max_clicks":         info.GetMaxClicks(),
+		"rules":              rulesToJSON(info.GetRules(), info.GetPasswordProtected()),
+		"variants":           variantsToJSON(info.GetVariants(), info.GetPasswordProtected()),
+	}
+}
+
+// UtmParams 创建短链接时指定的 UTM 参数，跳转时合并到原链接的查询参数中
+type UtmParams struct {
+	Source   string `json:"source"`
+	Medium   string `json:"medium"`
+	Campaign string `json:"campaign"`
+	Term     string `json:"term"`
+	Content  string `json:"content"`
+}
+
+func (p *UtmParams) toProto() *short_url_v1.Utm {
+	if p == nil {
+		return nil
+	}
+	return &short_url_v1.Utm{
+		Source:   p.Source,
+		Medium:   p.Medium,
+		Campaign: p.Campaign,
+		Term:     p.Term,
+		Content:  p.Content,
+	}
+}
+
+func utmToJSON(utm *short_url_v1.Utm) *UtmParams {
+	if utm == nil {
+		return nil
+	}
+	return &UtmParams{
+		Source:   utm.GetSource(),
+		Medium:   utm.GetMedium(),
+		Campaign: utm.GetCampaign(),
+		Term:     utm.GetTerm(),
+		Content:  utm.GetContent(),
+	}
+}
+
+// RoutingRuleParams 按客户端平台与国家跳转的规则，platform 与 country 至少指定一个。
+// platform 取值为 ios/android/windows/macos/linux，country 为 ISO 3166-1 二位国家代码
+type RoutingRuleParams struct {
+	Platform string `json:"platform,omitempty"`
+	Country  string `json:"country,omitempty"`
+	Target   string `json:"target,omitempty"`
+}
+
+var platformNames = map[string]short_url_v1.Platform{
+	"ios":     short_url_v1.Platform_PLATFORM_IOS,
+	"android": short_url_v1.Platform_PLATFORM_ANDROID,
+	"windows": short_url_v1.Platform_PLATFORM_WINDOWS,
+	"macos":   short_url_v1.Platform_PLATFORM_MACOS,
+	"linux":   short_url_v1.Platform_PLATFORM_LINUX,
+}
+
+// rulesToProto 转换路由规则，平台名称不合法或国家代码不是两位字母时返回 false，
+// 其余校验由 rpc 层完成
+func rulesToProto(rules []RoutingRuleParams) ([]*short_url_v1.RoutingRule, bool) {
+	if len(rules) == 0 {
+		return nil, true
+	}
+	res := make([]*short_url_v1.RoutingRule, 0, len(rules))
+	for _, r := range rules {
+		var platform short_url_v1.Platform
+		if r.Platform != "" {
+			var ok bool
+			if platform, ok = platformNames[strings.ToLower(r.Platform)]; !ok {
+				return nil, false
+			}
+		}
+		country := strings.ToUpper(r.Country)
+		if country != "" && (len(country) != 2 || strings.Trim(country, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "") {
+			return nil, false
+		}
+		res = append(res, &short_url_v1.RoutingRule{Platform: platform, Country: country, Target: r.Target})
+	}
+	return res, true
+}
+
+// rulesToJSON 转换路由规则，hideTarget 为 true 时不返回跳转目标
+func rulesToJSON(rules []*short_url_v1.RoutingRule, hideTarget bool) []RoutingRuleParams {
+	if len(rules) == 0 {
+		return nil
+	}
+	res := make([]RoutingRuleParams, 0, len(rules))
+	for _, r := range rules {
+		var name string
+		for k, v := range platformNames {
+			if v == r.GetPlatform() {
+				name = k
+				break
+			}
+		}
+		rule := RoutingRuleParams{Platform: name, Country: r.GetCountry()}
+		if !hideTarget {
+			rule.Target = r.GetTarget()
+		}
+		res = append(res, rule)
+	}
+	return res
+}
+
+// VariantParams A/B 分流目标，weight 为相对权重，如 70 与 30
+type VariantParams struct {
+	Target string `json:"target,omitempty"`
+	Weight int32  `json:"weight"`
+}
+
+func variantsToProto(variants []VariantParams) []*short_url_v1.Variant {
+	if len(variants) == 0 {
+		return nil
+	}
+	res := make([]*short_url_v1.Variant, 0, len(variants))
+	for _, v := range variants {
+		res = append(res, &short_url_v1.Variant{Target: v.Target, Weight: v.Weight})
+	}
+	return res
+}
+
+// variantsToJSON 转换分流目标，hideTarget 为 true 时只返回权重
+func variantsToJSON(variants []*short_url_v1.Variant, hideTarget bool) []VariantParams {
+	if len(variants) == 0 {
+		return nil
+	}
+	res := make([]VariantParams, 0, len(variants))
+	for _, v := range variants {
+		variant := VariantParams{Weight: v.GetWeight()}
+		if !hideTarget {
+			variant.Target = v.GetTarget()
+		}
+		res = append(res, variant)
+	}
+	return res
+}
+
+// handleBizError 处理 rpc 返回的业务错误并写入响应，返回 true 表示已处理
+func handleBizError(ctx *gin.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+	httpStatus, code, ok := bizErrorCode(st.Code())
+	if !ok {
+		return false
+	}
+	if httpStatus == http.StatusServiceUnavailable {
+		ctx.Header("Retry-After", strconv.Itoa(resourceExhaustedRetryAfter))
+	}
+	ctx.JSON(httpStatus, gin.H{
+		"error": st.Message(),
+		"code":  code,
+	})
+	return true
+}
+
+// bizErrorCode 将 gRPC 业务状态码映射为 HTTP 状态码与错误码，非业务错误返回 false
+func bizErrorCode(c codes.Code) (int, string, bool) {
+	switch c {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest, "INVALID_ARGUMENT", true
+	case codes.AlreadyExists:
+		return http.StatusConflict, "ALREADY_EXISTS", true
+	case codes.NotFound:
+		return http.StatusNotFound, "NOT_FOUND", true
+	case codes.ResourceExhausted:
+		return http.StatusServiceUnavailable, "RESOURCE_EXHAUSTED", true
+	default:
+		return 0, "", false
+	}
+}
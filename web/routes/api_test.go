@@ -0,0 +1,292 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	short_url_v1 "short_url/proto/short_url/v1"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statsClient 只实现统计相关接口，其余方法调用时 panic
+type statsClient struct {
+	short_url_v1.ShortUrlServiceClient
+
+	statsReq *short_url_v1.GetClickStatsRequest
+	topReq   *short_url_v1.ListTopLinksRequest
+}
+
+func (c *statsClient) GetClickStats(_ context.Context, req *short_url_v1.GetClickStatsRequest, _ ...grpc.CallOption) (*short_url_v1.GetClickStatsResponse, error) {
+	c.statsReq = req
+	if req.GetShortUrl() == "missing" {
+		return nil, status.Error(codes.NotFound, "短链接不存在")
+	}
+	return &short_url_v1.GetClickStatsResponse{
+		ShortUrl:    req.GetShortUrl(),
+		Granularity: req.GetGranularity(),
+		Total:       42,
+		RangeTotal:  7,
+		Points:      []*short_url_v1.ClickStatPoint{{Timestamp: 1700000000, Clicks: 7}},
+	}, nil
+}
+
+func (c *statsClient) ListTopLinks(_ context.Context, req *short_url_v1.ListTopLinksRequest, _ ...grpc.CallOption) (*short_url_v1.ListTopLinksResponse, error) {
+	c.topReq = req
+	return &short_url_v1.ListTopLinksResponse{
+		Window: req.GetWindow(),
+		Links:  []*short_url_v1.TopLink{{ShortUrl: "abc123", Clicks: 9}},
+	}, nil
+}
+
+func TestApiHandler_GetClickStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name     string
+		path     string
+		wantCode int
+		wantBody map[string]any
+		wantReq  *short_url_v1.GetClickStatsRequest
+	}{
+		{
+			name:     "按小时查询",
+			path:     "/api/stats/abc123?start=1700000000&end=1700086400",
+			wantCode: http.StatusOK,
+			wantBody: map[string]any{
+				"short_url":   "abc123",
+				"granularity": "hour",
+				"total":       float64(42),
+				"range_total": float64(7),
+			},
+			wantReq: &short_url_v1.GetClickStatsRequest{
+				ShortUrl:    "abc123",
+				Granularity: short_url_v1.StatGranularity_STAT_GRANULARITY_HOUR,
+				Start:       1700000000,
+				End:         1700086400,
+			},
+		},
+		{
+			name:     "按天查询",
+			path:     "/api/stats/abc123?granularity=day",
+			wantCode: http.StatusOK,
+			wantBody: map[string]any{
+				"short_url":   "abc123",
+				"granularity": "day",
+			},
+			wantReq: &short_url_v1.GetClickStatsRequest{
+				ShortUrl:    "abc123",
+				Granularity: short_url_v1.StatGranularity_STAT_GRANULARITY_DAY,
+			},
+		},
+		{
+			name:     "粒度不合法",
+			path:     "/api/stats/abc123?granularity=week",
+			wantCode: http.StatusBadRequest,
+			wantBody: map[string]any{"code": "INVALID_GRANULARITY"},
+		},
+		{
+			name:     "短链接不存在",
+			path:     "/api/stats/missing",
+			wantCode: http.StatusNotFound,
+			wantBody: map[string]any{"code": "NOT_FOUND"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &statsClient{}
+			srv := gin.New()
+			NewApiHandler(client).RegisterRoutes(srv)
+
+			w := httptest.NewRecorder()
+			srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			assert.Equal(t, tc.wantCode, w.Code)
+			var body map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			for k, v := range tc.wantBody {
+				assert.Equal(t, v, body[k], k)
+			}
+			if tc.wantReq != nil {
+				require.NotNil(t, client.statsReq)
+				assert.Equal(t, tc.wantReq.GetShortUrl(), client.statsReq.GetShortUrl())
+				assert.Equal(t, tc.wantReq.GetGranularity(), client.statsReq.GetGranularity())
+				assert.Equal(t, tc.wantReq.GetStart(), client.statsReq.GetStart())
+				assert.Equal(t, tc.wantReq.GetEnd(), client.statsReq.GetEnd())
+			}
+		})
+	}
+}
+
+func TestApiHandler_ListTopLinks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name       string
+		path       string
+		wantCode   int
+		wantWindow string
+		wantReq    *short_url_v1.ListTopLinksRequest
+	}{
+		{
+			name:       "默认窗口",
+			path:       "/api/stats/top",
+			wantCode:   http.StatusOK,
+			wantWindow: "1h",
+			wantReq:    &short_url_v1.ListTopLinksRequest{Window: short_url_v1.TopWindow_TOP_WINDOW_1H},
+		},
+		{
+			name:       "指定窗口与数量",
+			path:       "/api/stats/top?window=5m&limit=3",
+			wantCode:   http.StatusOK,
+			wantWindow: "5m",
+			wantReq:    &short_url_v1.ListTopLinksRequest{Window: short_url_v1.TopWindow_TOP_WINDOW_5M, Limit: 3},
+		},
+		{
+			name:     "窗口不合法",
+			path:     "/api/stats/top?window=1w",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &statsClient{}
+			srv := gin.New()
+			NewApiHandler(client).RegisterRoutes(srv)
+
+			w := httptest.NewRecorder()
+			srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			assert.Equal(t, tc.wantCode, w.Code)
+			// top 不能被参数路由当作短码处理
+			assert.Nil(t, client.statsReq)
+			if tc.wantReq == nil {
+				assert.Nil(t, client.topReq)
+				return
+			}
+			require.NotNil(t, client.topReq)
+			assert.Equal(t, tc.wantReq.GetWindow(), client.topReq.GetWindow())
+			assert.Equal(t, tc.wantReq.GetLimit(), client.topReq.GetLimit())
+
+			var body struct {
+				Window string `json:"window"`
+				Links  []struct {
+					ShortUrl string `json:"short_url"`
+					Clicks   int64  `json:"clicks"`
+				} `json:"links"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tc.wantWindow, body.Window)
+			if assert.Len(t, body.Links, 1) {
+				assert.Equal(t, "abc123", body.Links[0].ShortUrl)
+				assert.Equal(t, int64(9), body.Links[0].Clicks)
+			}
+		})
+	}
+}
+
+// linkClient 按 protected 返回带有路由规则与分流目标的短链接详情，其余方法调用时 panic
+type linkClient struct {
+	short_url_v1.ShortUrlServiceClient
+
+	protected bool
+}
+
+func (c *linkClient) info(shortUrl string) *short_url_v1.ShortUrlInfo {
+	return &short_url_v1.ShortUrlInfo{
+		ShortUrl:          shortUrl,
+		OriginUrl:         "https://secret.example.com/origin",
+		NeverExpire:       true,
+		PasswordProtected: c.protected,
+		Rules: []*short_url_v1.RoutingRule{
+			{Platform: short_url_v1.Platform_PLATFORM_IOS, Target: "https://secret.example.com/ios"},
+		},
+		Variants: []*short_url_v1.Variant{
+			{Target: "https://secret.example.com/a", Weight: 70},
+			{Target: "https://secret.example.com/b", Weight: 30},
+		},
+	}
+}
+
+func (c *linkClient) GetShortUrlInfo(_ context.Context, req *short_url_v1.GetShortUrlInfoRequest, _ ...grpc.CallOption) (*short_url_v1.GetShortUrlInfoResponse, error) {
+	return &short_url_v1.GetShortUrlInfoResponse{Info: c.info(req.GetShortUrl())}, nil
+}
+
+func (c *linkClient) GenerateShortUrl(_ context.Context, req *short_url_v1.GenerateShortUrlRequest, _ ...grpc.CallOption) (*short_url_v1.GenerateShortUrlResponse, error) {
+	info := c.info("abc123")
+	return &short_url_v1.GenerateShortUrlResponse{
+		ShortUrl:          info.GetShortUrl(),
+		NeverExpire:       info.GetNeverExpire(),
+		PasswordProtected: info.GetPasswordProtected(),
+		Rules:             info.GetRules(),
+		Variants:          info.GetVariants(),
+	}, nil
+}
+
+func TestApiHandler_HideProtectedTargets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name        string
+		method      string
+		path        string
+		body        string
+		protected   bool
+		wantTargets bool
+	}{
+		{
+			name:        "查询需要密码的短链接",
+			method:      http.MethodGet,
+			path:        "/api/links/abc123",
+			protected:   true,
+			wantTargets: false,
+		},
+		{
+			name:        "创建需要密码的短链接",
+			method:      http.MethodPost,
+			path:        "/api/create",
+			body:        `{"origin_url":"https://secret.example.com/origin","password":"pwd"}`,
+			protected:   true,
+			wantTargets: false,
+		},
+		{
+			name:        "查询无需密码的短链接",
+			method:      http.MethodGet,
+			path:        "/api/links/abc123",
+			protected:   false,
+			wantTargets: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := gin.New()
+			NewApiHandler(&linkClient{protected: tc.protected}).RegisterRoutes(srv)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			srv.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var body struct {
+				Rules    []RoutingRuleParams `json:"rules"`
+				Variants []VariantParams     `json:"variants"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			// 规则与分流本身仍然返回，只隐藏跳转目标
+			require.Len(t, body.Rules, 1)
+			assert.Equal(t, "ios", body.Rules[0].Platform)
+			require.Len(t, body.Variants, 2)
+			assert.Equal(t, int32(70), body.Variants[0].Weight)
+			assert.Equal(t, tc.wantTargets, strings.Contains(w.Body.String(), "secret.example.com"))
+		})
+	}
+}
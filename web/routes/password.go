@@ -0,0 +1,147 @@
+package routes
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"math"
+	"net/http"
+	short_url_v1 "short_url/proto/short_url/v1"
+	"short_url/web/analytics"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// 密码校验通过后下发的 cookie 名前缀，完整名称为前缀加短码
+const passwordCookiePrefix = "su_pass_"
+
+// VerifyPassword 校验访问密码，成功后下发签名 cookie，有效期内再次访问无需输入密码。
+// 按短码与客户端 IP 限制尝试频率，防止暴力破解
+func (h *ServerHandler) VerifyPassword(ctx *gin.Context) {
+	shortUrl := ctx.Param("short_url")
+	if !h.checkShortUrl(shortUrl) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Short URL not found"})
+		return
+	}
+	password := ctx.PostForm("password")
+	if password == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "请输入访问密码",
+			"code":  "INVALID_PASSWORD",
+		})
+		return
+	}
+
+	key := shortUrl + ":" + analytics.HashIP(h.ipSalt, ctx.ClientIP())
+	res, err := h.passwordLimiter.Allow(ctx.Request.Context(), key, 1)
+	if err != nil {
+		// 限流器不可用时拒绝校验，避免失去暴力破解防护
+		log.Printf("[ServerHandler] password limiter failed for short URL: %s And err: %s", shortUrl, err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": "限流器内部错误",
+			"code":  "INTERNAL_ERROR",
+		})
+		return
+	}
+	if !res.Allowed {
+		if res.RetryAfter > 0 {
+			ctx.Header("Retry-After", strconv.FormatInt(int64(math.Ceil(res.RetryAfter.Seconds())), 10))
+		}
+		ctx.JSON(http.StatusTooManyRequests, gin.H{
+			"error": "尝试次数过多，请稍后再试",
+			"code":  "TOO_MANY_ATTEMPTS",
+		})
+		return
+	}
+
+	resp, err := h.svc.VerifyPassword(ctx, &short_url_v1.VerifyPasswordRequest{
+		ShortUrl: shortUrl,
+		Password: password,
+	})
+	if err != nil {
+		switch status.Code(err) {
+		case codes.NotFound:
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Short URL not found"})
+			return
+		case codes.FailedPrecondition:
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"error": "链接尚未生效",
+				"code":  "NOT_YET_AVAILABLE",
+			})
+			return
+		}
+		log.Printf("[ServerHandler] verify password failed for short URL: %s And err: %s", shortUrl, err.Error())
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "服务暂时不可用，请稍后再试",
+			"code":  "SERVICE_UNAVAILABLE",
+		})
+		return
+	}
+	if !resp.GetOk() {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "密码错误",
+			"code":  "INVALID_PASSWORD",
+		})
+		return
+	}
+
+	expiresAt := time.Now().Add(h.passwordTTL)
+	http.SetCookie(ctx.Writer, &http.Cookie{
+		Name:     passwordCookiePrefix + shortUrl,
+		Value:    signPasswordCookie(h.cookieSecret, shortUrl, expiresAt),
+		Path:     "/" + shortUrl,
+		Expires:  expiresAt,
+		MaxAge:   int(h.passwordTTL.Seconds()),
+		Secure:   ctx.Request.TLS != nil,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	ctx.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// checkPasswordCookie 判断请求是否携带该短链接未过期且签名有效的 cookie
+func (h *ServerHandler) checkPasswordCookie(ctx *gin.Context, shortUrl string) bool {
+	val, err := ctx.Cookie(passwordCookiePrefix + shortUrl)
+	if err != nil {
+		return false
+	}
+	return verifyPasswordCookie(h.cookieSecret, shortUrl, val, time.Now())
+}
+
+// renderPasswordPage 返回密码输入页，页面通过 POST 当前地址提交密码
+func (h *ServerHandler) renderPasswordPage(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+	ctx.File(h.passwordPage)
+}
+
+// signPasswordCookie 生成 "过期时间戳.签名" 格式的 cookie 值，签名覆盖短码与过期时间
+func signPasswordCookie(secret, shortUrl string, expiresAt time.Time) string {
+	exp := strconv.FormatInt(expiresAt.Unix(), 10)
+	return exp + "." + passwordCookieMAC(secret, shortUrl, exp)
+}
+
+func verifyPasswordCookie(secret, shortUrl, val string, now time.Time) bool {
+	exp, mac, ok := strings.Cut(val, ".")
+	if !ok {
+		return false
+	}
+	expiresAt, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || now.Unix() >= expiresAt {
+		return false
+	}
+	return hmac.Equal([]byte(mac), []byte(passwordCookieMAC(secret, shortUrl, exp)))
+}
+
+func passwordCookieMAC(secret, shortUrl, exp string) string {
+	m := hmac.New(sha256.New, []byte(secret))
+	m.Write([]byte(shortUrl))
+	m.Write([]byte{0})
+	m.Write([]byte(exp))
+	return hex.EncodeToString(m.Sum(nil))
+}
@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordCookie(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	val := signPasswordCookie("secret", "docs", now.Add(time.Minute))
+
+	testCases := []struct {
+		name     string
+		secret   string
+		shortUrl string
+		val      string
+		now      time.Time
+		want     bool
+	}{
+		{name: "有效", secret: "secret", shortUrl: "docs", val: val, now: now, want: true},
+		{name: "已过期", secret: "secret", shortUrl: "docs", val: val, now: now.Add(time.Minute), want: false},
+		{name: "其他短链接", secret: "secret", shortUrl: "other", val: val, now: now, want: false},
+		{name: "密钥不一致", secret: "another", shortUrl: "docs", val: val, now: now, want: false},
+		{name: "篡改过期时间", secret: "secret", shortUrl: "docs", val: "9999999999" + val[10:], now: now, want: false},
+		{name: "格式错误", secret: "secret", shortUrl: "docs", val: "garbage", now: now, want: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, verifyPasswordCookie(tc.secret, tc.shortUrl, tc.val, tc.now))
+		})
+	}
+}
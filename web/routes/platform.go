@@ -0,0 +1,27 @@
+package routes
+
+import (
+	short_url_v1 "short_url/proto/short_url/v1"
+	"strings"
+)
+
+// detectPlatform 根据 User-Agent 识别客户端平台，无法识别时返回 PLATFORM_UNSPECIFIED。
+// iPadOS 13 之后的 Safari 默认使用 Macintosh 的 UA，这里按 macOS 处理
+func detectPlatform(ua string) short_url_v1.Platform {
+	ua = strings.ToLower(ua)
+	switch {
+	// iOS 与 Android 的 UA 中分别包含 "like Mac OS X" 与 "Linux"，需先于桌面平台判断
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"), strings.Contains(ua, "ipod"):
+		return short_url_v1.Platform_PLATFORM_IOS
+	case strings.Contains(ua, "android"):
+		return short_url_v1.Platform_PLATFORM_ANDROID
+	case strings.Contains(ua, "windows"):
+		return short_url_v1.Platform_PLATFORM_WINDOWS
+	case strings.Contains(ua, "macintosh"), strings.Contains(ua, "mac os x"):
+		return short_url_v1.Platform_PLATFORM_MACOS
+	case strings.Contains(ua, "linux"), strings.Contains(ua, "x11"):
+		return short_url_v1.Platform_PLATFORM_LINUX
+	default:
+		return short_url_v1.Platform_PLATFORM_UNSPECIFIED
+	}
+}
@@ -0,0 +1,54 @@
+package routes
+
+import (
+	"net"
+	short_url_v1 "short_url/proto/short_url/v1"
+
+	"github.com/gin-gonic/gin"
+)
+
+// routeTarget 按路由规则选择跳转目标，并根据规则依赖的条件设置缓存相关的响应头，没有规则命中时返回 false
+func (h *ServerHandler) routeTarget(ctx *gin.Context, rules []*short_url_v1.RoutingRule, country string) (string, bool) {
+	var byPlatform, byCountry bool
+	for _, rule := range rules {
+		byPlatform = byPlatform || rule.GetPlatform() != short_url_v1.Platform_PLATFORM_UNSPECIFIED
+		byCountry = byCountry || rule.GetCountry() != ""
+	}
+	if byPlatform {
+		// 跳转目标依赖 User-Agent，告知缓存按 User-Agent 区分
+		ctx.Header("Vary", "User-Agent")
+	}
+	if byCountry {
+		// 跳转目标依赖客户端 IP，无法通过 Vary 表达，不允许浏览器与代理缓存
+		ctx.Header("Cache-Control", "private, no-store")
+	}
+	return selectTarget(rules, detectPlatform(ctx.Request.UserAgent()), country)
+}
+
+// clientCountry 按客户端 IP 查询所在国家，未配置 GeoIP 数据库或查询不到时返回空字符串。
+// 客户端 IP 由 gin 按可信代理设置的 X-Real-IP / X-Forwarded-For 解析
+func (h *ServerHandler) clientCountry(ctx *gin.Context) string {
+	if h.geo == nil {
+		return ""
+	}
+	ip := net.ParseIP(ctx.ClientIP())
+	if ip == nil {
+		return ""
+	}
+	return h.geo.Country(ip)
+}
+
+// selectTarget 按顺序匹配路由规则，返回第一条命中规则的跳转目标，均未命中时返回 false。
+// 规则中未指定的条件视为满足，无法识别的平台或国家不会命中指定了对应条件的规则
+func selectTarget(rules []*short_url_v1.RoutingRule, platform short_url_v1.Platform, country string) (string, bool) {
+	for _, rule := range rules {
+		if p := rule.GetPlatform(); p != short_url_v1.Platform_PLATFORM_UNSPECIFIED && p != platform {
+			continue
+		}
+		if c := rule.GetCountry(); c != "" && c != country {
+			continue
+		}
+		return rule.GetTarget(), true
+	}
+	return "", false
+}
@@ -0,0 +1,75 @@
+package routes
+
+import (
+	short_url_v1 "short_url/proto/short_url/v1"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectTarget(t *testing.T) {
+	const (
+		origin     = "https://example.com/app"
+		appStore   = "itms-apps://apps.apple.com/app/id123"
+		googlePlay = "market://details?id=com.example.app"
+
+		iphoneUA  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1"
+		androidUA = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Mobile Safari/537.36"
+		windowsUA = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36"
+		macUA     = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Safari/605.1.15"
+		linuxUA   = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36"
+	)
+	rules := []*short_url_v1.RoutingRule{
+		{Platform: short_url_v1.Platform_PLATFORM_IOS, Target: appStore},
+		{Platform: short_url_v1.Platform_PLATFORM_ANDROID, Target: googlePlay},
+	}
+
+	regional := []*short_url_v1.RoutingRule{
+		{Platform: short_url_v1.Platform_PLATFORM_IOS, Country: "CN", Target: "https://example.cn/ios"},
+		{Country: "CN", Target: "https://example.cn"},
+		{Country: "DE", Target: "https://example.de"},
+		{Platform: short_url_v1.Platform_PLATFORM_IOS, Target: appStore},
+	}
+
+	testCases := []struct {
+		name    string
+		rules   []*short_url_v1.RoutingRule
+		ua      string
+		country string
+		want    string
+	}{
+		{name: "iOS 跳转到 App Store", rules: rules, ua: iphoneUA, want: appStore},
+		{name: "Android 跳转到应用商店", rules: rules, ua: androidUA, want: googlePlay},
+		{name: "桌面平台未命中规则时跳转到原链接", rules: rules, ua: windowsUA, want: origin},
+		{name: "macOS 未命中规则", rules: rules, ua: macUA, want: origin},
+		{name: "无法识别的 User-Agent", rules: rules, ua: "curl/8.4.0", want: origin},
+		{name: "没有规则", ua: iphoneUA, want: origin},
+		{
+			name:  "Linux 规则不匹配 Android",
+			rules: []*short_url_v1.RoutingRule{{Platform: short_url_v1.Platform_PLATFORM_LINUX, Target: "https://example.com/linux"}},
+			ua:    androidUA,
+			want:  origin,
+		},
+		{
+			name:  "Linux 桌面命中规则",
+			rules: []*short_url_v1.RoutingRule{{Platform: short_url_v1.Platform_PLATFORM_LINUX, Target: "https://example.com/linux"}},
+			ua:    linuxUA,
+			want:  "https://example.com/linux",
+		},
+		{name: "平台与国家同时命中", rules: regional, ua: iphoneUA, country: "CN", want: "https://example.cn/ios"},
+		{name: "只按国家命中", rules: regional, ua: androidUA, country: "CN", want: "https://example.cn"},
+		{name: "无法识别平台时仍按国家命中", rules: regional, ua: "curl/8.4.0", country: "DE", want: "https://example.de"},
+		{name: "国家未命中时按平台命中", rules: regional, ua: iphoneUA, country: "US", want: appStore},
+		{name: "无法解析国家", rules: regional, ua: windowsUA, want: origin},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := selectTarget(tc.rules, detectPlatform(tc.ua), tc.country)
+			if !ok {
+				got = origin
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
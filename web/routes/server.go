@@ -5,10 +5,18 @@ import (
 	"github.com/afex/hystrix-go/hystrix"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"log"
 	"net/http"
+	"net/url"
+	"path"
 	"short_url/pkg/generator"
+	"short_url/pkg/geoip"
 	short_url_v1 "short_url/proto/short_url/v1"
+	"short_url/web/analytics"
+	"short_url/web/pkg"
+	"strings"
 	"time"
 )
 
@@ -16,25 +24,69 @@ type ServerHandler struct {
 	svc          short_url_v1.ShortUrlServiceClient
 	weights      []int
 	requestGroup singleflight.Group
+	collector    analytics.Collector
+	ipSalt       string        // 客户端 IP 哈希使用的盐
+	redirectCode int           // 短链接未指定跳转状态码时使用的默认值
+	pendingPage  string        // 短链接尚未生效时展示的页面文件路径
+	geo          *geoip.Reader // 按客户端 IP 解析国家，为 nil 时不解析
+	variantTTL   time.Duration // A/B 分流 cookie 的有效期
+
+	passwordLimiter pkg.RateLimiter // 按短码与客户端 IP 限制密码尝试频率
+	cookieSecret    string          // 密码校验 cookie 的签名密钥
+	passwordTTL     time.Duration   // 密码校验 cookie 的有效期
+	passwordPage    string          // 密码输入页的文件路径
+}
+
+// ServerOptions 跳转服务配置
+type ServerOptions struct {
+	Weights             []int  // 短码校验位权重
+	IPSalt              string // 客户端 IP 哈希使用的盐
+	DefaultRedirectCode int    // 默认跳转状态码，支持 301/302/307/308
+	NotYetAvailablePage string // 短链接尚未生效时展示的页面文件路径
+	// GeoIP 离线 GeoIP 数据库，用于按国家跳转与统计点击的国家分布，为 nil 时不解析国家
+	GeoIP *geoip.Reader
+	// VariantTTL A/B 分流 cookie 的有效期，有效期内同一访客始终跳转到同一个分流目标
+	VariantTTL time.Duration
+
+	PasswordLimiter pkg.RateLimiter // 密码尝试限流器
+	CookieSecret    string          // 密码校验 cookie 的签名密钥
+	PasswordTTL     time.Duration   // 密码校验 cookie 的有效期
+	PasswordPage    string          // 密码输入页的文件路径
 }
 
 var _ Handler = (*ServerHandler)(nil)
 
-func NewServerHandler(svc short_url_v1.ShortUrlServiceClient, weights []int) *ServerHandler {
+func NewServerHandler(svc short_url_v1.ShortUrlServiceClient, collector analytics.Collector, opts ServerOptions) *ServerHandler {
 	return &ServerHandler{
 		svc:          svc,
-		weights:      weights,
+		weights:      opts.Weights,
 		requestGroup: singleflight.Group{},
+		collector:    collector,
+		ipSalt:       opts.IPSalt,
+		redirectCode: opts.DefaultRedirectCode,
+		pendingPage:  opts.NotYetAvailablePage,
+		geo:          opts.GeoIP,
+		variantTTL:   opts.VariantTTL,
+
+		passwordLimiter: opts.PasswordLimiter,
+		cookieSecret:    opts.CookieSecret,
+		passwordTTL:     opts.PasswordTTL,
+		passwordPage:    opts.PasswordPage,
 	}
 }
 
 func (sh *ServerHandler) RegisterRoutes(srv *gin.Engine) {
 	srv.GET("/:short_url", sh.Redirect)
+	// 短码之后的路径仅对开启透传的短链接生效
+	srv.GET("/:short_url/*path", sh.Redirect)
+	// 密码输入页提交到当前地址，带额外路径的地址同样需要处理
+	srv.POST("/:short_url", sh.VerifyPassword)
+	srv.POST("/:short_url/*path", sh.VerifyPassword)
 }
 
 func (h *ServerHandler) Redirect(ctx *gin.Context) {
 	shortUrl := ctx.Param("short_url")
-	if ok := generator.CheckShortUrl(shortUrl, h.weights); !ok {
+	if !h.checkShortUrl(shortUrl) {
 		ctx.JSON(404, gin.H{"error": "Short URL not found"})
 		return
 	}
@@ -43,21 +95,89 @@ func (h *ServerHandler) Redirect(ctx *gin.Context) {
 		func() error {
 			// 请求合并层 (防缓存击穿)
 			result, err, _ := h.requestGroup.Do(shortUrl, func() (interface{}, error) {
-				resp, err := h.svc.GetOriginUrl(ctx, &short_url_v1.GetOriginUrlRequest{
+				return h.svc.GetOriginUrl(ctx, &short_url_v1.GetOriginUrlRequest{
 					ShortUrl: shortUrl,
 				})
-				if err != nil {
-					return "", err
-				}
-				return resp.GetOriginUrl(), nil
 			})
 
+			if status.Code(err) == codes.FailedPrecondition {
+				// 尚未到生效时间，页面不能被缓存，否则生效后仍会展示
+				ctx.Header("Cache-Control", "no-store")
+				ctx.File(h.pendingPage)
+				return nil
+			}
 			if err != nil {
 				return err
 			}
 
-			// 重定向到原始URL
-			ctx.Redirect(301, result.(string))
+			resp := result.(*short_url_v1.GetOriginUrlResponse)
+			if resp.GetPasswordProtected() {
+				if !h.checkPasswordCookie(ctx, shortUrl) {
+					h.renderPasswordPage(ctx)
+					return nil
+				}
+				// 跳转结果依赖 cookie，不允许浏览器与代理缓存
+				ctx.Header("Cache-Control", "private, no-store")
+			}
+			// 跳转规则优先，未命中时按 A/B 分流选择目标，均未配置时跳转到原链接
+			country := h.clientCountry(ctx)
+			base, routed := "", false
+			if len(resp.GetRules()) > 0 {
+				base, routed = h.routeTarget(ctx, resp.GetRules(), country)
+			}
+			var variant int32
+			if !routed {
+				base = resp.GetOriginUrl()
+				if variants := resp.GetVariants(); len(variants) > 0 {
+					// 跳转结果依赖分流 cookie，不允许浏览器与代理缓存
+					ctx.Header("Cache-Control", "private, no-store")
+					if variant = h.chooseVariant(ctx, shortUrl, variants); variant > 0 {
+						base = variants[variant-1].GetTarget()
+					}
+				}
+			}
+			target, err := applyUtm(base, resp.GetUtm())
+			if err != nil {
+				log.Printf("[ServerHandler] apply utm failed for short URL: %s And err: %s", shortUrl, err.Error())
+				target = base
+			}
+			// 仅有末尾斜杠时视为没有额外路径
+			extraPath := strings.TrimPrefix(ctx.Request.URL.EscapedPath(), "/"+shortUrl)
+			if extraPath == "/" {
+				extraPath = ""
+			}
+			if resp.GetPassThrough() {
+				// 请求中的查询参数优先于短链接配置的 UTM 参数
+				if passed, err := passThrough(target, extraPath, ctx.Request.URL.Query()); err != nil {
+					log.Printf("[ServerHandler] pass through failed for short URL: %s And err: %s", shortUrl, err.Error())
+				} else {
+					target = passed
+				}
+			} else if extraPath != "" {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "Short URL not found"})
+				return nil
+			}
+
+			// 限制点击次数的短链接每次跳转都需单独扣减预算，不能经过请求合并
+			if resp.GetMaxClicks() > 0 {
+				consumed, err := h.svc.ConsumeClick(ctx, &short_url_v1.ConsumeClickRequest{ShortUrl: shortUrl})
+				if status.Code(err) == codes.NotFound || (err == nil && !consumed.GetOk()) {
+					ctx.JSON(http.StatusNotFound, gin.H{"error": "Short URL not found"})
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				ctx.Header("Cache-Control", "private, no-store")
+			}
+
+			// 重定向到原始URL，短链接未指定状态码时使用默认值
+			code := int(resp.GetRedirectCode())
+			if code == 0 {
+				code = h.redirectCode
+			}
+			ctx.Redirect(code, target)
+			h.collectClick(ctx, shortUrl, country, variant)
 			return nil
 		},
 		// 降级处理逻辑
@@ -75,18 +195,100 @@ func (h *ServerHandler) Redirect(ctx *gin.Context) {
 		switch err {
 		case hystrix.ErrCircuitOpen:
 			// 熔断器已打开错误
-			msg = fmt.Sprintf("Circuit open error:", err.Error())
+			msg = fmt.Sprintf("Circuit open error: %s", err.Error())
 		case hystrix.ErrMaxConcurrency:
 			// 超过最大并发数错误
-			msg = fmt.Sprintf("Max concurrency error:", err.Error())
+			msg = fmt.Sprintf("Max concurrency error: %s", err.Error())
 		default:
-			msg = fmt.Sprintf("Other error:", err.Error())
+			msg = fmt.Sprintf("Other error: %s", err.Error())
 		}
 		// 记录错误日志
-		log.Printf("[ServerHandler] RPC error [code=%s]: %v", msg)
+		log.Printf("[ServerHandler] RPC error [code=%s]: %v", msg, err)
 		ctx.JSON(http.StatusNotFound, gin.H{
 			"error":     msg,
 			"timestamp": time.Now().Unix(),
 		})
 	}
 }
+
+// passThrough 将请求中短码之后的路径与查询参数合并到原链接。
+// extraPath 为转义后的路径，按路径段追加到原链接路径之后；
+// 查询参数与原链接中同名的参数以请求为准，其余参数保留
+func passThrough(originUrl, extraPath string, query url.Values) (string, error) {
+	if extraPath == "" && len(query) == 0 {
+		return originUrl, nil
+	}
+	u, err := url.Parse(originUrl)
+	if err != nil {
+		return "", err
+	}
+	if extraPath != "" {
+		// 先单独清理额外路径，避免 .. 跳出原链接的路径
+		cleaned := path.Clean("/" + extraPath)
+		if strings.HasSuffix(extraPath, "/") && cleaned != "/" {
+			cleaned += "/"
+		}
+		u = u.JoinPath(cleaned)
+	}
+	mergeQuery(u, query)
+	return u.String(), nil
+}
+
+// applyUtm 将短链接配置的 UTM 参数合并到原链接，与原链接中同名的参数以 UTM 配置为准
+func applyUtm(originUrl string, utm *short_url_v1.Utm) (string, error) {
+	query := url.Values{}
+	for key, val := range map[string]string{
+		"utm_source":   utm.GetSource(),
+		"utm_medium":   utm.GetMedium(),
+		"utm_campaign": utm.GetCampaign(),
+		"utm_term":     utm.GetTerm(),
+		"utm_content":  utm.GetContent(),
+	} {
+		if val != "" {
+			query.Set(key, val)
+		}
+	}
+	if len(query) == 0 {
+		return originUrl, nil
+	}
+	u, err := url.Parse(originUrl)
+	if err != nil {
+		return "", err
+	}
+	mergeQuery(u, query)
+	return u.String(), nil
+}
+
+// mergeQuery 将 query 合并到 u 的查询参数中，同名参数以 query 为准
+func mergeQuery(u *url.URL, query url.Values) {
+	if len(query) == 0 {
+		return
+	}
+	q := u.Query()
+	for k, vs := range query {
+		q[k] = vs
+	}
+	u.RawQuery = q.Encode()
+}
+
+// collectClick 异步提交点击事件，不影响跳转响应
+func (h *ServerHandler) collectClick(ctx *gin.Context, shortUrl, country string, variant int32) {
+	h.collector.Collect(analytics.ClickEvent{
+		ShortUrl:  shortUrl,
+		Timestamp: time.Now(),
+		Referrer:  ctx.Request.Referer(),
+		UserAgent: ctx.Request.UserAgent(),
+		IPHash:    analytics.HashIP(h.ipSalt, ctx.ClientIP()),
+		Country:   country,
+		Variant:   variant,
+	})
+}
+
+// checkShortUrl 请求过滤：生成格式的短链接使用校验位快速拒绝，
+// 其余请求按自定义别名规则校验，交由 rpc 层的布隆过滤器继续过滤
+func (h *ServerHandler) checkShortUrl(shortUrl string) bool {
+	if generator.IsGeneratedFormat(shortUrl) {
+		return generator.CheckShortUrl(shortUrl, h.weights)
+	}
+	return generator.CheckCustomAlias(shortUrl)
+}
@@ -0,0 +1,99 @@
+package routes
+
+import (
+	"net/url"
+	short_url_v1 "short_url/proto/short_url/v1"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPassThrough(t *testing.T) {
+	testCases := []struct {
+		name      string
+		originUrl string
+		extraPath string
+		query     url.Values
+		want      string
+	}{
+		{
+			name:      "没有额外路径与参数时保持原链接",
+			originUrl: "https://example.com/a?b=1#top",
+			want:      "https://example.com/a?b=1#top",
+		},
+		{
+			name:      "追加查询参数",
+			originUrl: "https://example.com/a",
+			query:     url.Values{"utm_source": {"newsletter"}},
+			want:      "https://example.com/a?utm_source=newsletter",
+		},
+		{
+			name:      "同名参数以请求为准，其余参数保留",
+			originUrl: "https://example.com/a?utm_source=site&id=7#top",
+			query:     url.Values{"utm_source": {"newsletter"}, "q": {"a b&c"}},
+			want:      "https://example.com/a?id=7&q=a+b%26c&utm_source=newsletter#top",
+		},
+		{
+			name:      "追加路径并保留转义",
+			originUrl: "https://example.com/docs/",
+			extraPath: "/extra/a%2Fb",
+			want:      "https://example.com/docs/extra/a%2Fb",
+		},
+		{
+			name:      "原链接没有路径",
+			originUrl: "https://example.com",
+			extraPath: "/extra/path",
+			query:     url.Values{"x": {"1"}},
+			want:      "https://example.com/extra/path?x=1",
+		},
+		{
+			name:      "不允许跳出原链接路径",
+			originUrl: "https://example.com/docs",
+			extraPath: "/../../admin",
+			want:      "https://example.com/docs/admin",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := passThrough(tc.originUrl, tc.extraPath, tc.query)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestApplyUtm(t *testing.T) {
+	testCases := []struct {
+		name      string
+		originUrl string
+		utm       *short_url_v1.Utm
+		want      string
+	}{
+		{
+			name:      "未配置 UTM 时保持原链接",
+			originUrl: "https://example.com/a?b=1#top",
+			want:      "https://example.com/a?b=1#top",
+		},
+		{
+			name:      "追加非空的 UTM 参数",
+			originUrl: "https://example.com/a",
+			utm:       &short_url_v1.Utm{Source: "newsletter", Medium: "email"},
+			want:      "https://example.com/a?utm_medium=email&utm_source=newsletter",
+		},
+		{
+			name:      "同名参数以 UTM 配置为准，其余参数保留",
+			originUrl: "https://example.com/a?utm_source=site&id=7#top",
+			utm:       &short_url_v1.Utm{Source: "newsletter", Campaign: "双十一"},
+			want:      "https://example.com/a?id=7&utm_campaign=%E5%8F%8C%E5%8D%81%E4%B8%80&utm_source=newsletter#top",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := applyUtm(tc.originUrl, tc.utm)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package routes
+
+import (
+	"math/rand/v2"
+	"net/http"
+	short_url_v1 "short_url/proto/short_url/v1"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// A/B 分流 cookie 名前缀，完整名称为前缀加短码，值为从 1 开始的分流序号
+const variantCookiePrefix = "su_ab_"
+
+// chooseVariant 为访客选择分流目标，返回从 1 开始的序号，权重均无效时返回 0。
+// 请求携带合法的分流 cookie 时沿用之前的选择，否则按权重随机选择并下发 cookie
+func (h *ServerHandler) chooseVariant(ctx *gin.Context, shortUrl string, variants []*short_url_v1.Variant) int32 {
+	name := variantCookiePrefix + shortUrl
+	if val, err := ctx.Cookie(name); err == nil {
+		if n, err := strconv.Atoi(val); err == nil && n >= 1 && n <= len(variants) {
+			return int32(n)
+		}
+	}
+
+	var total int
+	for _, v := range variants {
+		total += int(max(v.GetWeight(), 0))
+	}
+	if total == 0 {
+		return 0
+	}
+	n := pickVariant(variants, rand.IntN(total))
+	http.SetCookie(ctx.Writer, &http.Cookie{
+		Name:     name,
+		Value:    strconv.Itoa(int(n)),
+		Path:     "/" + shortUrl,
+		Expires:  time.Now().Add(h.variantTTL),
+		MaxAge:   int(h.variantTTL.Seconds()),
+		Secure:   ctx.Request.TLS != nil,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return n
+}
+
+// pickVariant 返回 n 落在的权重区间对应的序号（从 1 开始），n 的取值范围为 [0, 权重之和)
+func pickVariant(variants []*short_url_v1.Variant, n int) int32 {
+	for i, v := range variants {
+		n -= int(max(v.GetWeight(), 0))
+		if n < 0 {
+			return int32(i + 1)
+		}
+	}
+	return int32(len(variants))
+}
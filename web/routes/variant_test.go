@@ -0,0 +1,83 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	short_url_v1 "short_url/proto/short_url/v1"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPickVariant(t *testing.T) {
+	variants := []*short_url_v1.Variant{
+		{Target: "https://a.example.com", Weight: 70},
+		{Target: "https://b.example.com", Weight: 30},
+	}
+	testCases := []struct {
+		name string
+		n    int
+		want int32
+	}{
+		{name: "第一个区间的起点", n: 0, want: 1},
+		{name: "第一个区间的终点", n: 69, want: 1},
+		{name: "第二个区间的起点", n: 70, want: 2},
+		{name: "第二个区间的终点", n: 99, want: 2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, pickVariant(variants, tc.n))
+		})
+	}
+}
+
+func TestChooseVariant(t *testing.T) {
+	h := &ServerHandler{variantTTL: time.Hour}
+	variants := []*short_url_v1.Variant{
+		{Target: "https://a.example.com", Weight: 1},
+		{Target: "https://b.example.com", Weight: 1},
+	}
+
+	testCases := []struct {
+		name       string
+		cookie     string
+		want       int32 // 0 表示随机选择
+		wantCookie bool
+	}{
+		{name: "沿用 cookie 中的选择", cookie: "2", want: 2},
+		{name: "序号越界时重新选择", cookie: "3", wantCookie: true},
+		{name: "格式错误时重新选择", cookie: "b", wantCookie: true},
+		{name: "首次访问", wantCookie: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/promo", nil)
+			if tc.cookie != "" {
+				ctx.Request.AddCookie(&http.Cookie{Name: variantCookiePrefix + "promo", Value: tc.cookie})
+			}
+
+			got := h.chooseVariant(ctx, "promo", variants)
+			if tc.want > 0 {
+				assert.Equal(t, tc.want, got)
+			} else {
+				assert.Contains(t, []int32{1, 2}, got)
+			}
+
+			cookies := w.Result().Cookies()
+			if !tc.wantCookie {
+				assert.Empty(t, cookies)
+				return
+			}
+			if assert.Len(t, cookies, 1) {
+				assert.Equal(t, variantCookiePrefix+"promo", cookies[0].Name)
+				assert.Equal(t, "/promo", cookies[0].Path)
+				assert.Equal(t, strconv.Itoa(int(got)), cookies[0].Value)
+			}
+		})
+	}
+}
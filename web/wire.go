@@ -6,24 +6,27 @@ import (
 	"short_url/web/ioc"
 	"short_url/web/routes"
 
-	"github.com/gin-gonic/gin"
 	"github.com/google/wire"
 )
 
-func Init() *gin.Engine {
+func Init() *App {
 	wire.Build(
 		ioc.InitLogger,
 		ioc.InitHystrix,
 		ioc.InitRedis,
 		ioc.InitRateLimiter,
+		ioc.InitPolicyRateLimiter,
 		ioc.InitEtcdClient,
 		ioc.InitShortUrlClient,
+		ioc.InitClickCollector,
 		ioc.InitServerHandler,
+		ioc.InitSignatureMiddleware,
 		ioc.InitGinMiddleware,
 		ioc.InitWebServer,
 		routes.NewApiHandler,
 		routes.NewHealthHandler,
+		ioc.InitLifecycle,
 		wire.Struct(new(App), "*"),
 	)
-	return nil, nil
+	return new(App)
 }
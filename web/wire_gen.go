@@ -7,25 +7,33 @@
 package main
 
 import (
-	"github.com/gin-gonic/gin"
 	"short_url/web/ioc"
 	"short_url/web/routes"
 )
 
 // Injectors from wire.go:
 
-func Init() *gin.Engine {
+func Init() *App {
 	logger := ioc.InitLogger()
 	cmdable := ioc.InitRedis()
 	rateLimiter, _ := ioc.InitRateLimiter(cmdable)
-	v := ioc.InitGinMiddleware(logger, rateLimiter)
 	client := ioc.InitEtcdClient()
 	shortUrlServiceClient := ioc.InitShortUrlClient(client)
+	signatureMiddlewareBuilder := ioc.InitSignatureMiddleware(shortUrlServiceClient, cmdable, logger)
+	policyRateLimiter := ioc.InitPolicyRateLimiter(cmdable, logger)
+	v := ioc.InitGinMiddleware(logger, rateLimiter, signatureMiddlewareBuilder, policyRateLimiter)
 	apiHandler := routes.NewApiHandler(shortUrlServiceClient)
-	serverHandler := ioc.InitServerHandler(shortUrlServiceClient)
+	collector := ioc.InitClickCollector(shortUrlServiceClient, logger)
+	serverHandler := ioc.InitServerHandler(shortUrlServiceClient, collector, cmdable)
 	string2 := ioc.InitHystrix()
 	healthHandler := routes.NewHealthHandler(string2)
 	engine := ioc.InitWebServer(v, apiHandler, serverHandler, healthHandler)
-
-	return engine
+	manager := ioc.InitLifecycle(logger)
+	app := &App{
+		Engine:     engine,
+		EtcdClient: client,
+		Collector:  collector,
+		Lifecycle:  manager,
+	}
+	return app
 }